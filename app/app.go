@@ -1,22 +1,192 @@
 package app
 
 import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
 	"github.com/go-liquor/liquor-sdk/config"
 	"github.com/go-liquor/liquor-sdk/logger"
-	"github.com/go-liquor/liquor-sdk/server/http"
 	"go.uber.org/fx"
+	"go.uber.org/zap"
 )
 
-// NewApp create a new app
-func NewApp(modules ...fx.Option) {
-	options := []fx.Option{
-		config.ConfigModule,
-		logger.LoggerModule,
-		http.HttpModule,
+// Application is a liquor application whose lifecycle can be controlled by the caller.
+type Application struct {
+	fx         *fx.App
+	config     *config.Config
+	logger     *zap.Logger
+	shutdowner fx.Shutdowner
+}
+
+// NewApp create a new app, runs it until a termination signal is received and
+// exits the process with a non-zero code if the app fails to start or stop.
+//
+// Modules can be mixed with options selecting the built-in servers:
+//
+//	app.NewApp(
+//	    app.WithoutHTTP(),
+//	    app.WithGRPC(),
+//	    grpc.RegisterGRPCService(&UsersServer{}, NewUsersServer, pb.RegisterUsersServer),
+//	)
+func NewApp(options ...fx.Option) {
+	modules := make([]any, len(options))
+	for i, o := range options {
+		modules[i] = o
+	}
+	Run(modules...)
+}
+
+// Run is NewApp accepting constructors too, provided like the modules.
+//
+// Parameters:
+//   - modules: The application modules, options (WithoutHTTP, WithGRPC, ...) and constructors
+//
+// Example:
+//
+//	app.Run(
+//	    app.WithGRPC(),
+//	    NewUsersRepository,
+//	    grpc.RegisterGRPCService(&UsersServer{}, NewUsersServer, pb.RegisterUsersServer),
+//	)
+func Run(modules ...any) {
+	os.Exit(New(modules...).Run())
+}
+
+// New creates a new application without starting it.
+//
+// Parameters:
+//   - modules: The application modules, options (WithoutHTTP, WithGRPC, ...) and constructors
+//
+// Returns:
+//   - *Application: The application
+//
+// Example:
+//
+//	application := app.New(modules...)
+//	if err := application.Start(ctx); err != nil {
+//	    t.Fatal(err)
+//	}
+//	defer application.Stop(ctx)
+func New(modules ...any) *Application {
+	a := &Application{}
+	options := buildOptions(modules)
+	options = append(options, fx.Populate(&a.config, &a.logger, &a.shutdowner))
+	a.fx = fx.New(options...)
+	return a
+}
+
+// Err returns the error raised while building the application, if any.
+//
+// Returns:
+//   - error: nil if the dependency graph is valid, error otherwise
+func (a *Application) Err() error {
+	return a.fx.Err()
+}
+
+// Start runs the OnStart hooks of every module, then warns about the config keys that
+// were never read (see logger.WarnUnusedConfigKeys). It fails if the hooks take
+// longer than `app.startTimeout` (default 15s).
+//
+// Parameters:
+//   - ctx: Context for the start
+//
+// Returns:
+//   - error: nil if successful, error otherwise
+func (a *Application) Start(ctx context.Context) error {
+	if err := a.fx.Err(); err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, a.StartTimeout())
+	defer cancel()
+	if err := a.fx.Start(ctx); err != nil {
+		return err
+	}
+	logger.WarnUnusedConfigKeys(a.logger, a.config)
+	return nil
+}
+
+// Stop runs the OnStop hooks of every module. It fails if the hooks take
+// longer than `app.stopTimeout` (default 15s).
+//
+// Parameters:
+//   - ctx: Context for the stop
+//
+// Returns:
+//   - error: nil if successful, error otherwise
+func (a *Application) Stop(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, a.StopTimeout())
+	defer cancel()
+	return a.fx.Stop(ctx)
+}
+
+// Wait blocks until the application receives a termination signal or Shutdown is called.
+//
+// Returns:
+//   - fx.ShutdownSignal: The signal and the exit code that stopped the application
+func (a *Application) Wait() fx.ShutdownSignal {
+	return <-a.fx.Wait()
+}
+
+// Shutdown asks a running application to stop; Wait returns with the given exit code.
+//
+// Parameters:
+//   - code: The exit code reported by Wait
+//
+// Returns:
+//   - error: nil if successful, error otherwise
+func (a *Application) Shutdown(code int) error {
+	if a.shutdowner == nil {
+		return fmt.Errorf("application is not built: %w", a.fx.Err())
+	}
+	return a.shutdowner.Shutdown(fx.ExitCode(code))
+}
+
+// Run starts the application, waits for a termination signal and stops it.
+//
+// Returns:
+//   - int: The process exit code (non-zero if the app failed to start or stop)
+func (a *Application) Run() int {
+	if err := a.Start(context.Background()); err != nil {
+		a.logError("failed to start application", err)
+		return 1
+	}
+	sig := a.Wait()
+	if err := a.Stop(context.Background()); err != nil {
+		a.logError("failed to stop application", err)
+		if sig.ExitCode == 0 {
+			return 1
+		}
+	}
+	return sig.ExitCode
+}
+
+// StartTimeout returns the timeout applied to Start.
+func (a *Application) StartTimeout() time.Duration {
+	return a.timeout("app.startTimeout")
+}
+
+// StopTimeout returns the timeout applied to Stop.
+func (a *Application) StopTimeout() time.Duration {
+	return a.timeout("app.stopTimeout")
+}
+
+func (a *Application) timeout(key string) time.Duration {
+	if a.config != nil {
+		if d := a.config.GetDuration(key); d > 0 {
+			return d
+		}
+	}
+	return fx.DefaultTimeout
+}
+
+func (a *Application) logError(msg string, err error) {
+	if a.logger != nil {
+		a.logger.Error(msg, zap.Error(err))
+		return
 	}
-	options = append(options, modules...)
-	app := fx.New(options...)
-	app.Run()
+	fmt.Fprintf(os.Stderr, "%s: %v\n", msg, err)
 }
 
 func NewModule(moduleName string, in ...any) fx.Option {
@@ -0,0 +1,346 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-liquor/liquor-sdk/config"
+	"github.com/go-liquor/liquor-sdk/seed"
+	"github.com/spf13/cobra"
+	"go.uber.org/fx"
+	"gopkg.in/yaml.v3"
+)
+
+// Migrator runs versioned migrations for the `migrate` command.
+type Migrator interface {
+	Up(ctx context.Context) error
+	Down(ctx context.Context, steps int) error
+	Status(ctx context.Context, w io.Writer) error
+}
+
+// RunCLI runs the application as a command line tool and exits the process with the
+// command exit code. The binary exposes the `serve` (default), `migrate up/down/status`,
+// `seed`, `worker <name>`, `routes` and `config print/env` commands.
+//
+// Example:
+//
+//	func main() {
+//	    app.RunCLI(
+//	        postgres.DatabasePostgresModule,
+//	        app.RegisterWorkers(NewEmailWorker),
+//	    )
+//	}
+func RunCLI(modules ...any) {
+	os.Exit(Execute(NewCommand(modules...)))
+}
+
+// Execute runs a command built by NewCommand.
+//
+// Parameters:
+//   - cmd: The root command
+//
+// Returns:
+//   - int: The process exit code
+func Execute(cmd *cobra.Command) int {
+	if err := cmd.Execute(); err != nil {
+		var exit exitError
+		if errors.As(err, &exit) {
+			return int(exit)
+		}
+		fmt.Fprintln(cmd.ErrOrStderr(), "Error:", err)
+		return 1
+	}
+	return 0
+}
+
+// exitError carries a process exit code through cobra.
+type exitError int
+
+func (e exitError) Error() string {
+	return fmt.Sprintf("exit code %d", int(e))
+}
+
+// NewCommand builds the root command of a liquor application. Every subcommand boots
+// only the modules it needs; `--config path` merges config files and `--set key=value`
+// overrides configuration keys.
+//
+// Parameters:
+//   - modules: The application modules, options and constructors (as in Run)
+//
+// Returns:
+//   - *cobra.Command: The root command (extra commands can be added to it)
+func NewCommand(modules ...any) *cobra.Command {
+	var overrides, files []string
+	with := func(opts ...any) []any {
+		values := map[string]string{}
+		for _, o := range overrides {
+			k, v, _ := strings.Cut(o, "=")
+			values[k] = v
+		}
+		return append(append(opts, WithConfigOverrides(values), WithConfigFiles(files...)), modules...)
+	}
+
+	serve := &cobra.Command{
+		Use:   "serve",
+		Short: "Run the servers and workers",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return exitCode(New(with()...).Run())
+		},
+	}
+
+	root := &cobra.Command{
+		Use:           filepath.Base(os.Args[0]),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE:          serve.RunE,
+	}
+	root.PersistentFlags().StringArrayVar(&overrides, "set", nil, "override a config key (key=value)")
+	root.PersistentFlags().StringArrayVar(&files, "config", nil, "merge a config file over config.yaml (repeatable)")
+	root.AddCommand(
+		serve,
+		newMigrateCommand(with),
+		&cobra.Command{
+			Use:   "worker <name>",
+			Short: "Run a single registered worker",
+			Args:  cobra.ExactArgs(1),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return exitCode(New(with(withMode(ModeWorker), WithWorker(args[0]))...).Run())
+			},
+		},
+		&cobra.Command{
+			Use:   "routes",
+			Short: "Print the registered HTTP routes",
+			RunE: func(cmd *cobra.Command, args []string) error {
+				var server *gin.Engine
+				a := New(append(with(withMode(ModeRoutes), WithoutOps()), fx.NopLogger, fx.Populate(&server))...)
+				if err := a.Err(); err != nil {
+					return err
+				}
+				printRoutes(cmd.OutOrStdout(), server.Routes())
+				return nil
+			},
+		},
+		newSeedCommand(with),
+		newConfigCommand(with),
+	)
+	root.SetErr(os.Stderr)
+	return root
+}
+
+func newMigrateCommand(with func(opts ...any) []any) *cobra.Command {
+	// migrate boots the graph without starting it: the versioned migrations, then the
+	// registered migration functions, run while the graph is built, only in ModeMigrate
+	// (a real `migrate up`).
+	var dryRun bool
+	build := func(mode Mode, migrator *Migrator) error {
+		populate := fx.Invoke(func(p struct {
+			fx.In
+			Migrator Migrator `optional:"true"`
+		}) {
+			*migrator = p.Migrator
+		})
+		var opts []any
+		if dryRun {
+			mode = ModeMigrateDryRun
+			opts = append(opts, WithConfigOverrides(map[string]string{"database.migrations.dryRun": "true"}))
+		}
+		opts = append(opts, withMode(mode), WithoutOps())
+		return New(append(with(opts...), populate)...).Err()
+	}
+
+	var steps int
+	down := &cobra.Command{
+		Use:   "down",
+		Short: "Roll back the last migrations",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var migrator Migrator
+			if err := build(ModeMigrateDown, &migrator); err != nil {
+				return err
+			}
+			if migrator == nil {
+				return errors.New("no versioned migrations registered: down is not supported")
+			}
+			return migrator.Down(cmd.Context(), steps)
+		},
+	}
+	down.Flags().IntVar(&steps, "steps", 1, "number of migrations to roll back")
+
+	migrate := &cobra.Command{
+		Use:   "migrate",
+		Short: "Run database migrations",
+	}
+	migrate.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "print the versioned migrations instead of running them")
+	migrate.AddCommand(
+		&cobra.Command{
+			Use:   "up",
+			Short: "Apply the pending migrations",
+			RunE: func(cmd *cobra.Command, args []string) error {
+				// the pending migrations are applied while the graph is built (see autoMigrate),
+				// before the migration functions; a dry run only prints them
+				var migrator Migrator
+				if err := build(ModeMigrate, &migrator); err != nil {
+					return err
+				}
+				if migrator == nil || !dryRun {
+					return nil
+				}
+				return migrator.Up(cmd.Context())
+			},
+		},
+		down,
+		&cobra.Command{
+			Use:   "status",
+			Short: "Print the migrations status",
+			RunE: func(cmd *cobra.Command, args []string) error {
+				var migrator Migrator
+				if err := build(ModeMigrateStatus, &migrator); err != nil {
+					return err
+				}
+				if migrator == nil {
+					return errors.New("no versioned migrations registered: status is not supported")
+				}
+				return migrator.Status(cmd.Context(), cmd.OutOrStdout())
+			},
+		},
+	)
+	return migrate
+}
+
+func newSeedCommand(with func(opts ...any) []any) *cobra.Command {
+	var reload, force bool
+	cmd := &cobra.Command{
+		Use:   "seed",
+		Short: "Populate the database with the registered seeders of `app.env`",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var runner *seed.Runner
+			var cfg *config.Config
+			a := New(append(with(withMode(ModeSeed), WithoutOps()), fx.Populate(&runner, &cfg))...)
+			if err := a.Err(); err != nil {
+				return err
+			}
+			if runner == nil {
+				return errors.New("no seeders registered")
+			}
+			if reload {
+				if env := cfg.GetAppEnv(); isProduction(env) && !force {
+					return fmt.Errorf("seed --reload empties the seeded tables of app.env %q: add --force to run it", env)
+				}
+				return runner.Reload(cmd.Context())
+			}
+			return runner.Run(cmd.Context())
+		},
+	}
+	cmd.Flags().BoolVar(&reload, "reload", false, "empty the seeded tables before running the seeders")
+	cmd.Flags().BoolVar(&force, "force", false, "allow --reload in production")
+	return cmd
+}
+
+// isProduction reports whether app.env is a production environment.
+func isProduction(env string) bool {
+	switch strings.ToLower(env) {
+	case "prod", "production":
+		return true
+	}
+	return false
+}
+
+func newConfigCommand(with func(opts ...any) []any) *cobra.Command {
+	// load reads the configuration as the app does (with its files, env prefix, values and
+	// overrides), but the resolvers of the modules aren't loaded: their references are
+	// printed as is
+	load := func() (*config.Config, error) {
+		s, _ := parseSettings(with())
+		opts := s.loadOptions()
+		opts.KeepUnresolved = true
+		cfg, err := config.Load(opts)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range s.overrides {
+			cfg.Set(k, v)
+		}
+		return cfg, nil
+	}
+
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Inspect the configuration",
+	}
+	var sources bool
+	printCmd := &cobra.Command{
+		Use:   "print",
+		Short: "Print the resolved configuration (the secrets are redacted)",
+		RunE: func(c *cobra.Command, args []string) error {
+			cfg, err := load()
+			if err != nil {
+				return err
+			}
+			if sources {
+				w := tabwriter.NewWriter(c.OutOrStdout(), 0, 0, 2, ' ', 0)
+				fmt.Fprintln(w, "KEY\tSOURCE\tVALUE")
+				for _, info := range cfg.Inspect() {
+					fmt.Fprintf(w, "%s\t%s\t%v\n", info.Key, info.Source, info.Value)
+				}
+				return w.Flush()
+			}
+			enc := yaml.NewEncoder(c.OutOrStdout())
+			enc.SetIndent(2)
+			return enc.Encode(cfg.RedactedSettings())
+		},
+	}
+	printCmd.Flags().BoolVar(&sources, "sources", false, "list the keys with the source of their value")
+	cmd.AddCommand(printCmd)
+	cmd.AddCommand(&cobra.Command{
+		Use:   "env",
+		Short: "List the keys overridden by environment variables",
+		RunE: func(c *cobra.Command, args []string) error {
+			cfg, err := load()
+			if err != nil {
+				return err
+			}
+			overrides := cfg.EnvOverrides()
+			keys := make([]string, 0, len(overrides))
+			for k := range overrides {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			w := tabwriter.NewWriter(c.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "KEY\tVARIABLE")
+			for _, k := range keys {
+				fmt.Fprintf(w, "%s\t%s\n", k, overrides[k])
+			}
+			return w.Flush()
+		},
+	})
+	return cmd
+}
+
+func printRoutes(w io.Writer, routes gin.RoutesInfo) {
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Path == routes[j].Path {
+			return routes[i].Method < routes[j].Method
+		}
+		return routes[i].Path < routes[j].Path
+	})
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "METHOD\tPATH\tHANDLER")
+	for _, r := range routes {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", r.Method, r.Path, r.Handler)
+	}
+	tw.Flush()
+}
+
+func exitCode(code int) error {
+	if code != 0 {
+		return exitError(code)
+	}
+	return nil
+}
@@ -0,0 +1,294 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime/debug"
+	"sync"
+
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+const subscribersGroup = `group:"liquor-event-subscribers"`
+
+// Subscriber reacts to the events of one type (see Subscribe and SubscribeAsync).
+type Subscriber struct {
+	name      string
+	eventType reflect.Type
+	async     bool
+	outbox    *outboxTarget
+	handle    func(ctx context.Context, event any) error
+}
+
+type outboxTarget struct {
+	topic string
+}
+
+// EventOutbox stores events in the transaction of the publisher, to deliver them
+// reliably to an external broker (see the outbox package and ToOutbox).
+type EventOutbox interface {
+	// Store saves an event of a topic. The key orders the events (events of a key are
+	// delivered in order); it may be empty.
+	Store(ctx context.Context, topic, key string, payload []byte) error
+}
+
+// EventKeyer is implemented by the events with a delivery key (see ToOutbox).
+type EventKeyer interface {
+	EventKey() string
+}
+
+// Subscribe creates a synchronous subscriber: it runs during Publish, which returns its error.
+//
+// Parameters:
+//   - name: The subscriber name (used in the logs and errors)
+//   - fn: The function called with every published E
+//
+// Returns:
+//   - Subscriber: The subscriber, to register with RegisterSubscribers or EventBus.Subscribe
+//
+// Example:
+//
+//	app.Subscribe("welcome-email", func(ctx context.Context, e UserCreated) error {
+//	    return mailer.SendWelcome(ctx, e.Email)
+//	})
+func Subscribe[E any](name string, fn func(ctx context.Context, event E) error) Subscriber {
+	return Subscriber{
+		name:      name,
+		eventType: reflect.TypeFor[E](),
+		handle: func(ctx context.Context, event any) error {
+			return fn(ctx, event.(E))
+		},
+	}
+}
+
+// SubscribeAsync creates an asynchronous subscriber: it runs in its own goroutine after
+// Publish returns, and its errors are logged. The stop of the app waits for the running
+// asynchronous subscribers.
+//
+// Parameters:
+//   - name: The subscriber name (used in the logs)
+//   - fn: The function called with every published E
+//
+// Returns:
+//   - Subscriber: The subscriber, to register with RegisterSubscribers or EventBus.Subscribe
+func SubscribeAsync[E any](name string, fn func(ctx context.Context, event E) error) Subscriber {
+	s := Subscribe(name, fn)
+	s.async = true
+	return s
+}
+
+// ToOutbox creates a subscriber storing every published E in the EventOutbox of the app,
+// encoded in JSON. It runs synchronously, so the event is stored in the transaction of the
+// publisher when the outbox supports it. The key of the events implementing EventKeyer
+// orders their delivery.
+//
+// Parameters:
+//   - topic: The topic of the events (e.g. the SQS queue or the Redis stream)
+//
+// Returns:
+//   - Subscriber: The subscriber, to register with RegisterSubscribers
+func ToOutbox[E any](topic string) Subscriber {
+	return Subscriber{
+		name:      "outbox:" + topic,
+		eventType: reflect.TypeFor[E](),
+		outbox:    &outboxTarget{topic: topic},
+	}
+}
+
+// EventBus delivers the published events to the subscribers of their type, so modules
+// react to each other's events without depending on each other.
+type EventBus struct {
+	mu          sync.RWMutex
+	subscribers map[reflect.Type][]Subscriber
+	outbox      EventOutbox
+	logger      *zap.Logger
+	running     sync.WaitGroup
+}
+
+// NewEventBus creates an event bus. The app provides one (see RegisterSubscribers).
+//
+// Parameters:
+//   - logger: The logger of the asynchronous subscribers (may be nil)
+//   - outbox: The outbox of the ToOutbox subscribers (may be nil)
+//
+// Returns:
+//   - *EventBus: The event bus
+func NewEventBus(logger *zap.Logger, outbox EventOutbox) *EventBus {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &EventBus{subscribers: map[reflect.Type][]Subscriber{}, outbox: outbox, logger: logger}
+}
+
+// Subscribe adds a subscriber.
+//
+// Parameters:
+//   - s: The subscriber (see Subscribe, SubscribeAsync and ToOutbox)
+//
+// Returns:
+//   - error: An error if s is a ToOutbox subscriber and the bus has no outbox
+func (b *EventBus) Subscribe(s Subscriber) error {
+	if s.eventType == nil {
+		return errors.New("events: the subscriber is empty, create it with app.Subscribe")
+	}
+	if s.outbox != nil {
+		if b.outbox == nil {
+			return fmt.Errorf("events: %s needs an app.EventOutbox (see the outbox package)", s.name)
+		}
+		topic := s.outbox.topic
+		s.handle = func(ctx context.Context, event any) error {
+			payload, err := json.Marshal(event)
+			if err != nil {
+				return err
+			}
+			var key string
+			if keyer, ok := event.(EventKeyer); ok {
+				key = keyer.EventKey()
+			}
+			return b.outbox.Store(ctx, topic, key, payload)
+		}
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subscribers[s.eventType] = append(b.subscribers[s.eventType], s)
+	return nil
+}
+
+// Publish delivers an event to the subscribers of its type (exactly its type: a *E is not
+// delivered to the subscribers of E). The synchronous subscribers run first, in no
+// particular order; a failing subscriber does not stop the others, and Publish returns
+// their joined errors. The asynchronous subscribers then run with a context that is not
+// canceled with ctx.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - event: The event
+//
+// Returns:
+//   - error: The errors of the synchronous subscribers
+//
+// Example:
+//
+//	err := bus.Publish(ctx, UserCreated{ID: user.ID, Email: user.Email})
+func (b *EventBus) Publish(ctx context.Context, event any) error {
+	b.mu.RLock()
+	subscribers := b.subscribers[reflect.TypeOf(event)]
+	b.mu.RUnlock()
+
+	var errs []error
+	for _, s := range subscribers {
+		if s.async {
+			continue
+		}
+		if err := s.run(ctx, event); err != nil {
+			errs = append(errs, fmt.Errorf("events: %s failed on %T: %w", s.name, event, err))
+		}
+	}
+
+	detached := context.WithoutCancel(ctx)
+	for _, s := range subscribers {
+		if !s.async {
+			continue
+		}
+		b.running.Add(1)
+		go func(s Subscriber) {
+			defer b.running.Done()
+			if err := s.run(detached, event); err != nil {
+				b.logger.Error("event subscriber failed", zap.String("subscriber", s.name),
+					zap.String("event", fmt.Sprintf("%T", event)), zap.Error(err))
+			}
+		}(s)
+	}
+	return errors.Join(errs...)
+}
+
+// Wait blocks until the running asynchronous subscribers return or ctx is done.
+func (b *EventBus) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		b.running.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s Subscriber) run(ctx context.Context, event any) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v\n%s", r, debug.Stack())
+		}
+	}()
+	return s.handle(ctx, event)
+}
+
+var (
+	subscriberType  = reflect.TypeOf(Subscriber{})
+	subscribersType = reflect.TypeOf([]Subscriber{})
+)
+
+// RegisterSubscribers register your event subscribers. It accepts Subscriber and
+// []Subscriber values and constructors returning a Subscriber or a []Subscriber, so
+// subscribers can use the services of the app. The events are published with the
+// *app.EventBus provided to the app.
+//
+// Example:
+//
+//	app.NewApp(
+//	    app.RegisterSubscribers(
+//	        func(mailer *services.Mailer) app.Subscriber {
+//	            return app.SubscribeAsync("welcome-email", mailer.OnUserCreated)
+//	        },
+//	        app.ToOutbox[UserCreated]("users"),
+//	    ),
+//	)
+func RegisterSubscribers(subscribers ...any) fx.Option {
+	provides := make([]any, 0, len(subscribers))
+	for _, s := range subscribers {
+		switch v := s.(type) {
+		case Subscriber:
+			provides = append(provides, fx.Annotate(func() Subscriber { return v }, fx.ResultTags(subscribersGroup)))
+		case []Subscriber:
+			provides = append(provides, fx.Annotate(func() []Subscriber { return v }, fx.ResultTags(`group:"liquor-event-subscribers,flatten"`)))
+		default:
+			tag := subscribersGroup
+			if t := reflect.TypeOf(s); t != nil && t.Kind() == reflect.Func && t.NumOut() > 0 && t.Out(0) == subscribersType {
+				tag = `group:"liquor-event-subscribers,flatten"`
+			} else if t == nil || t.Kind() != reflect.Func || t.NumOut() == 0 || t.Out(0) != subscriberType {
+				return fx.Error(errors.New("RegisterSubscribers: expected a Subscriber, []Subscriber or a constructor returning them"))
+			}
+			provides = append(provides, fx.Annotate(s, fx.ResultTags(tag)))
+		}
+	}
+	return fx.Module("liquor-app-event-subscribers", fx.Provide(provides...))
+}
+
+type eventBusParams struct {
+	fx.In
+
+	Logger      *zap.Logger
+	Lc          fx.Lifecycle
+	Outbox      EventOutbox  `optional:"true"`
+	Subscribers []Subscriber `group:"liquor-event-subscribers"`
+}
+
+// newEventBus provides the event bus with the registered subscribers. The stop of the
+// app waits for its asynchronous subscribers.
+func newEventBus(p eventBusParams) (*EventBus, error) {
+	bus := NewEventBus(p.Logger, p.Outbox)
+	for _, s := range p.Subscribers {
+		if err := bus.Subscribe(s); err != nil {
+			return nil, err
+		}
+	}
+	p.Lc.Append(fx.StopHook(bus.Wait))
+	return bus, nil
+}
@@ -1,10 +1,198 @@
 package app
 
-import "go.uber.org/fx"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"reflect"
 
-// RegisterMigrations register the functions with migrations function
+	"github.com/go-liquor/liquor-sdk/config"
+	"github.com/go-liquor/liquor-sdk/migrate"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+const migrationsGroup = `group:"liquor-migrations,flatten"`
+
+// RegisterMigrations register your migrations. It accepts:
+//   - migrate.Migration and []migrate.Migration values (see migrate.Go and migrate.SQL)
+//   - fs.FS values holding `<version>_<name>.up.sql`/`.down.sql` files (e.g. an embed.FS)
+//   - functions, invoked with their dependencies
+//
+// Versioned migrations need the MigrationsModule of your database module. They are applied
+// in order when the app is served (unless `database.migrations.autoRun` is false) or with the
+// `migrate up` command, tracked in `database.migrations.table` and guarded by a database lock
+// so only one replica migrates. Functions run when the app is served or with `migrate up`,
+// and are skipped by the other commands (`migrate down`, `migrate status`, `--dry-run`,
+// `routes`, `worker`, ...).
+//
+// The versioned migrations are applied first, then the functions run, in the order they
+// are registered, and both happen before the invokes of the modules of the app (and before
+// their start hooks), so the modules always find the schema migrated.
+//
+// Example:
+//
+//	//go:embed migrations/*.sql
+//	var migrationFiles embed.FS
+//
+//	migrations, _ := fs.Sub(migrationFiles, "migrations")
+//	app.NewApp(
+//	    postgres.DatabasePostgresModule,
+//	    postgres.MigrationsModule,
+//	    app.RegisterMigrations(
+//	        migrations,
+//	        migrate.Go(3, "backfill_users", backfillUsers, nil),
+//	    ),
+//	)
 func RegisterMigrations(migrations ...any) fx.Option {
-	return fx.Module("liquor-app-migrations", fx.Invoke(
-		migrations...,
-	))
+	var versioned []migrate.Migration
+	var options []fx.Option
+	for _, m := range migrations {
+		switch v := m.(type) {
+		case migrate.Migration:
+			versioned = append(versioned, v)
+		case []migrate.Migration:
+			versioned = append(versioned, v...)
+		case fs.FS:
+			list, err := migrate.FromFS(v, ".")
+			if err != nil {
+				return fx.Error(err)
+			}
+			versioned = append(versioned, list...)
+		default:
+			options = append(options, invokeInModes(m, ModeServe, ModeMigrate))
+		}
+	}
+
+	if len(versioned) > 0 {
+		options = append(options, fx.Provide(fx.Annotate(
+			func() []migrate.Migration { return versioned },
+			fx.ResultTags(migrationsGroup),
+		)))
+	}
+	return fx.Module("liquor-app-migrations", options...)
+}
+
+type migratorParams struct {
+	fx.In
+
+	Config     *config.Config
+	Logger     *zap.Logger
+	Store      migrate.Store       `optional:"true"`
+	Migrations []migrate.Migration `group:"liquor-migrations"`
+}
+
+// newMigrator provides the Migrator of the versioned migrations (nil when none is registered).
+func newMigrator(p migratorParams) (Migrator, error) {
+	if len(p.Migrations) == 0 {
+		return nil, nil
+	}
+	if p.Store == nil {
+		return nil, errors.New("versioned migrations need a migrate.Store: add the MigrationsModule of your database module")
+	}
+	migrator, err := migrate.NewMigrator(p.Store, p.Migrations, p.Logger, migrate.Options{
+		DryRun: p.Config.GetBool("database.migrations.dryRun"),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return migrator, nil
+}
+
+type autoMigrateParams struct {
+	fx.In
+
+	Config   *config.Config
+	Mode     Mode     `optional:"true"`
+	Migrator Migrator `optional:"true"`
+}
+
+// autoMigrate applies the versioned migrations while the served app is built, within
+// `database.migrations.timeout` (default `app.startTimeout`), and with `migrate up`, within
+// `database.migrations.timeout` if set. It is the first invoke of the app (see
+// buildOptions), so the migration functions and the modules find the schema migrated.
+func autoMigrate(p autoMigrateParams) error {
+	if p.Migrator == nil {
+		return nil
+	}
+	ctx := context.Background()
+	timeout := p.Config.GetDuration("database.migrations.timeout")
+	switch p.Mode {
+	case ModeServe, "":
+		if p.Config.IsSet("database.migrations.autoRun") && !p.Config.GetBool("database.migrations.autoRun") {
+			return nil
+		}
+		if timeout <= 0 {
+			timeout = p.Config.GetDuration("app.startTimeout")
+		}
+		if timeout <= 0 {
+			timeout = fx.DefaultTimeout
+		}
+	case ModeMigrate:
+	default:
+		return nil
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	return p.Migrator.Up(ctx)
+}
+
+// modeInvoked is the result of a function of invokeInModes.
+type modeInvoked struct{}
+
+// modeInvokesGroup is the group of the functions invoked in a mode (see invokeInModes).
+func modeInvokesGroup(mode Mode) string {
+	return `group:"liquor-app-invokes-` + string(mode) + `"`
+}
+
+// invokeInModes returns an option invoking fn only when the application runs in one of the
+// given modes. fn is provided in the group of each mode, consumed by buildOptions for the
+// mode of the app, so its dependencies are never built in the other modes.
+func invokeInModes(fn any, modes ...Mode) fx.Option {
+	fnType := reflect.TypeOf(fn)
+	if fnType == nil || fnType.Kind() != reflect.Func || fnType.IsVariadic() {
+		return fx.Error(fmt.Errorf("expected a function, got %T", fn))
+	}
+
+	in := make([]reflect.Type, fnType.NumIn())
+	for i := range in {
+		in[i] = fnType.In(i)
+	}
+	out := make([]reflect.Type, 0, len(modes)+1)
+	tags := make([]string, 0, len(modes))
+	for _, mode := range modes {
+		out = append(out, reflect.TypeOf(modeInvoked{}))
+		tags = append(tags, modeInvokesGroup(mode))
+	}
+	errType := reflect.TypeOf((*error)(nil)).Elem()
+	out = append(out, errType)
+
+	fnValue := reflect.ValueOf(fn)
+	wrapped := reflect.MakeFunc(reflect.FuncOf(in, out, false), func(args []reflect.Value) []reflect.Value {
+		var errs []error
+		for _, result := range fnValue.Call(args) {
+			if err, ok := result.Interface().(error); ok && err != nil {
+				errs = append(errs, err)
+			}
+		}
+		results := make([]reflect.Value, 0, len(out))
+		for range modes {
+			results = append(results, reflect.ValueOf(modeInvoked{}))
+		}
+		err := reflect.Zero(errType)
+		if joined := errors.Join(errs...); joined != nil {
+			err = reflect.ValueOf(joined)
+		}
+		return append(results, err)
+	})
+	return fx.Provide(fx.Annotate(wrapped.Interface(), fx.ResultTags(tags...)))
+}
+
+// invokeMode invokes the functions of invokeInModes registered for mode.
+func invokeMode(mode Mode) fx.Option {
+	return fx.Invoke(fx.Annotate(func([]modeInvoked) {}, fx.ParamTags(modeInvokesGroup(mode))))
 }
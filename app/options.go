@@ -0,0 +1,220 @@
+package app
+
+import (
+	"github.com/go-liquor/liquor-sdk/config"
+	"github.com/go-liquor/liquor-sdk/logger"
+	"github.com/go-liquor/liquor-sdk/server/grpc"
+	"github.com/go-liquor/liquor-sdk/server/http"
+	"go.uber.org/fx"
+)
+
+// Option selects which built-in servers run in the application. It is an fx.Option doing
+// nothing in fx itself, so it can be passed to NewApp with the modules.
+type Option struct {
+	fx.Option
+	apply func(*settings)
+}
+
+func option(apply func(*settings)) Option {
+	return Option{Option: fx.Options(), apply: apply}
+}
+
+// Mode is the command the application runs for. It is available in the dependency graph.
+type Mode string
+
+const (
+	// ModeServe runs the servers and workers (default).
+	ModeServe Mode = "serve"
+	// ModeMigrate applies the migrations (`migrate up`).
+	ModeMigrate Mode = "migrate"
+	// ModeMigrateDown rolls back the versioned migrations (`migrate down`).
+	ModeMigrateDown Mode = "migrate-down"
+	// ModeMigrateStatus prints the status of the versioned migrations (`migrate status`).
+	ModeMigrateStatus Mode = "migrate-status"
+	// ModeMigrateDryRun prints the versioned migrations without running them (`--dry-run`).
+	ModeMigrateDryRun Mode = "migrate-dry-run"
+	// ModeWorker runs a single worker.
+	ModeWorker Mode = "worker"
+	// ModeRoutes lists the registered routes.
+	ModeRoutes Mode = "routes"
+	// ModeSeed runs the seeders.
+	ModeSeed Mode = "seed"
+)
+
+type settings struct {
+	mode      Mode
+	http      bool
+	grpc      bool
+	ops       *bool
+	workers   []string
+	overrides map[string]string
+	files     []string
+	envPrefix string
+	values    map[string]interface{}
+}
+
+// WithoutHTTP disables the main HTTP server. The router is still provided, so modules
+// registering routes keep working, but it is not served.
+// The ops listener serving `/-/health` runs instead unless WithoutOps is used.
+func WithoutHTTP() Option {
+	return option(func(s *settings) {
+		s.http = false
+	})
+}
+
+// WithGRPC enables the gRPC server (see grpc.RegisterGRPCService).
+func WithGRPC() Option {
+	return option(func(s *settings) {
+		s.grpc = true
+	})
+}
+
+// WithWorker runs the application as a worker: the main HTTP server is disabled and only
+// the registered workers with the given names run (every worker when no name is given).
+func WithWorker(names ...string) Option {
+	return option(func(s *settings) {
+		s.http = false
+		s.workers = append(s.workers, names...)
+	})
+}
+
+// WithOps forces the ops listener (`server.ops.port`) to run.
+func WithOps() Option {
+	return option(func(s *settings) {
+		enabled := true
+		s.ops = &enabled
+	})
+}
+
+// WithoutOps disables the ops listener.
+func WithoutOps() Option {
+	return option(func(s *settings) {
+		enabled := false
+		s.ops = &enabled
+	})
+}
+
+// withMode sets the command the application runs for.
+func withMode(mode Mode) Option {
+	return option(func(s *settings) {
+		s.mode = mode
+	})
+}
+
+// WithConfigOverrides overrides configuration keys after the config file is read.
+func WithConfigOverrides(values map[string]string) Option {
+	return option(func(s *settings) {
+		for k, v := range values {
+			s.overrides[k] = v
+		}
+	})
+}
+
+// WithConfigFiles merges config files over config.yaml and config.<env>.yaml
+// (see config.Load for the precedence of the sources).
+func WithConfigFiles(paths ...string) Option {
+	return option(func(s *settings) {
+		s.files = append(s.files, paths...)
+	})
+}
+
+// WithConfigValues builds the configuration from values instead of the config files and
+// the environment, e.g. in the tests (see config.NewFromMap).
+func WithConfigValues(values map[string]interface{}) Option {
+	return option(func(s *settings) {
+		if s.values == nil {
+			s.values = map[string]interface{}{}
+		}
+		for k, v := range values {
+			s.values[k] = v
+		}
+	})
+}
+
+// WithEnvPrefix sets the prefix of the environment variables overriding the configuration,
+// e.g. "LIQUOR" for LIQUOR_SERVER_HTTP_PORT (see config.Load).
+func WithEnvPrefix(prefix string) Option {
+	return option(func(s *settings) {
+		s.envPrefix = prefix
+	})
+}
+
+// parseSettings splits the Run arguments into settings and fx options.
+// Values that are neither an Option nor an fx.Option are provided as constructors.
+func parseSettings(in []any) (*settings, []fx.Option) {
+	s := &settings{mode: ModeServe, http: true, overrides: map[string]string{}}
+	var modules []fx.Option
+	for _, i := range in {
+		switch v := i.(type) {
+		case Option:
+			v.apply(s)
+		case fx.Option:
+			modules = append(modules, v)
+		default:
+			modules = append(modules, fx.Provide(v))
+		}
+	}
+	return s, modules
+}
+
+// loadOptions returns the options loading the configuration of the app.
+func (s *settings) loadOptions() config.LoadOptions {
+	return config.LoadOptions{Files: s.files, EnvPrefix: s.envPrefix, Values: s.values}
+}
+
+// buildOptions builds the fx options of the Run arguments (see parseSettings).
+func buildOptions(in []any) []fx.Option {
+	s, modules := parseSettings(in)
+
+	options := []fx.Option{
+		fx.Supply(s.mode),
+		config.ConfigModule,
+		logger.LoggerModule,
+	}
+	if len(s.files) > 0 || s.envPrefix != "" || s.values != nil {
+		options = append(options, fx.Supply(s.loadOptions()))
+	}
+	if len(s.overrides) > 0 {
+		options = append(options, fx.Decorate(func(cfg *config.Config) *config.Config {
+			for k, v := range s.overrides {
+				cfg.Set(k, v)
+			}
+			return cfg
+		}))
+	}
+	// the other commands only build what they need: the routes, gRPC services and workers
+	// (and their dependencies) are provided in groups consumed below in their modes
+	serves := s.mode == ModeServe
+	if s.http && serves {
+		options = append(options, http.HttpModule)
+	} else {
+		// modules using the router directly still need it
+		options = append(options, http.HttpRouterModule)
+	}
+	if s.grpc && serves {
+		options = append(options, grpc.GrpcModule)
+	}
+	if (serves || s.mode == ModeWorker) && ((s.ops == nil && !s.http) || (s.ops != nil && *s.ops)) {
+		options = append(options, http.OpsModule)
+	}
+	options = append(options,
+		fx.Provide(newMigrator),
+		fx.Provide(newSeedRunner),
+		fx.Provide(newEventBus),
+		// the invokes of a module run before the ones of the modules after it and of the
+		// root: the schema is migrated, then the migration functions run, before the
+		// invokes of the user modules
+		fx.Module("liquor-app-migrate",
+			fx.Invoke(autoMigrate),
+			invokeMode(s.mode),
+		),
+	)
+	if serves || s.mode == ModeWorker {
+		options = append(options, fx.Invoke(runWorkers(s.workers)))
+	}
+	options = append(options, modules...)
+	if serves || s.mode == ModeRoutes {
+		options = append(options, http.RoutesModule)
+	}
+	return options
+}
@@ -0,0 +1,97 @@
+package app
+
+import (
+	"errors"
+	"io/fs"
+	"sort"
+	"sync/atomic"
+
+	"github.com/go-liquor/liquor-sdk/config"
+	"github.com/go-liquor/liquor-sdk/seed"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+const seedsGroup = `group:"liquor-seeds"`
+
+// seedsRegistered numbers the RegisterSeeds calls: fx does not keep the order of a group,
+// and seeders run in the order they are registered.
+var seedsRegistered atomic.Int64
+
+type registeredSeeds struct {
+	order   int64
+	seeders []seed.Seeder
+}
+
+// RegisterSeeds register your seeders. It accepts seed.Seeder and []seed.Seeder values
+// (see seed.Go and seed.Fixtures) and fs.FS values holding fixture files (see seed.FromFS).
+//
+// Seeders need the SeedsModule of your database module. They run with the `seed` command
+// (`seed --reload` empties the seeded tables first, and needs `--force` in production) or
+// through the *seed.Runner provided to the app, and are restricted to `app.env` with Seeder.In.
+//
+// Example:
+//
+//	//go:embed fixtures/*.yaml
+//	var fixtureFiles embed.FS
+//
+//	fixtures, _ := fs.Sub(fixtureFiles, "fixtures")
+//	app.RunCLI(
+//	    postgres.DatabasePostgresModule,
+//	    postgres.SeedsModule,
+//	    app.RegisterSeeds(
+//	        fixtures,
+//	        seed.Go("demo_orders", createDemoOrders).In("dev").Truncating("orders"),
+//	    ),
+//	)
+func RegisterSeeds(seeds ...any) fx.Option {
+	var seeders []seed.Seeder
+	for _, s := range seeds {
+		switch v := s.(type) {
+		case seed.Seeder:
+			seeders = append(seeders, v)
+		case []seed.Seeder:
+			seeders = append(seeders, v...)
+		case fs.FS:
+			list, err := seed.FromFS(v, ".")
+			if err != nil {
+				return fx.Error(err)
+			}
+			seeders = append(seeders, list...)
+		default:
+			return fx.Error(errors.New("RegisterSeeds: expected a seed.Seeder, []seed.Seeder or fs.FS"))
+		}
+	}
+	registered := registeredSeeds{order: seedsRegistered.Add(1), seeders: seeders}
+	return fx.Module("liquor-app-seeds", fx.Provide(fx.Annotate(
+		func() registeredSeeds { return registered },
+		fx.ResultTags(seedsGroup),
+	)))
+}
+
+type seedRunnerParams struct {
+	fx.In
+
+	Config *config.Config
+	Logger *zap.Logger
+	Store  seed.Store        `optional:"true"`
+	Seeds  []registeredSeeds `group:"liquor-seeds"`
+}
+
+// newSeedRunner provides the *seed.Runner of the registered seeders (nil when none is registered).
+func newSeedRunner(p seedRunnerParams) (*seed.Runner, error) {
+	sort.Slice(p.Seeds, func(i, j int) bool {
+		return p.Seeds[i].order < p.Seeds[j].order
+	})
+	var seeders []seed.Seeder
+	for _, s := range p.Seeds {
+		seeders = append(seeders, s.seeders...)
+	}
+	if len(seeders) == 0 {
+		return nil, nil
+	}
+	if p.Store == nil {
+		return nil, errors.New("seeders need a seed.Store: add the SeedsModule of your database module")
+	}
+	return seed.NewRunner(p.Store, seeders, p.Config.GetAppEnv(), p.Logger), nil
+}
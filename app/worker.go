@@ -0,0 +1,105 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/go-liquor/liquor-sdk/helpers/lqarray"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// Worker is a long-running background process started with the application and
+// canceled when it stops.
+type Worker interface {
+	// Name identifies the worker (used by WithWorker and the `worker` command).
+	Name() string
+	// Run blocks until ctx is canceled or the worker fails.
+	Run(ctx context.Context) error
+}
+
+const workersGroup = `group:"liquor-workers"`
+
+// RegisterWorkers register the constructors of your workers
+//
+// Example:
+//
+//	app.NewApp(
+//	    app.WithWorker(),
+//	    app.RegisterWorkers(NewEmailWorker),
+//	)
+func RegisterWorkers(workers ...any) fx.Option {
+	annotated := make([]any, len(workers))
+	for i, w := range workers {
+		annotated[i] = fx.Annotate(w, fx.As(new(Worker)), fx.ResultTags(workersGroup))
+	}
+	return fx.Module("liquor-app-workers", fx.Provide(annotated...))
+}
+
+type workerRunnerParams struct {
+	fx.In
+
+	Logger     *zap.Logger
+	Lc         fx.Lifecycle
+	Shutdowner fx.Shutdowner
+	Workers    []Worker `group:"liquor-workers"`
+}
+
+// runWorkers starts the registered workers (restricted to names when not empty) and
+// cancels them on stop. A worker failing shuts the application down with exit code 1.
+func runWorkers(names []string) func(p workerRunnerParams) error {
+	return func(p workerRunnerParams) error {
+		var selected []Worker
+		for _, w := range p.Workers {
+			if len(names) == 0 || lqarray.Contains(names, w.Name()) {
+				selected = append(selected, w)
+			}
+		}
+		for _, name := range names {
+			if !lqarray.ContainsBy(selected, func(w Worker) bool { return w.Name() == name }) {
+				return fmt.Errorf("worker %q is not registered", name)
+			}
+		}
+		if len(selected) == 0 {
+			return nil
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		var wg sync.WaitGroup
+		p.Lc.Append(fx.Hook{
+			OnStart: func(context.Context) error {
+				for _, w := range selected {
+					wg.Add(1)
+					go func(w Worker) {
+						defer wg.Done()
+						p.Logger.Info("starting worker", zap.String("worker", w.Name()))
+						err := w.Run(ctx)
+						if err != nil && !errors.Is(err, context.Canceled) {
+							p.Logger.Error("worker failed", zap.String("worker", w.Name()), zap.Error(err))
+							p.Shutdowner.Shutdown(fx.ExitCode(1))
+						}
+					}(w)
+				}
+				return nil
+			},
+			OnStop: func(stopCtx context.Context) error {
+				p.Logger.Info("stopping workers")
+				cancel()
+				done := make(chan struct{})
+				go func() {
+					wg.Wait()
+					close(done)
+				}()
+				select {
+				case <-done:
+					return nil
+				case <-stopCtx.Done():
+					return stopCtx.Err()
+				}
+			},
+		})
+		return nil
+	}
+}
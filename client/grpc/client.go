@@ -0,0 +1,140 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-liquor/liquor-sdk/config"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+)
+
+// ClientPool holds the gRPC connections built from the `grpc.clients` config section.
+type ClientPool struct {
+	conns map[string]*grpc.ClientConn
+}
+
+// NewClientPool creates a connection for every entry under `grpc.clients` and closes
+// them when the application stops. Connections are established lazily on the first call.
+//
+// Parameters:
+//   - cfg: Application configuration
+//   - logger: Logger instance
+//   - lc: Fx lifecycle used to close the connections on shutdown
+//
+// Returns:
+//   - *ClientPool: The pool with one connection per configured client
+//   - error: nil if successful, error if any client is misconfigured
+func NewClientPool(cfg *config.Config, logger *zap.Logger, lc fx.Lifecycle) (*ClientPool, error) {
+	pool := &ClientPool{conns: map[string]*grpc.ClientConn{}}
+	names := make([]string, 0)
+	for name := range cfg.GetStringMap("grpc.clients") {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		cc, err := ReadClientConfig(cfg, name)
+		if err != nil {
+			pool.close()
+			return nil, err
+		}
+		conn, err := Dial(cc)
+		if err != nil {
+			pool.close()
+			return nil, err
+		}
+		logger.Debug("grpc client registered", zap.String("name", name), zap.String("target", cc.Target))
+		pool.conns[strings.ToLower(name)] = conn
+	}
+
+	lc.Append(fx.Hook{
+		OnStop: func(ctx context.Context) error {
+			logger.Info("closing grpc clients")
+			return pool.close()
+		},
+	})
+	return pool, nil
+}
+
+// Dial creates a new gRPC connection from a client configuration.
+//
+// Parameters:
+//   - cc: The client configuration
+//
+// Returns:
+//   - *grpc.ClientConn: The client connection
+//   - error: nil if successful, error otherwise
+//
+// Example:
+//
+//	cc, _ := grpc.ReadClientConfig(cfg, "users")
+//	conn, err := grpc.Dial(cc)
+func Dial(cc ClientConfig) (*grpc.ClientConn, error) {
+	opts, err := cc.dialOptions()
+	if err != nil {
+		return nil, fmt.Errorf("grpc client %q: %w", cc.Name, err)
+	}
+	conn, err := grpc.NewClient(cc.Target, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("grpc client %q: failed to create connection: %w", cc.Name, err)
+	}
+	return conn, nil
+}
+
+// Conn retrieves the connection of a named client. Names are case-insensitive, like the
+// configuration keys.
+//
+// Parameters:
+//   - name: The client name (key under `grpc.clients`)
+//
+// Returns:
+//   - *grpc.ClientConn: The client connection
+//   - error: nil if successful, error if the client is not configured
+func (p *ClientPool) Conn(name string) (*grpc.ClientConn, error) {
+	conn, ok := p.conns[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("grpc client %q is not configured in grpc.clients", name)
+	}
+	return conn, nil
+}
+
+func (p *ClientPool) close() error {
+	var errs []error
+	for name, conn := range p.conns {
+		if err := conn.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("grpc client %q: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// RegisterGRPCClient provides a generated gRPC client built from a named connection.
+//
+// Parameters:
+//   - name: The client name (key under `grpc.clients`)
+//   - constructor: The generated client constructor (e.g. pb.NewUsersClient)
+//
+// Returns:
+//   - fx.Option: Fx option providing the client
+//
+// Example:
+//
+//	app.NewApp(
+//	    grpc.GRPCClientModule,
+//	    grpc.RegisterGRPCClient("users", pb.NewUsersClient),
+//	)
+func RegisterGRPCClient[T any](name string, constructor func(cc grpc.ClientConnInterface) T) fx.Option {
+	return fx.Module("liquor-grpc-client-"+name, fx.Provide(func(pool *ClientPool) (T, error) {
+		conn, err := pool.Conn(name)
+		if err != nil {
+			var zero T
+			return zero, err
+		}
+		return constructor(conn), nil
+	}))
+}
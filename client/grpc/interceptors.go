@@ -0,0 +1,60 @@
+package grpc
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/go-liquor/liquor-sdk/helpers/lqcontext"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// outgoingContext appends the request ID and trace context stored in ctx (and the auth
+// token with forwardAuth) to the outgoing metadata, without overriding values already set
+// by the caller.
+func outgoingContext(ctx context.Context, forwardAuth bool) context.Context {
+	md, _ := metadata.FromOutgoingContext(ctx)
+	headers := lqcontext.Headers(ctx)
+	if auth := lqcontext.AuthHeader(ctx); auth != "" && forwardAuth {
+		headers[lqcontext.HeaderAuthorization] = auth
+	}
+	var pairs []string
+	for k, v := range headers {
+		key := strings.ToLower(k)
+		if len(md.Get(key)) > 0 {
+			continue
+		}
+		pairs = append(pairs, key, v)
+	}
+	if len(pairs) == 0 {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, pairs...)
+}
+
+func propagationUnaryInterceptor(forwardAuth bool) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return invoker(outgoingContext(ctx, forwardAuth), method, req, reply, cc, opts...)
+	}
+}
+
+func propagationStreamInterceptor(forwardAuth bool) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(outgoingContext(ctx, forwardAuth), desc, cc, method, opts...)
+	}
+}
+
+// deadlineUnaryInterceptor applies the default deadline when the caller did not set one.
+func deadlineUnaryInterceptor(timeout time.Duration) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if timeout > 0 {
+			if _, ok := ctx.Deadline(); !ok {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, timeout)
+				defer cancel()
+			}
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
@@ -0,0 +1,8 @@
+package grpc
+
+import "go.uber.org/fx"
+
+// GRPCClientModule provides the *ClientPool built from the `grpc.clients` config section.
+var GRPCClientModule = fx.Module("liquor-grpc-client", fx.Provide(
+	NewClientPool,
+))
@@ -0,0 +1,186 @@
+package grpc
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-liquor/liquor-sdk/config"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// ClientConfig holds the settings of a named gRPC client read from `grpc.clients.<name>`.
+type ClientConfig struct {
+	Name          string
+	Target        string
+	LoadBalancing string
+	Timeout       time.Duration
+	ForwardAuth   bool
+	TLS           TLSConfig
+	Retry         RetryConfig
+}
+
+// TLSConfig holds the transport security settings of a gRPC client.
+type TLSConfig struct {
+	Enabled            bool
+	CAFile             string
+	CertFile           string
+	KeyFile            string
+	ServerName         string
+	InsecureSkipVerify bool
+}
+
+// RetryConfig holds the retry policy of a gRPC client.
+type RetryConfig struct {
+	MaxAttempts       int
+	InitialBackoff    time.Duration
+	MaxBackoff        time.Duration
+	BackoffMultiplier float64
+	Codes             []string
+}
+
+// ReadClientConfig reads the configuration of a named gRPC client.
+//
+// Parameters:
+//   - cfg: Application configuration
+//   - name: The client name (key under `grpc.clients`)
+//
+// Returns:
+//   - ClientConfig: The client settings with defaults applied
+//   - error: nil if successful, error if the target is missing
+func ReadClientConfig(cfg *config.Config, name string) (ClientConfig, error) {
+	prefix := "grpc.clients." + name + "."
+	cc := ClientConfig{
+		Name:          name,
+		Target:        cfg.GetString(prefix + "target"),
+		LoadBalancing: cfg.GetString(prefix + "loadBalancing"),
+		Timeout:       cfg.GetDuration(prefix + "timeout"),
+		ForwardAuth:   cfg.GetBool(prefix + "forwardAuth"),
+		TLS: TLSConfig{
+			Enabled:            cfg.GetBool(prefix + "tls.enabled"),
+			CAFile:             cfg.GetString(prefix + "tls.caFile"),
+			CertFile:           cfg.GetString(prefix + "tls.certFile"),
+			KeyFile:            cfg.GetString(prefix + "tls.keyFile"),
+			ServerName:         cfg.GetString(prefix + "tls.serverName"),
+			InsecureSkipVerify: cfg.GetBool(prefix + "tls.insecureSkipVerify"),
+		},
+		Retry: RetryConfig{
+			MaxAttempts:       cfg.GetInt(prefix + "retry.maxAttempts"),
+			InitialBackoff:    cfg.GetDuration(prefix + "retry.initialBackoff"),
+			MaxBackoff:        cfg.GetDuration(prefix + "retry.maxBackoff"),
+			BackoffMultiplier: cfg.GetFloat64(prefix + "retry.backoffMultiplier"),
+			Codes:             cfg.GetStringSlice(prefix + "retry.codes"),
+		},
+	}
+	if cc.Target == "" {
+		return cc, fmt.Errorf("grpc client %q: missing config key %q", name, prefix+"target")
+	}
+	if cc.Retry.InitialBackoff == 0 {
+		cc.Retry.InitialBackoff = 100 * time.Millisecond
+	}
+	if cc.Retry.MaxBackoff == 0 {
+		cc.Retry.MaxBackoff = time.Second
+	}
+	if cc.Retry.BackoffMultiplier == 0 {
+		cc.Retry.BackoffMultiplier = 2
+	}
+	if len(cc.Retry.Codes) == 0 {
+		cc.Retry.Codes = []string{"UNAVAILABLE"}
+	}
+	return cc, nil
+}
+
+// serviceConfig builds the gRPC service config JSON with the load balancing and retry policy.
+func (c ClientConfig) serviceConfig() (string, error) {
+	sc := map[string]any{}
+	if c.LoadBalancing != "" {
+		sc["loadBalancingConfig"] = []map[string]any{{c.LoadBalancing: map[string]any{}}}
+	}
+	if c.Retry.MaxAttempts > 1 {
+		codes := make([]string, len(c.Retry.Codes))
+		for i, code := range c.Retry.Codes {
+			codes[i] = strings.ToUpper(code)
+		}
+		sc["methodConfig"] = []map[string]any{{
+			"name": []map[string]any{{}},
+			"retryPolicy": map[string]any{
+				"maxAttempts":          c.Retry.MaxAttempts,
+				"initialBackoff":       formatDuration(c.Retry.InitialBackoff),
+				"maxBackoff":           formatDuration(c.Retry.MaxBackoff),
+				"backoffMultiplier":    c.Retry.BackoffMultiplier,
+				"retryableStatusCodes": codes,
+			},
+		}}
+	}
+	if len(sc) == 0 {
+		return "", nil
+	}
+	out, err := json.Marshal(sc)
+	return string(out), err
+}
+
+// transportCredentials builds the transport credentials from the TLS settings.
+func (c ClientConfig) transportCredentials() (credentials.TransportCredentials, error) {
+	if !c.TLS.Enabled {
+		return insecure.NewCredentials(), nil
+	}
+	tlsConfig := &tls.Config{
+		ServerName:         c.TLS.ServerName,
+		InsecureSkipVerify: c.TLS.InsecureSkipVerify,
+	}
+	if c.TLS.CAFile != "" {
+		ca, err := os.ReadFile(c.TLS.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read ca file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("failed to parse ca file %s", c.TLS.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if c.TLS.CertFile != "" || c.TLS.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.TLS.CertFile, c.TLS.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return credentials.NewTLS(tlsConfig), nil
+}
+
+// dialOptions builds the dial options for the client.
+func (c ClientConfig) dialOptions() ([]grpc.DialOption, error) {
+	creds, err := c.transportCredentials()
+	if err != nil {
+		return nil, err
+	}
+	opts := []grpc.DialOption{
+		grpc.WithTransportCredentials(creds),
+		grpc.WithChainUnaryInterceptor(
+			propagationUnaryInterceptor(c.ForwardAuth),
+			deadlineUnaryInterceptor(c.Timeout),
+		),
+		grpc.WithChainStreamInterceptor(
+			propagationStreamInterceptor(c.ForwardAuth),
+		),
+	}
+	sc, err := c.serviceConfig()
+	if err != nil {
+		return nil, err
+	}
+	if sc != "" {
+		opts = append(opts, grpc.WithDefaultServiceConfig(sc))
+	}
+	return opts, nil
+}
+
+func formatDuration(d time.Duration) string {
+	return fmt.Sprintf("%gs", d.Seconds())
+}
@@ -0,0 +1,91 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	nethttp "net/http"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned when a request is rejected because the circuit breaker is open.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+type breakerState int
+
+const (
+	breakerClosed breakerState = iota
+	breakerOpen
+	breakerHalfOpen
+)
+
+// breakerTransport rejects requests after consecutive failures until the open timeout elapses,
+// then lets a single probe request through to decide whether to close the circuit again.
+type breakerTransport struct {
+	name      string
+	next      nethttp.RoundTripper
+	threshold int
+	timeout   time.Duration
+
+	mu       sync.Mutex
+	state    breakerState
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+func newBreakerTransport(name string, next nethttp.RoundTripper, cfg CircuitBreakerConfig) *breakerTransport {
+	return &breakerTransport{
+		name:      name,
+		next:      next,
+		threshold: cfg.FailureThreshold,
+		timeout:   cfg.OpenTimeout,
+	}
+}
+
+func (b *breakerTransport) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case breakerOpen:
+		if time.Since(b.openedAt) < b.timeout {
+			return false
+		}
+		b.state = breakerHalfOpen
+		b.probing = true
+		return true
+	case breakerHalfOpen:
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	default:
+		return true
+	}
+}
+
+func (b *breakerTransport) record(success bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+	if success {
+		b.state = breakerClosed
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if b.state == breakerHalfOpen || b.failures >= b.threshold {
+		b.state = breakerOpen
+		b.openedAt = time.Now()
+	}
+}
+
+func (b *breakerTransport) RoundTrip(req *nethttp.Request) (*nethttp.Response, error) {
+	if !b.allow() {
+		return nil, fmt.Errorf("http client %q: %w", b.name, ErrCircuitOpen)
+	}
+	resp, err := b.next.RoundTrip(req)
+	b.record(err == nil && resp.StatusCode < nethttp.StatusInternalServerError)
+	return resp, err
+}
@@ -0,0 +1,252 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	nethttp "net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/go-liquor/liquor-sdk/config"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// Client is an HTTP client bound to a named upstream.
+type Client struct {
+	cfg    ClientConfig
+	logger *zap.Logger
+	base   *nethttp.Transport
+	client *nethttp.Client
+}
+
+// NewClient creates a client with retries, circuit breaking and instrumentation
+// for the given upstream configuration.
+//
+// Parameters:
+//   - cfg: The client configuration
+//   - logger: Logger instance
+//
+// Returns:
+//   - *Client: The HTTP client
+func NewClient(cfg ClientConfig, logger *zap.Logger) *Client {
+	c := &Client{
+		cfg:    cfg,
+		logger: logger,
+		base:   cfg.baseTransport(),
+	}
+	c.SetTransport(c.base)
+	return c
+}
+
+// SetTransport replaces the network transport below the retry, circuit breaker and
+// instrumentation layers. It is mainly used to plug a FixtureTransport in tests.
+//
+// Parameters:
+//   - rt: The transport performing the actual round trip
+func (c *Client) SetTransport(rt nethttp.RoundTripper) {
+	var transport nethttp.RoundTripper = rt
+	if c.cfg.CircuitBreaker.Enabled {
+		transport = newBreakerTransport(c.cfg.Name, transport, c.cfg.CircuitBreaker)
+	}
+	transport = &retryTransport{next: transport, cfg: c.cfg.Retry}
+	transport = &instrumentTransport{name: c.cfg.Name, next: transport, logger: c.logger, forwardAuth: c.cfg.ForwardAuth}
+	c.client = &nethttp.Client{
+		Transport: transport,
+		Timeout:   c.cfg.Timeout,
+	}
+}
+
+// HTTPClient returns the underlying *http.Client.
+//
+// Returns:
+//   - *http.Client: The standard library client with the configured transport chain
+func (c *Client) HTTPClient() *nethttp.Client {
+	return c.client
+}
+
+// NewRequest creates a request for a path relative to the client base URL.
+//
+// Parameters:
+//   - ctx: Context for the request (carries request ID and trace headers)
+//   - method: The HTTP method
+//   - path: The path relative to the base URL
+//   - body: The request body (optional)
+//
+// Returns:
+//   - *http.Request: The request
+//   - error: nil if successful, error otherwise
+func (c *Client) NewRequest(ctx context.Context, method, path string, body io.Reader) (*nethttp.Request, error) {
+	path, query, _ := strings.Cut(path, "?")
+	u, err := url.JoinPath(c.cfg.BaseURL, path)
+	if err != nil {
+		return nil, fmt.Errorf("http client %q: %w", c.cfg.Name, err)
+	}
+	if query != "" {
+		u += "?" + query
+	}
+	return nethttp.NewRequestWithContext(ctx, method, u, body)
+}
+
+// Do sends a request.
+//
+// Parameters:
+//   - req: The request to send
+//
+// Returns:
+//   - *http.Response: The response
+//   - error: nil if successful, error otherwise
+func (c *Client) Do(req *nethttp.Request) (*nethttp.Response, error) {
+	return c.client.Do(req)
+}
+
+// DoJSON sends a JSON request and decodes the JSON response into out.
+// Responses with a status code >= 400 are returned as *StatusError.
+//
+// Parameters:
+//   - ctx: Context for the request
+//   - method: The HTTP method
+//   - path: The path relative to the base URL
+//   - in: The value encoded as the request body (optional)
+//   - out: The value the response body is decoded into (optional)
+//
+// Returns:
+//   - error: nil if successful, error otherwise
+//
+// Example:
+//
+//	var user User
+//	err := client.DoJSON(ctx, http.MethodGet, "/users/"+id, nil, &user)
+func (c *Client) DoJSON(ctx context.Context, method, path string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := c.NewRequest(ctx, method, path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := c.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= nethttp.StatusBadRequest {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		return &StatusError{Client: c.cfg.Name, StatusCode: resp.StatusCode, Body: data}
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// StatusError is returned by DoJSON when the upstream answers with an error status.
+type StatusError struct {
+	Client     string
+	StatusCode int
+	Body       []byte
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("http client %q: unexpected status %d", e.Client, e.StatusCode)
+}
+
+// ClientPool holds the HTTP clients built from the `http.clients` config section.
+type ClientPool struct {
+	clients map[string]*Client
+}
+
+// NewClientPool creates a client for every entry under `http.clients` and closes
+// idle connections when the application stops.
+//
+// Parameters:
+//   - cfg: Application configuration
+//   - logger: Logger instance
+//   - lc: Fx lifecycle
+//
+// Returns:
+//   - *ClientPool: The pool with one client per configured upstream
+//   - error: nil if successful, error if any client is misconfigured
+func NewClientPool(cfg *config.Config, logger *zap.Logger, lc fx.Lifecycle) (*ClientPool, error) {
+	pool := &ClientPool{clients: map[string]*Client{}}
+	names := make([]string, 0)
+	for name := range cfg.GetStringMap("http.clients") {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		cc, err := ReadClientConfig(cfg, name)
+		if err != nil {
+			return nil, err
+		}
+		pool.clients[strings.ToLower(name)] = NewClient(cc, logger)
+		logger.Debug("http client registered", zap.String("name", name), zap.String("baseUrl", cc.BaseURL))
+	}
+
+	lc.Append(fx.Hook{
+		OnStop: func(ctx context.Context) error {
+			for _, c := range pool.clients {
+				c.base.CloseIdleConnections()
+			}
+			return nil
+		},
+	})
+	return pool, nil
+}
+
+// Client retrieves a named client. Names are case-insensitive, like the configuration keys.
+//
+// Parameters:
+//   - name: The client name (key under `http.clients`)
+//
+// Returns:
+//   - *Client: The HTTP client
+//   - error: nil if successful, error if the client is not configured
+func (p *ClientPool) Client(name string) (*Client, error) {
+	c, ok := p.clients[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("http client %q is not configured in http.clients", name)
+	}
+	return c, nil
+}
+
+// ReplaceTransport replaces the network transport of a named client, keeping the retry,
+// circuit breaker and instrumentation layers. Use it in tests with a FixtureTransport.
+//
+// Parameters:
+//   - name: The client name (key under `http.clients`)
+//   - rt: The transport to use
+//
+// Returns:
+//   - fx.Option: Fx option decorating the *ClientPool
+//
+// Example:
+//
+//	fx.New(
+//	    http.HTTPClientModule,
+//	    http.ReplaceTransport("payments", http.NewFixtureTransport(fixtures...)),
+//	)
+func ReplaceTransport(name string, rt nethttp.RoundTripper) fx.Option {
+	return fx.Decorate(func(pool *ClientPool) (*ClientPool, error) {
+		c, err := pool.Client(name)
+		if err != nil {
+			return nil, err
+		}
+		c.SetTransport(rt)
+		return pool, nil
+	})
+}
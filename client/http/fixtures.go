@@ -0,0 +1,181 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	nethttp "net/http"
+	"os"
+	"sync"
+)
+
+// Fixture is a recorded request/response pair.
+type Fixture struct {
+	Method  string            `json:"method"`
+	Path    string            `json:"path"`
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    string            `json:"body,omitempty"`
+}
+
+func (f Fixture) matches(req *nethttp.Request) bool {
+	if f.Method != "" && f.Method != req.Method {
+		return false
+	}
+	return f.Path == req.URL.RequestURI() || f.Path == req.URL.Path
+}
+
+func (f Fixture) response(req *nethttp.Request) *nethttp.Response {
+	status := f.Status
+	if status == 0 {
+		status = nethttp.StatusOK
+	}
+	header := nethttp.Header{}
+	for k, v := range f.Headers {
+		header.Set(k, v)
+	}
+	return &nethttp.Response{
+		Status:        fmt.Sprintf("%d %s", status, nethttp.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewBufferString(f.Body)),
+		ContentLength: int64(len(f.Body)),
+		Request:       req,
+	}
+}
+
+// FixtureTransport answers requests from recorded fixtures instead of the network.
+// Fixtures matching the same request are returned in order; the last one is repeated.
+type FixtureTransport struct {
+	mu       sync.Mutex
+	fixtures []Fixture
+	used     map[int]bool
+}
+
+// NewFixtureTransport creates a transport serving the given fixtures.
+//
+// Parameters:
+//   - fixtures: The recorded fixtures
+//
+// Returns:
+//   - *FixtureTransport: The transport
+//
+// Example:
+//
+//	rt := http.NewFixtureTransport(http.Fixture{
+//	    Method: "GET", Path: "/users/1", Status: 200, Body: `{"id":"1"}`,
+//	})
+func NewFixtureTransport(fixtures ...Fixture) *FixtureTransport {
+	return &FixtureTransport{fixtures: fixtures, used: map[int]bool{}}
+}
+
+// LoadFixtures reads fixtures from JSON files (each holding an array of Fixture) matching a glob pattern.
+//
+// Parameters:
+//   - fsys: The file system (e.g. os.DirFS("testdata") or an embed.FS)
+//   - pattern: The glob pattern (e.g. "fixtures/*.json")
+//
+// Returns:
+//   - *FixtureTransport: The transport serving the loaded fixtures
+//   - error: nil if successful, error otherwise
+func LoadFixtures(fsys fs.FS, pattern string) (*FixtureTransport, error) {
+	files, err := fs.Glob(fsys, pattern)
+	if err != nil {
+		return nil, err
+	}
+	var fixtures []Fixture
+	for _, file := range files {
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+		var items []Fixture
+		if err := json.Unmarshal(data, &items); err != nil {
+			return nil, fmt.Errorf("failed to parse fixtures %s: %w", file, err)
+		}
+		fixtures = append(fixtures, items...)
+	}
+	return NewFixtureTransport(fixtures...), nil
+}
+
+func (t *FixtureTransport) RoundTrip(req *nethttp.Request) (*nethttp.Response, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	last := -1
+	for i, f := range t.fixtures {
+		if !f.matches(req) {
+			continue
+		}
+		if !t.used[i] {
+			t.used[i] = true
+			return f.response(req), nil
+		}
+		last = i
+	}
+	if last >= 0 {
+		return t.fixtures[last].response(req), nil
+	}
+	return nil, fmt.Errorf("no fixture for %s %s", req.Method, req.URL.RequestURI())
+}
+
+// RecordingTransport forwards requests to the next transport and records them as fixtures.
+type RecordingTransport struct {
+	Next nethttp.RoundTripper
+
+	mu       sync.Mutex
+	fixtures []Fixture
+}
+
+func (t *RecordingTransport) RoundTrip(req *nethttp.Request) (*nethttp.Response, error) {
+	next := t.Next
+	if next == nil {
+		next = nethttp.DefaultTransport
+	}
+	resp, err := next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	headers := map[string]string{}
+	if ct := resp.Header.Get("Content-Type"); ct != "" {
+		headers["Content-Type"] = ct
+	}
+	t.mu.Lock()
+	t.fixtures = append(t.fixtures, Fixture{
+		Method:  req.Method,
+		Path:    req.URL.RequestURI(),
+		Status:  resp.StatusCode,
+		Headers: headers,
+		Body:    string(body),
+	})
+	t.mu.Unlock()
+	return resp, nil
+}
+
+// Save writes the recorded fixtures to a JSON file readable by LoadFixtures.
+//
+// Parameters:
+//   - path: The destination file
+//
+// Returns:
+//   - error: nil if successful, error otherwise
+func (t *RecordingTransport) Save(path string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	data, err := json.MarshalIndent(t.fixtures, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
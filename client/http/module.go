@@ -0,0 +1,8 @@
+package http
+
+import "go.uber.org/fx"
+
+// HTTPClientModule provides the *ClientPool built from the `http.clients` config section.
+var HTTPClientModule = fx.Module("liquor-http-client", fx.Provide(
+	NewClientPool,
+))
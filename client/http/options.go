@@ -0,0 +1,111 @@
+package http
+
+import (
+	"fmt"
+	"net"
+	nethttp "net/http"
+	"time"
+
+	"github.com/go-liquor/liquor-sdk/config"
+)
+
+// ClientConfig holds the settings of a named HTTP client read from `http.clients.<name>`.
+type ClientConfig struct {
+	Name           string
+	BaseURL        string
+	Timeout        time.Duration
+	MaxConns       int
+	ForwardAuth    bool
+	Retry          RetryConfig
+	CircuitBreaker CircuitBreakerConfig
+}
+
+// RetryConfig holds the retry policy applied to idempotent requests.
+type RetryConfig struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Jitter         float64
+}
+
+// CircuitBreakerConfig holds the circuit breaker settings of a client.
+type CircuitBreakerConfig struct {
+	Enabled          bool
+	FailureThreshold int
+	OpenTimeout      time.Duration
+}
+
+// ReadClientConfig reads the configuration of a named HTTP client.
+//
+// Parameters:
+//   - cfg: Application configuration
+//   - name: The client name (key under `http.clients`)
+//
+// Returns:
+//   - ClientConfig: The client settings with defaults applied
+//   - error: nil if successful, error if the base URL is missing
+func ReadClientConfig(cfg *config.Config, name string) (ClientConfig, error) {
+	prefix := "http.clients." + name + "."
+	cc := ClientConfig{
+		Name:        name,
+		BaseURL:     cfg.GetString(prefix + "baseUrl"),
+		Timeout:     cfg.GetDuration(prefix + "timeout"),
+		MaxConns:    cfg.GetInt(prefix + "maxConns"),
+		ForwardAuth: cfg.GetBool(prefix + "forwardAuth"),
+		Retry: RetryConfig{
+			MaxAttempts:    cfg.GetInt(prefix + "retry.maxAttempts"),
+			InitialBackoff: cfg.GetDuration(prefix + "retry.initialBackoff"),
+			MaxBackoff:     cfg.GetDuration(prefix + "retry.maxBackoff"),
+			Jitter:         cfg.GetFloat64(prefix + "retry.jitter"),
+		},
+		CircuitBreaker: CircuitBreakerConfig{
+			Enabled:          cfg.GetBool(prefix + "circuitBreaker.enabled"),
+			FailureThreshold: cfg.GetInt(prefix + "circuitBreaker.failureThreshold"),
+			OpenTimeout:      cfg.GetDuration(prefix + "circuitBreaker.openTimeout"),
+		},
+	}
+	if cc.BaseURL == "" {
+		return cc, fmt.Errorf("http client %q: missing config key %q", name, prefix+"baseUrl")
+	}
+	if cc.Timeout == 0 {
+		cc.Timeout = 30 * time.Second
+	}
+	if cc.Retry.InitialBackoff == 0 {
+		cc.Retry.InitialBackoff = 100 * time.Millisecond
+	}
+	if cc.Retry.MaxBackoff == 0 {
+		cc.Retry.MaxBackoff = 2 * time.Second
+	}
+	// an explicit 0 disables the jitter
+	if !cfg.IsSet(prefix + "retry.jitter") {
+		cc.Retry.Jitter = 0.2
+	}
+	if cc.CircuitBreaker.FailureThreshold == 0 {
+		cc.CircuitBreaker.FailureThreshold = 5
+	}
+	if cc.CircuitBreaker.OpenTimeout == 0 {
+		cc.CircuitBreaker.OpenTimeout = 30 * time.Second
+	}
+	return cc, nil
+}
+
+// baseTransport builds the network transport honoring the connection limits.
+func (c ClientConfig) baseTransport() *nethttp.Transport {
+	transport := &nethttp.Transport{
+		Proxy: nethttp.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   10 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		ForceAttemptHTTP2:     true,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: time.Second,
+		MaxIdleConnsPerHost:   nethttp.DefaultMaxIdleConnsPerHost,
+	}
+	if c.MaxConns > 0 {
+		transport.MaxConnsPerHost = c.MaxConns
+		transport.MaxIdleConnsPerHost = c.MaxConns
+	}
+	return transport
+}
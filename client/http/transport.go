@@ -0,0 +1,134 @@
+package http
+
+import (
+	"errors"
+	"io"
+	"math"
+	"math/rand"
+	nethttp "net/http"
+	"time"
+
+	"github.com/go-liquor/liquor-sdk/helpers/lqcontext"
+	"go.uber.org/zap"
+)
+
+var idempotentMethods = map[string]bool{
+	nethttp.MethodGet:     true,
+	nethttp.MethodHead:    true,
+	nethttp.MethodOptions: true,
+	nethttp.MethodPut:     true,
+	nethttp.MethodDelete:  true,
+	nethttp.MethodTrace:   true,
+}
+
+var retryableStatus = map[int]bool{
+	nethttp.StatusTooManyRequests:     true,
+	nethttp.StatusBadGateway:          true,
+	nethttp.StatusServiceUnavailable:  true,
+	nethttp.StatusGatewayTimeout:      true,
+	nethttp.StatusInternalServerError: true,
+}
+
+// retryTransport retries idempotent requests on network errors and retryable status codes
+// using exponential backoff with jitter.
+type retryTransport struct {
+	next nethttp.RoundTripper
+	cfg  RetryConfig
+}
+
+func (r *retryTransport) RoundTrip(req *nethttp.Request) (*nethttp.Response, error) {
+	if r.cfg.MaxAttempts <= 1 || !idempotentMethods[req.Method] || (req.Body != nil && req.GetBody == nil) {
+		return r.next.RoundTrip(req)
+	}
+
+	var (
+		resp *nethttp.Response
+		err  error
+	)
+	for attempt := 1; ; attempt++ {
+		attemptReq := req
+		if attempt > 1 && req.GetBody != nil {
+			body, bodyErr := req.GetBody()
+			if bodyErr != nil {
+				return nil, bodyErr
+			}
+			attemptReq = req.Clone(req.Context())
+			attemptReq.Body = body
+		}
+
+		resp, err = r.next.RoundTrip(attemptReq)
+		if !r.shouldRetry(resp, err) || attempt >= r.cfg.MaxAttempts {
+			return resp, err
+		}
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(r.backoff(attempt))
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (r *retryTransport) shouldRetry(resp *nethttp.Response, err error) bool {
+	if err != nil {
+		return !errors.Is(err, ErrCircuitOpen)
+	}
+	return retryableStatus[resp.StatusCode]
+}
+
+func (r *retryTransport) backoff(attempt int) time.Duration {
+	d := float64(r.cfg.InitialBackoff) * math.Pow(2, float64(attempt-1))
+	if d > float64(r.cfg.MaxBackoff) {
+		d = float64(r.cfg.MaxBackoff)
+	}
+	d += d * r.cfg.Jitter * (rand.Float64()*2 - 1)
+	return time.Duration(d)
+}
+
+// instrumentTransport propagates the request ID and trace headers stored in the request
+// context (and the bearer token with forwardAuth) and logs every request.
+type instrumentTransport struct {
+	name        string
+	next        nethttp.RoundTripper
+	logger      *zap.Logger
+	forwardAuth bool
+}
+
+func (t *instrumentTransport) RoundTrip(req *nethttp.Request) (*nethttp.Response, error) {
+	headers := lqcontext.Headers(req.Context())
+	if auth := lqcontext.AuthHeader(req.Context()); auth != "" && t.forwardAuth {
+		headers[lqcontext.HeaderAuthorization] = auth
+	}
+	if len(headers) > 0 {
+		req = req.Clone(req.Context())
+		for k, v := range headers {
+			if req.Header.Get(k) == "" {
+				req.Header.Set(k, v)
+			}
+		}
+	}
+
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	fields := []zap.Field{
+		zap.String("client", t.name),
+		zap.String("method", req.Method),
+		zap.String("url", req.URL.Redacted()),
+		zap.Duration("duration", time.Since(start)),
+	}
+	if id := req.Header.Get(lqcontext.HeaderRequestID); id != "" {
+		fields = append(fields, zap.String("requestId", id))
+	}
+	if err != nil {
+		t.logger.Warn("http client request failed", append(fields, zap.Error(err))...)
+		return nil, err
+	}
+	t.logger.Debug("http client request", append(fields, zap.Int("status", resp.StatusCode))...)
+	return resp, nil
+}
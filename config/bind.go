@@ -0,0 +1,256 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/mitchellh/mapstructure"
+	"go.uber.org/fx"
+)
+
+// BindError lists every invalid or missing key of a bound section.
+type BindError struct {
+	// Prefix is the bound section.
+	Prefix string
+	// Problems are the invalid keys, as "<key>: <problem>".
+	Problems []string
+}
+
+func (e *BindError) Error() string {
+	section := e.Prefix
+	if section == "" {
+		section = "root"
+	}
+	return fmt.Sprintf("config: invalid section %s:\n  - %s", section, strings.Join(e.Problems, "\n  - "))
+}
+
+var (
+	timeType = reflect.TypeOf(time.Time{})
+	validate = newValidator()
+)
+
+func newValidator() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+	v.RegisterTagNameFunc(func(f reflect.StructField) string {
+		return fieldKey(f)
+	})
+	return v
+}
+
+// Bind reads a configuration section into a typed struct, then validates it.
+//
+// The keys are the `config` tags of the fields (default: the field name in lower camel
+// case, e.g. MaxAttempts is maxAttempts), and nested structs are nested sections.
+// A missing key takes the value of the `default` tag, and the `validate` tags
+// (https://github.com/go-playground/validator) check the values.
+//
+// Parameters:
+// - cfg: The configuration.
+// - prefix: The section (e.g. "queue"), or "" for the whole configuration.
+//
+// Returns:
+// - The bound struct.
+// - A *BindError listing every invalid or missing key, nil otherwise.
+//
+// Example:
+//
+//	type MailerConfig struct {
+//	    Host     string        `validate:"required,hostname"`
+//	    Port     int           `default:"587" validate:"min=1,max=65535"`
+//	    Timeout  time.Duration `default:"10s"`
+//	    From     string        `config:"sender" validate:"required,email"`
+//	}
+//
+//	mailer, err := config.Bind[MailerConfig](cfg, "mailer")
+func Bind[T any](cfg *Config, prefix string) (T, error) {
+	var out T
+	typ := reflect.TypeOf(out)
+	if typ == nil || typ.Kind() != reflect.Struct {
+		return out, fmt.Errorf("config: Bind expects a struct, got %T", out)
+	}
+
+	bindErr := &BindError{Prefix: prefix}
+	values := map[string]any{}
+	collect(cfg, typ, prefix, values)
+
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook: mapstructure.ComposeDecodeHookFunc(
+			mapstructure.StringToTimeDurationHookFunc(),
+			mapstructure.StringToTimeHookFunc(time.RFC3339),
+			mapstructure.StringToSliceHookFunc(","),
+		),
+		WeaklyTypedInput: true,
+		Squash:           true,
+		TagName:          "config",
+		Result:           &out,
+	})
+	if err != nil {
+		return out, err
+	}
+	if err := decoder.Decode(values); err != nil {
+		var decodeErr *mapstructure.Error
+		if !errors.As(err, &decodeErr) {
+			return out, err
+		}
+		for _, problem := range decodeErr.Errors {
+			bindErr.Problems = append(bindErr.Problems, decodeProblem(prefix, problem))
+		}
+	}
+
+	if err := validate.Struct(out); err != nil {
+		var invalid validator.ValidationErrors
+		if !errors.As(err, &invalid) {
+			return out, err
+		}
+		for _, fe := range invalid {
+			bindErr.Problems = append(bindErr.Problems, validationProblem(prefix, fe))
+		}
+	}
+
+	if len(bindErr.Problems) > 0 {
+		return out, bindErr
+	}
+	return out, nil
+}
+
+// Provide provides a typed configuration section to the app (see Bind). The section is
+// bound on start, so an invalid section fails the startup.
+//
+// Parameters:
+// - prefix: The section.
+//
+// Returns:
+// - An fx.Option providing T.
+//
+// Example:
+//
+//	app.NewApp(
+//	    config.Provide[MailerConfig]("mailer"),
+//	    app.NewModule("mailer", NewMailer), // func NewMailer(cfg MailerConfig) *Mailer
+//	)
+func Provide[T any](prefix string) fx.Option {
+	return fx.Options(
+		fx.Provide(func(cfg *Config) (T, error) {
+			return Bind[T](cfg, prefix)
+		}),
+		fx.Invoke(func(T) {}),
+	)
+}
+
+// collect reads the values of the fields of typ into values: nested structs are read
+// key by key, so every source of the configuration applies to them.
+func collect(cfg *Config, typ reflect.Type, prefix string, values map[string]any) {
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			collect(cfg, field.Type, prefix, values)
+			continue
+		}
+		name := fieldKey(field)
+		if name == "-" {
+			continue
+		}
+		key := joinKey(prefix, name)
+
+		if inner := structType(field.Type); inner != nil {
+			nested := map[string]any{}
+			collect(cfg, inner, key, nested)
+			if len(nested) > 0 {
+				values[name] = nested
+			}
+			continue
+		}
+		if cfg.IsSet(key) {
+			values[name] = cfg.Get(key)
+		} else if def, ok := field.Tag.Lookup("default"); ok {
+			values[name] = def
+		}
+	}
+}
+
+// structType returns the struct type read as a nested section, or nil.
+func structType(t reflect.Type) reflect.Type {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || t == timeType {
+		return nil
+	}
+	return t
+}
+
+// fieldKey returns the key of a field: its `config` tag, or its name in lower camel case.
+func fieldKey(f reflect.StructField) string {
+	if tag, ok := f.Tag.Lookup("config"); ok {
+		if name, _, _ := strings.Cut(tag, ","); name != "" {
+			return name
+		}
+	}
+	return lowerCamel(f.Name)
+}
+
+// lowerCamel lowers the leading capitals of a name: MaxAttempts is maxAttempts, URL is url
+// and URLPath is urlPath.
+func lowerCamel(name string) string {
+	runes := []rune(name)
+	for i := range runes {
+		if !unicode.IsUpper(runes[i]) {
+			break
+		}
+		if i > 0 && i+1 < len(runes) && unicode.IsLower(runes[i+1]) {
+			break
+		}
+		runes[i] = unicode.ToLower(runes[i])
+	}
+	return string(runes)
+}
+
+func joinKey(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
+
+// decodeProblem prefixes a mapstructure error ("cannot parse 'TLS.Port' as int: ...") with
+// the key of the field it names.
+func decodeProblem(prefix, problem string) string {
+	if _, rest, ok := strings.Cut(problem, "'"); ok {
+		if name, _, ok := strings.Cut(rest, "'"); ok {
+			segments := strings.Split(name, ".")
+			for i, segment := range segments {
+				segments[i] = lowerCamel(segment)
+			}
+			return joinKey(prefix, strings.Join(segments, ".")) + ": " + problem
+		}
+	}
+	return prefix + ": " + problem
+}
+
+func validationProblem(prefix string, fe validator.FieldError) string {
+	// the namespace starts with the name of the bound struct
+	_, path, _ := strings.Cut(fe.Namespace(), ".")
+	key := joinKey(prefix, path)
+	switch fe.Tag() {
+	case "required":
+		return key + ": is required"
+	case "min", "gte":
+		return fmt.Sprintf("%s: must be at least %s (got %v)", key, fe.Param(), fe.Value())
+	case "max", "lte":
+		return fmt.Sprintf("%s: must be at most %s (got %v)", key, fe.Param(), fe.Value())
+	case "oneof":
+		return fmt.Sprintf("%s: must be one of [%s] (got %v)", key, fe.Param(), fe.Value())
+	}
+	if fe.Param() != "" {
+		return fmt.Sprintf("%s: fails %s=%s (got %v)", key, fe.Tag(), fe.Param(), fe.Value())
+	}
+	return fmt.Sprintf("%s: fails %s (got %v)", key, fe.Tag(), fe.Value())
+}
@@ -1,9 +1,39 @@
 package config
 
-import "github.com/spf13/viper"
+import (
+	"sync"
+	"time"
+
+	"github.com/spf13/viper"
+)
 
 type Config struct {
-	stg *viper.Viper
+	mu           sync.RWMutex
+	stg          *viper.Viper
+	sources      []Source
+	envPrefix    string
+	envOverrides map[string]string
+	secretKeys   map[string]bool
+	secretValues []string
+	keySources   map[string]Source
+	reads        sync.Map
+
+	// state of the reloads (see watch.go)
+	opts      LoadOptions
+	paths     []string
+	layers    []layer
+	secrets   *secretStore
+	overrides map[string]interface{}
+	reloadMu  sync.Mutex
+	watchers  []*watcher
+	listeners []func(changed []string, err error)
+}
+
+// v returns the current settings, replaced on each reload.
+func (c *Config) v() *viper.Viper {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.stg
 }
 
 // Get retrieves a configuration value by its key as an interface{}.
@@ -14,7 +44,7 @@ type Config struct {
 // Returns:
 // - The value associated with the key as an interface{}.
 func (c *Config) Get(key string) interface{} {
-	return c.stg.Get(key)
+	return c.read(key).Get(key)
 }
 
 // GetString retrieves a configuration value by its key as a string.
@@ -25,7 +55,7 @@ func (c *Config) Get(key string) interface{} {
 // Returns:
 // - The value associated with the key as a string.
 func (c *Config) GetString(key string) string {
-	return c.stg.GetString(key)
+	return c.read(key).GetString(key)
 }
 
 // GetStringSlice retrieves a configuration value by its key as a list of string.
@@ -36,7 +66,7 @@ func (c *Config) GetString(key string) string {
 // Returns:
 // - The value associated with the key as a list of string.
 func (c *Config) GetStringSlice(key string) []string {
-	return c.stg.GetStringSlice(key)
+	return c.read(key).GetStringSlice(key)
 }
 
 // GetInt retrieves a configuration value by its key as an int.
@@ -47,7 +77,7 @@ func (c *Config) GetStringSlice(key string) []string {
 // Returns:
 // - The value associated with the key as an int.
 func (c *Config) GetInt(key string) int {
-	return c.stg.GetInt(key)
+	return c.read(key).GetInt(key)
 }
 
 // GetInt64 retrieves a configuration value by its key as an int64.
@@ -58,7 +88,7 @@ func (c *Config) GetInt(key string) int {
 // Returns:
 // - The value associated with the key as an int64.
 func (c *Config) GetInt64(key string) int64 {
-	return c.stg.GetInt64(key)
+	return c.read(key).GetInt64(key)
 }
 
 // GetBool retrieves a configuration value by its key as a bool.
@@ -69,7 +99,7 @@ func (c *Config) GetInt64(key string) int64 {
 // Returns:
 // - The value associated with the key as a bool.
 func (c *Config) GetBool(key string) bool {
-	return c.stg.GetBool(key)
+	return c.read(key).GetBool(key)
 }
 
 // GetFloat64 retrieves a configuration value by its key as a float64.
@@ -80,7 +110,63 @@ func (c *Config) GetBool(key string) bool {
 // Returns:
 // - The value associated with the key as a float64.
 func (c *Config) GetFloat64(key string) float64 {
-	return c.stg.GetFloat64(key)
+	return c.read(key).GetFloat64(key)
+}
+
+// GetDuration retrieves a configuration value by its key as a time.Duration.
+//
+// Parameters:
+// - key: The key identifying the configuration value (e.g. "5s", "100ms").
+//
+// Returns:
+// - The value associated with the key as a time.Duration.
+func (c *Config) GetDuration(key string) time.Duration {
+	return c.read(key).GetDuration(key)
+}
+
+// GetStringMap retrieves a configuration section by its key as a map.
+//
+// Parameters:
+// - key: The key identifying the configuration section.
+//
+// Returns:
+// - The section associated with the key as a map[string]interface{}.
+func (c *Config) GetStringMap(key string) map[string]interface{} {
+	return c.read(key).GetStringMap(key)
+}
+
+// IsSet checks whether a configuration key has a value.
+//
+// Parameters:
+// - key: The key identifying the configuration value.
+//
+// Returns:
+// - true if the key has a value, false otherwise.
+func (c *Config) IsSet(key string) bool {
+	return c.read(key).IsSet(key)
+}
+
+// Set overrides the value of a configuration key. The override is kept across reloads.
+//
+// Parameters:
+// - key: The key identifying the configuration value.
+// - value: The value to set.
+func (c *Config) Set(key string, value interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.overrides == nil {
+		c.overrides = map[string]interface{}{}
+	}
+	c.overrides[key] = value
+	c.stg.Set(key, value)
+}
+
+// AllSettings retrieves every configuration value as a nested map.
+//
+// Returns:
+// - The configuration values as a map[string]interface{}.
+func (c *Config) AllSettings() map[string]interface{} {
+	return c.v().AllSettings()
 }
 
 // GetAppName retrieves the name of the application from the configuration.
@@ -91,6 +177,14 @@ func (c *Config) GetAppName() string {
 	return c.GetString("app.name")
 }
 
+// GetAppEnv retrieves the environment the application runs in (e.g. dev, test, prod).
+//
+// Returns:
+// - The application environment as a string.
+func (c *Config) GetAppEnv() string {
+	return c.GetString("app.env")
+}
+
 // IsDebug checks whether the application is in debug mode.
 //
 // Returns:
@@ -120,7 +214,7 @@ func (c *Config) GetServerHttpCorsDefaultAllow() bool {
 // Returns:
 // - A slice of strings containing the allowed origins.
 func (c *Config) GetServerHttpCorsAllowOrigins() []string {
-	return c.stg.GetStringSlice("server.http.cors.origin")
+	return c.GetStringSlice("server.http.cors.origin")
 }
 
 // GetServerHttpCorsAllowMethods retrieves the list of allowed HTTP methods for CORS on the HTTP server.
@@ -128,7 +222,7 @@ func (c *Config) GetServerHttpCorsAllowOrigins() []string {
 // Returns:
 // - A slice of strings containing the allowed HTTP methods.
 func (c *Config) GetServerHttpCorsAllowMethods() []string {
-	return c.stg.GetStringSlice("server.http.cors.methods")
+	return c.GetStringSlice("server.http.cors.methods")
 }
 
 // GetServerHttpCorsAllowHeaders retrieves the list of allowed headers for CORS on the HTTP server.
@@ -136,7 +230,7 @@ func (c *Config) GetServerHttpCorsAllowMethods() []string {
 // Returns:
 // - A slice of strings containing the allowed headers.
 func (c *Config) GetServerHttpCorsAllowHeaders() []string {
-	return c.stg.GetStringSlice("server.http.cors.headers")
+	return c.GetStringSlice("server.http.cors.headers")
 }
 
 // GetServerHttpCorsAllowCredentials checks if credentials are allowed in CORS requests for the HTTP server.
@@ -0,0 +1,201 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ConsulOptions configures a ConsulProvider.
+type ConsulOptions struct {
+	// Address is the address of the Consul agent (default http://127.0.0.1:8500).
+	Address string
+	// Token is the ACL token (optional).
+	Token string
+	// Key is the key holding a YAML or JSON document, e.g. "apps/payments/config.yaml".
+	Key string
+	// Prefix is the prefix of a tree of keys, one per value: "apps/payments/" with the key
+	// "apps/payments/database/host" sets database.host. Used when Key is empty.
+	Prefix string
+	// Wait bounds the blocking queries of the watch (default 5m).
+	Wait time.Duration
+	// Client is the HTTP client (default: a client timing out 30s after the Wait of the
+	// blocking queries, which Consul extends by up to Wait/16).
+	Client *http.Client
+}
+
+// ConsulProvider is a Provider of the Consul KV store, watched with blocking queries.
+type ConsulProvider struct {
+	opts ConsulOptions
+}
+
+// NewConsulProvider creates the provider of the Consul KV store from the configuration.
+//
+// Config keys:
+// - config.consul.address: The address of the Consul agent (default: CONSUL_HTTP_ADDR, then http://127.0.0.1:8500).
+// - config.consul.token: The ACL token (default: CONSUL_HTTP_TOKEN).
+// - config.consul.key: The key holding a YAML or JSON document.
+// - config.consul.prefix: The prefix of a tree of keys, when key isn't set.
+//
+// Parameters:
+// - cfg: The configuration.
+//
+// Returns:
+// - The provider, or an error if neither the key nor the prefix is set.
+func NewConsulProvider(cfg *Config) (*ConsulProvider, error) {
+	opts := ConsulOptions{
+		Address: cfg.GetString("config.consul.address"),
+		Token:   cfg.GetString("config.consul.token"),
+		Key:     cfg.GetString("config.consul.key"),
+		Prefix:  cfg.GetString("config.consul.prefix"),
+	}
+	if opts.Address == "" {
+		opts.Address = os.Getenv("CONSUL_HTTP_ADDR")
+	}
+	if opts.Token == "" {
+		opts.Token = os.Getenv("CONSUL_HTTP_TOKEN")
+	}
+	return NewConsulProviderWith(opts)
+}
+
+// NewConsulProviderWith creates a provider of the Consul KV store.
+//
+// Parameters:
+// - opts: The options.
+//
+// Returns:
+// - The provider, or an error if neither the key nor the prefix is set.
+func NewConsulProviderWith(opts ConsulOptions) (*ConsulProvider, error) {
+	if opts.Key == "" && opts.Prefix == "" {
+		return nil, fmt.Errorf("config: config.consul.key or config.consul.prefix is required")
+	}
+	if opts.Address == "" {
+		opts.Address = "http://127.0.0.1:8500"
+	}
+	if !strings.Contains(opts.Address, "://") {
+		opts.Address = "http://" + opts.Address
+	}
+	if opts.Wait <= 0 {
+		opts.Wait = 5 * time.Minute
+	}
+	if opts.Client == nil {
+		opts.Client = providerClient(opts.Wait + opts.Wait/16 + providerTimeout)
+	}
+	return &ConsulProvider{opts: opts}, nil
+}
+
+// Name implements Provider.
+func (p *ConsulProvider) Name() string {
+	if p.opts.Key != "" {
+		return "consul:" + p.opts.Key
+	}
+	return "consul:" + p.opts.Prefix
+}
+
+// Fetch implements Provider.
+func (p *ConsulProvider) Fetch(ctx context.Context) (map[string]interface{}, error) {
+	values, _, err := p.query(ctx, 0)
+	return values, err
+}
+
+// Watch implements WatchingProvider.
+func (p *ConsulProvider) Watch(ctx context.Context, update func(values map[string]interface{})) error {
+	values, index, err := p.query(ctx, 0)
+	if err != nil {
+		return err
+	}
+	// the changes since the fetch
+	update(values)
+	for ctx.Err() == nil {
+		values, next, err := p.query(ctx, index)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		// the index goes back when the Consul state is restored: query from the start
+		if next < index {
+			next = 0
+		}
+		if next != index {
+			update(values)
+		}
+		index = next
+	}
+	return nil
+}
+
+type consulEntry struct {
+	Key   string
+	Value []byte
+}
+
+// query reads the key (or the prefix), blocking until its index passes index when set.
+func (p *ConsulProvider) query(ctx context.Context, index uint64) (map[string]interface{}, uint64, error) {
+	path := p.opts.Key
+	params := url.Values{}
+	if path == "" {
+		path = p.opts.Prefix
+		params.Set("recurse", "true")
+	}
+	if index > 0 {
+		params.Set("index", strconv.FormatUint(index, 10))
+		params.Set("wait", p.opts.Wait.String())
+	}
+	endpoint := strings.TrimRight(p.opts.Address, "/") + "/v1/kv/" + strings.TrimLeft(path, "/") + "?" + params.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	if p.opts.Token != "" {
+		req.Header.Set("X-Consul-Token", p.opts.Token)
+	}
+	res, err := p.opts.Client.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer res.Body.Close()
+	next, _ := strconv.ParseUint(res.Header.Get("X-Consul-Index"), 10, 64)
+
+	var entries []consulEntry
+	switch {
+	case res.StatusCode == http.StatusNotFound && p.opts.Key == "":
+		// an empty tree
+	case res.StatusCode == http.StatusNotFound:
+		return nil, 0, fmt.Errorf("consul key %s not found", p.opts.Key)
+	case res.StatusCode != http.StatusOK:
+		return nil, 0, statusError("consul", res)
+	default:
+		if err := json.NewDecoder(res.Body).Decode(&entries); err != nil {
+			return nil, 0, fmt.Errorf("consul: invalid response: %w", err)
+		}
+	}
+
+	if p.opts.Key != "" {
+		if len(entries) == 0 {
+			return nil, 0, fmt.Errorf("consul key %s not found", p.opts.Key)
+		}
+		values, err := decodeDocument(entries[0].Value)
+		if err != nil {
+			return nil, 0, fmt.Errorf("invalid consul key %s: %w", p.opts.Key, err)
+		}
+		return values, next, nil
+	}
+	values := map[string]interface{}{}
+	for _, entry := range entries {
+		key := strings.TrimPrefix(entry.Key, p.opts.Prefix)
+		// the folders have no value
+		if strings.Trim(key, "/") == "" || strings.HasSuffix(key, "/") {
+			continue
+		}
+		setPath(values, key, "/", string(entry.Value))
+	}
+	return values, next, nil
+}
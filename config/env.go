@@ -0,0 +1,101 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// envName returns the variable of a key: app.name is APP_NAME, or LIQUOR_APP_NAME with the LIQUOR prefix.
+func envName(prefix, key string) string {
+	name := strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+	if prefix == "" {
+		return name
+	}
+	return prefix + "_" + name
+}
+
+// normalizeEnvPrefix accepts the prefix with or without its trailing underscore.
+func normalizeEnvPrefix(prefix string) string {
+	return strings.ToUpper(strings.TrimSuffix(prefix, "_"))
+}
+
+// applyEnv sets the keys overridden by the environment and returns them with their variable.
+//
+// A variable overrides the key of the files it maps to (SERVER_HTTP_PORT is server.http.port).
+// With a prefix, the other prefixed variables add keys, each underscore being a level
+// (LIQUOR_TENANTS_ACME_HOST is tenants.acme.host). The values of slices are comma-separated
+// (or JSON arrays), and JSON objects set maps.
+func applyEnv(vp *viper.Viper, prefix string) map[string]string {
+	known := map[string]string{}
+	for _, key := range vp.AllKeys() {
+		known[envName(prefix, key)] = key
+	}
+
+	overrides := map[string]string{}
+	for _, entry := range os.Environ() {
+		name, value, _ := strings.Cut(entry, "=")
+		key, ok := known[strings.ToUpper(name)]
+		if !ok {
+			if prefix == "" || !strings.HasPrefix(strings.ToUpper(name), prefix+"_") {
+				continue
+			}
+			key = strings.ToLower(strings.ReplaceAll(name[len(prefix)+1:], "_", "."))
+			if key == "" {
+				continue
+			}
+		}
+		vp.Set(key, envValue(vp.Get(key), value))
+		overrides[key] = name
+	}
+	return overrides
+}
+
+// envValue converts a variable to the type of the current value of its key.
+func envValue(current any, value string) any {
+	trimmed := strings.TrimSpace(value)
+	if strings.HasPrefix(trimmed, "[") || strings.HasPrefix(trimmed, "{") {
+		var decoded any
+		if err := json.Unmarshal([]byte(trimmed), &decoded); err == nil {
+			return decoded
+		}
+	}
+	switch current.(type) {
+	case []any, []string:
+		if trimmed == "" {
+			return []string{}
+		}
+		items := strings.Split(value, ",")
+		for i := range items {
+			items[i] = strings.TrimSpace(items[i])
+		}
+		return items
+	}
+	return value
+}
+
+// EnvOverrides returns the keys overridden by environment variables, with their variable.
+//
+// Returns:
+// - A map of the overridden keys to the name of their variable.
+func (c *Config) EnvOverrides() map[string]string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	overrides := make(map[string]string, len(c.envOverrides))
+	for k, v := range c.envOverrides {
+		overrides[k] = v
+	}
+	return overrides
+}
+
+// EnvPrefix returns the prefix of the environment variables (empty without prefix).
+//
+// Returns:
+// - The prefix, without its trailing underscore.
+func (c *Config) EnvPrefix() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.envPrefix
+}
@@ -0,0 +1,206 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// EtcdOptions configures an EtcdProvider.
+type EtcdOptions struct {
+	// Endpoint is the address of the etcd gRPC gateway (default http://127.0.0.1:2379).
+	Endpoint string
+	// Username and Password authenticate the requests (optional).
+	Username string
+	Password string
+	// Key is the key holding a YAML or JSON document, e.g. "/apps/payments/config.yaml".
+	Key string
+	// Prefix is the prefix of a tree of keys, one per value: "/apps/payments/" with the key
+	// "/apps/payments/database/host" sets database.host. Used when Key is empty.
+	Prefix string
+	// Client is the HTTP client (default: a client timing out after 30s).
+	Client *http.Client
+}
+
+// EtcdProvider is a polled Provider of etcd v3, read through its JSON gateway (/v3/kv/range).
+type EtcdProvider struct {
+	opts EtcdOptions
+
+	mu    sync.Mutex
+	token string
+}
+
+// NewEtcdProvider creates the provider of etcd from the configuration.
+//
+// Config keys:
+// - config.etcd.endpoint: The address of etcd (default http://127.0.0.1:2379).
+// - config.etcd.username: The user (optional).
+// - config.etcd.password: Its password.
+// - config.etcd.key: The key holding a YAML or JSON document.
+// - config.etcd.prefix: The prefix of a tree of keys, when key isn't set.
+//
+// Parameters:
+// - cfg: The configuration.
+//
+// Returns:
+// - The provider, or an error if neither the key nor the prefix is set.
+func NewEtcdProvider(cfg *Config) (*EtcdProvider, error) {
+	return NewEtcdProviderWith(EtcdOptions{
+		Endpoint: cfg.GetString("config.etcd.endpoint"),
+		Username: cfg.GetString("config.etcd.username"),
+		Password: cfg.GetString("config.etcd.password"),
+		Key:      cfg.GetString("config.etcd.key"),
+		Prefix:   cfg.GetString("config.etcd.prefix"),
+	})
+}
+
+// NewEtcdProviderWith creates a provider of etcd.
+//
+// Parameters:
+// - opts: The options.
+//
+// Returns:
+// - The provider, or an error if neither the key nor the prefix is set.
+func NewEtcdProviderWith(opts EtcdOptions) (*EtcdProvider, error) {
+	if opts.Key == "" && opts.Prefix == "" {
+		return nil, fmt.Errorf("config: config.etcd.key or config.etcd.prefix is required")
+	}
+	if opts.Endpoint == "" {
+		opts.Endpoint = "http://127.0.0.1:2379"
+	}
+	if !strings.Contains(opts.Endpoint, "://") {
+		opts.Endpoint = "http://" + opts.Endpoint
+	}
+	if opts.Client == nil {
+		opts.Client = providerClient(providerTimeout)
+	}
+	return &EtcdProvider{opts: opts}, nil
+}
+
+// Name implements Provider.
+func (p *EtcdProvider) Name() string {
+	if p.opts.Key != "" {
+		return "etcd:" + p.opts.Key
+	}
+	return "etcd:" + p.opts.Prefix
+}
+
+type etcdKV struct {
+	Key   []byte `json:"key"`
+	Value []byte `json:"value"`
+}
+
+// Fetch implements Provider.
+func (p *EtcdProvider) Fetch(ctx context.Context) (map[string]interface{}, error) {
+	request := map[string][]byte{"key": []byte(p.opts.Key)}
+	if p.opts.Key == "" {
+		request = map[string][]byte{"key": []byte(p.opts.Prefix), "range_end": prefixEnd(p.opts.Prefix)}
+	}
+	var response struct {
+		Kvs []etcdKV `json:"kvs"`
+	}
+	if err := p.post(ctx, "/v3/kv/range", request, &response); err != nil {
+		return nil, err
+	}
+
+	if p.opts.Key != "" {
+		if len(response.Kvs) == 0 {
+			return nil, fmt.Errorf("etcd key %s not found", p.opts.Key)
+		}
+		values, err := decodeDocument(response.Kvs[0].Value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid etcd key %s: %w", p.opts.Key, err)
+		}
+		return values, nil
+	}
+	values := map[string]interface{}{}
+	for _, kv := range response.Kvs {
+		key := strings.TrimPrefix(string(kv.Key), p.opts.Prefix)
+		if strings.Trim(key, "/") == "" {
+			continue
+		}
+		setPath(values, key, "/", string(kv.Value))
+	}
+	return values, nil
+}
+
+// post sends a request to the gateway, authenticated when a user is set. The token is
+// renewed once when it expired.
+func (p *EtcdProvider) post(ctx context.Context, path string, request, response interface{}) error {
+	token := ""
+	if p.opts.Username != "" {
+		var err error
+		if token, err = p.authenticate(ctx, false); err != nil {
+			return err
+		}
+	}
+	status, err := p.send(ctx, path, token, request, response)
+	if status == http.StatusUnauthorized && token != "" {
+		if token, err = p.authenticate(ctx, true); err != nil {
+			return err
+		}
+		_, err = p.send(ctx, path, token, request, response)
+	}
+	return err
+}
+
+func (p *EtcdProvider) send(ctx context.Context, path, token string, request, response interface{}) (int, error) {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return 0, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(p.opts.Endpoint, "/")+path, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", token)
+	}
+	res, err := p.opts.Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return res.StatusCode, statusError("etcd", res)
+	}
+	if err := json.NewDecoder(res.Body).Decode(response); err != nil {
+		return res.StatusCode, fmt.Errorf("etcd: invalid response: %w", err)
+	}
+	return res.StatusCode, nil
+}
+
+func (p *EtcdProvider) authenticate(ctx context.Context, renew bool) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.token != "" && !renew {
+		return p.token, nil
+	}
+	var response struct {
+		Token string `json:"token"`
+	}
+	request := map[string]string{"name": p.opts.Username, "password": p.opts.Password}
+	if _, err := p.send(ctx, "/v3/auth/authenticate", "", request, &response); err != nil {
+		return "", err
+	}
+	p.token = response.Token
+	return p.token, nil
+}
+
+// prefixEnd returns the end of the range of the keys starting with prefix.
+func prefixEnd(prefix string) []byte {
+	end := []byte(prefix)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return end[:i+1]
+		}
+	}
+	// every key
+	return []byte{0}
+}
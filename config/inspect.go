@@ -0,0 +1,150 @@
+package config
+
+import (
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// KeyInfo describes a key of the configuration (see Inspect).
+type KeyInfo struct {
+	// Key is the key, in lower case.
+	Key string `json:"key"`
+	// Source is the source of the value: a file ("base:config.yaml"), a variable
+	// ("env:SERVER_HTTP_PORT"), "override" (`--set`) or "default" when the key isn't set.
+	Source string `json:"source"`
+	// Value is the value, Redacted for the secrets.
+	Value interface{} `json:"value"`
+	// Secret reports whether the key holds a secret (see Config.IsSecret).
+	Secret bool `json:"secret"`
+	// Read reports whether the key was read by the app.
+	Read bool `json:"read"`
+}
+
+// read records the read of a key and returns the current settings.
+func (c *Config) read(key string) *viper.Viper {
+	c.reads.Store(strings.ToLower(key), struct{}{})
+	return c.v()
+}
+
+// Uses declares the keys or sections that the app reads later (e.g. on each message), so
+// they aren't reported by UnusedKeys.
+//
+// Parameters:
+// - keys: The keys or sections.
+func (c *Config) Uses(keys ...string) {
+	for _, key := range keys {
+		c.reads.Store(strings.ToLower(key), struct{}{})
+	}
+}
+
+// wasRead reports whether a key, or a section containing it, was read.
+func (c *Config) wasRead(key string) bool {
+	for k := key; ; {
+		if _, ok := c.reads.Load(k); ok {
+			return true
+		}
+		i := strings.LastIndex(k, ".")
+		if i < 0 {
+			return false
+		}
+		k = k[:i]
+	}
+}
+
+// Inspect describes the keys of the configuration: the keys set by a source and the
+// keys read by the app, with their source.
+//
+// Returns:
+// - The keys, in order.
+func (c *Config) Inspect() []KeyInfo {
+	c.mu.RLock()
+	vp := c.stg
+	prefix := c.envPrefix
+	keySources := c.keySources
+	secretKeys := c.secretKeys
+	overrides := c.overrides
+	keys := map[string]bool{}
+	sections := map[string]bool{}
+	for _, key := range vp.AllKeys() {
+		keys[key] = true
+		parts := strings.Split(key, ".")
+		for i := 1; i < len(parts); i++ {
+			sections[strings.Join(parts[:i], ".")] = true
+		}
+	}
+	c.mu.RUnlock()
+	c.reads.Range(func(k, _ any) bool {
+		// the sections read whole are described by their keys
+		if !sections[k.(string)] {
+			keys[k.(string)] = true
+		}
+		return true
+	})
+
+	infos := make([]KeyInfo, 0, len(keys))
+	for _, key := range sortedKeys(keys) {
+		info := KeyInfo{Key: key, Secret: secretKeys[key], Read: c.wasRead(key), Source: "default"}
+		if _, ok := overrides[key]; ok {
+			info.Source = "override"
+		} else if source, ok := keySources[key]; ok {
+			info.Source = source.String()
+		} else if name := envName(prefix, key); os.Getenv(name) != "" {
+			info.Source = "env:" + name
+		}
+		if info.Source != "default" {
+			info.Value = vp.Get(key)
+		}
+		if info.Secret {
+			info.Value = Redacted
+		}
+		infos = append(infos, info)
+	}
+	return infos
+}
+
+// UnusedKeys returns the keys set by the config files (or the in-memory layers) that the
+// app never read. The keys read later (e.g. on each request) are reported too, so call
+// it once the app is started.
+//
+// Parameters:
+// - ignore: The sections whose keys aren't reported (e.g. "tenants").
+//
+// Returns:
+// - The unused keys, in order.
+func (c *Config) UnusedKeys(ignore ...string) []string {
+	var unused []string
+	for _, info := range c.Inspect() {
+		if info.Read || info.Source == "default" || info.Source == "override" ||
+			strings.HasPrefix(info.Source, "env:") || strings.HasPrefix(info.Source, "dotenv:") {
+			continue
+		}
+		if ignored(info.Key, ignore) {
+			continue
+		}
+		unused = append(unused, info.Key)
+	}
+	return unused
+}
+
+func ignored(key string, sections []string) bool {
+	for _, section := range sections {
+		section = strings.ToLower(section)
+		if key == section || strings.HasPrefix(key, section+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// sortedKeys returns the keys of a map in order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,302 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/viper"
+	"github.com/subosito/gotenv"
+)
+
+// DefaultSecretsFile is the secrets overlay read after the config files.
+const DefaultSecretsFile = "/etc/secrets/config.yaml"
+
+// LoadOptions selects the sources of the configuration (see Load).
+type LoadOptions struct {
+	// Files are explicit config files (the `--config` flag), merged in order.
+	Files []string
+	// Env is the profile selecting config.<env>.yaml (default: APP_ENV, then app.env).
+	Env string
+	// Dir is the directory of config.yaml, config.<env>.yaml and .env (default: the working directory).
+	Dir string
+	// SecretsFile is the secrets overlay (default: DefaultSecretsFile).
+	SecretsFile string
+	// Resolvers resolve the references of their scheme in the values, e.g. ${aws-sm:prod/db#dsn}
+	// (the file and env schemes are always resolved).
+	Resolvers []SecretResolver
+	// KeepUnresolved keeps the references of the schemes without resolver instead of
+	// failing, e.g. to print the configuration without the resolvers of the app.
+	KeepUnresolved bool
+	// EnvPrefix is the prefix of the environment variables, e.g. LIQUOR for LIQUOR_APP_NAME
+	// (default: app.envPrefix of the files, or no prefix).
+	EnvPrefix string
+	// Values replace the files and the environment: the configuration is built from these
+	// nested (or dotted) values only, taken as is, e.g. in the tests (see NewFromMap).
+	Values map[string]interface{}
+}
+
+// Source is a source of the configuration.
+type Source struct {
+	// Kind is the layer of the source: base, profile, file, secrets, dotenv or env.
+	Kind string
+	// Path is the file of the source (empty for the environment).
+	Path string
+}
+
+func (s Source) String() string {
+	if s.Path == "" {
+		return s.Kind
+	}
+	return s.Kind + ":" + s.Path
+}
+
+// Load reads the configuration from its layers. Each layer overrides the previous ones:
+//
+//  1. base: config.yaml
+//  2. profile: config.<env>.yaml, where env is APP_ENV (or app.env of the base file)
+//  3. file: the explicit files (`--config`), in order
+//  4. secrets: the secrets overlay (/etc/secrets/config.yaml)
+//  5. dotenv: the .env file, loaded in the environment without replacing the variables already set
+//  6. env: the environment variables (APP_NAME for app.name, see below)
+//
+// The variable of a key is the key in upper case with underscores for the dots, after the
+// prefix when one is set: server.http.port is SERVER_HTTP_PORT, or LIQUOR_SERVER_HTTP_PORT
+// with the LIQUOR prefix. Slices take comma-separated values or JSON arrays, and maps JSON
+// objects. Without prefix, only the keys of the files are overridden; with a prefix, every
+// prefixed variable sets its key, so the keys missing from the files can be set too.
+// APP_ENV always selects the profile.
+//
+// The references to secrets in the values (${file:/run/secrets/db}, ${env:DB_PASSWORD},
+// or the schemes of opts.Resolvers) are then resolved. The overrides (`--set`) apply last.
+// Missing layers are skipped, but at least one file is needed.
+//
+// Parameters:
+// - opts: The sources.
+//
+// Returns:
+// - The configuration.
+// - An error if no file is found or a file is invalid.
+func Load(opts LoadOptions) (*Config, error) {
+	secrets := newSecretStore(opts.Resolvers, opts.KeepUnresolved)
+	if opts.Values != nil {
+		// the values are taken as is: the references are kept
+		secrets = &secretStore{resolvers: map[string]SecretResolver{}, keepUnknown: true, cache: map[string]string{}}
+	}
+	return load(opts, nil, secrets)
+}
+
+// load reads the layers of Load, with the in-memory layers (see SetLayer) over the secrets.
+func load(opts LoadOptions, layers []layer, secrets *secretStore) (*Config, error) {
+	if opts.SecretsFile == "" {
+		opts.SecretsFile = DefaultSecretsFile
+	}
+	vp := viper.New()
+	cfg := &Config{stg: vp, opts: opts, layers: layers, secrets: secrets, keySources: map[string]Source{}}
+	if opts.Values != nil {
+		memory := layer{source: Source{Kind: "memory"}, values: nestKeys(opts.Values)}
+		if err := cfg.mergeLayers(append([]layer{memory}, layers...)); err != nil {
+			return nil, err
+		}
+		return cfg, secrets.resolve(cfg)
+	}
+
+	merge := func(kind, path string) error {
+		vp.SetConfigFile(path)
+		if err := vp.MergeInConfig(); err != nil {
+			return fmt.Errorf("failed to read config file %s: %w", path, err)
+		}
+		source := Source{Kind: kind, Path: path}
+		cfg.sources = append(cfg.sources, source)
+		for _, key := range fileKeys(path) {
+			cfg.keySources[key] = source
+		}
+		return nil
+	}
+
+	// the .env file is loaded first, so it can select the profile with APP_ENV
+	dotenv := filepath.Join(opts.Dir, ".env")
+	hasDotEnv := exists(dotenv)
+	if hasDotEnv {
+		if err := loadDotEnv(dotenv); err != nil {
+			return nil, err
+		}
+	}
+
+	tried := []string{}
+	base := filepath.Join(opts.Dir, "config.yaml")
+	tried = append(tried, base)
+	if exists(base) {
+		if err := merge("base", base); err != nil {
+			return nil, err
+		}
+	}
+
+	env := opts.Env
+	if env == "" {
+		env = os.Getenv("APP_ENV")
+	}
+	if env == "" {
+		env = vp.GetString("app.env")
+	}
+	if env != "" {
+		profile := filepath.Join(opts.Dir, "config."+env+".yaml")
+		tried = append(tried, profile)
+		if exists(profile) {
+			if err := merge("profile", profile); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for _, file := range opts.Files {
+		if !exists(file) {
+			return nil, fmt.Errorf("config file %s not found", file)
+		}
+		if err := merge("file", file); err != nil {
+			return nil, err
+		}
+	}
+
+	tried = append(tried, opts.SecretsFile)
+	if exists(opts.SecretsFile) {
+		if err := merge("secrets", opts.SecretsFile); err != nil {
+			return nil, err
+		}
+	}
+	cfg.paths = append(tried, opts.Files...)
+
+	if err := cfg.mergeLayers(layers); err != nil {
+		return nil, err
+	}
+
+	if len(cfg.sources) == 0 {
+		return nil, fmt.Errorf("config file not found try: %v", tried)
+	}
+
+	if hasDotEnv {
+		cfg.sources = append(cfg.sources, Source{Kind: "dotenv", Path: dotenv})
+	}
+
+	prefix := opts.EnvPrefix
+	if prefix == "" {
+		prefix = vp.GetString("app.envPrefix")
+	}
+	// read by Load itself
+	cfg.reads.Store("app.envprefix", struct{}{})
+	cfg.reads.Store("app.env", struct{}{})
+	cfg.envPrefix = normalizeEnvPrefix(prefix)
+	vp.SetEnvPrefix(cfg.envPrefix)
+	vp.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	vp.AutomaticEnv()
+	cfg.envOverrides = applyEnv(vp, cfg.envPrefix)
+	dotenvVars := map[string]bool{}
+	if hasDotEnv {
+		dotenvVars = dotEnvVars(dotenv)
+	}
+	for key, name := range cfg.envOverrides {
+		if dotenvVars[name] {
+			cfg.keySources[key] = Source{Kind: "dotenv", Path: name}
+		} else {
+			cfg.keySources[key] = Source{Kind: "env", Path: name}
+		}
+	}
+	if err := vp.BindEnv("app.env", envName(cfg.envPrefix, "app.env"), "APP_ENV"); err != nil {
+		return nil, err
+	}
+	if opts.Env != "" {
+		vp.Set("app.env", opts.Env)
+	}
+	cfg.sources = append(cfg.sources, Source{Kind: "env"})
+	if err := secrets.resolve(cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// mergeLayers merges in-memory layers over the settings.
+func (c *Config) mergeLayers(layers []layer) error {
+	for _, l := range layers {
+		if err := c.stg.MergeConfigMap(l.values); err != nil {
+			return fmt.Errorf("failed to merge %s: %w", l.source, err)
+		}
+		c.sources = append(c.sources, l.source)
+		flat := map[string]interface{}{}
+		flatten("", l.values, flat)
+		for key := range flat {
+			c.keySources[strings.ToLower(key)] = l.source
+		}
+	}
+	return nil
+}
+
+// nestKeys returns values with the dotted keys ("server.http.port") as nested maps.
+func nestKeys(values map[string]interface{}) map[string]interface{} {
+	flat := map[string]interface{}{}
+	flatten("", values, flat)
+	nested := map[string]interface{}{}
+	for _, key := range sortedKeys(flat) {
+		setPath(nested, key, ".", flat[key])
+	}
+	return nested
+}
+
+// Sources returns the sources of the configuration, lowest precedence first.
+//
+// Returns:
+// - The sources read by Load.
+func (c *Config) Sources() []Source {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return append([]Source(nil), c.sources...)
+}
+
+// fileKeys returns the keys of a config file.
+func fileKeys(path string) []string {
+	vp := viper.New()
+	vp.SetConfigFile(path)
+	if err := vp.ReadInConfig(); err != nil {
+		return nil
+	}
+	return vp.AllKeys()
+}
+
+// dotEnvVars returns the variables of the environment set by a .env file.
+func dotEnvVars(path string) map[string]bool {
+	vars := map[string]bool{}
+	values, err := gotenv.Read(path)
+	if err != nil {
+		return vars
+	}
+	for k, v := range values {
+		if os.Getenv(k) == v {
+			vars[k] = true
+		}
+	}
+	return vars
+}
+
+// loadDotEnv sets the variables of a .env file that are not set yet.
+func loadDotEnv(path string) error {
+	values, err := gotenv.Read(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	var errs []error
+	for _, k := range sortedKeys(values) {
+		if _, set := os.LookupEnv(k); set {
+			continue
+		}
+		if err := os.Setenv(k, values[k]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -1,39 +1,111 @@
 package config
 
 import (
-	"fmt"
-	"os"
+	"context"
+	"time"
 
-	"github.com/spf13/viper"
 	"go.uber.org/fx"
 )
 
-// ConfigModule enable the config (is required)
+// ConfigModule enable the config (is required). The remote providers (see
+// RegisterProviders) are merged over the files. With `config.watch: true`, the
+// configuration is reloaded when its files change (see Config.WatchFiles), and with
+// `config.secrets.refresh: <interval>` the secrets are resolved again periodically.
 var ConfigModule = fx.Module("liquor-config", fx.Provide(
 	readConfigFile,
-))
+),
+	fx.Invoke(startProviders, watchConfigFiles, refreshSecrets),
+)
 
-func readConfigFile() (*Config, error) {
-	vp := viper.New()
-	files := []string{
-		"config.yaml",
-		"/etc/secrets/config.yaml",
-	}
-	var file string
-	for _, f := range files {
-		if _, err := os.Stat(f); !os.IsNotExist(err) {
-			file = f
-			break
-		}
+// RegisterResolvers register the constructors of your secret resolvers (see SecretResolver).
+// The configuration is loaded with them, so they can't depend on the *Config: they
+// receive it in Resolve instead.
+//
+// Example:
+//
+//	app.NewApp(
+//	    config.RegisterResolvers(NewVaultResolver), // ${vault:secret/data/db#password}
+//	)
+func RegisterResolvers(resolvers ...any) fx.Option {
+	annotated := make([]any, len(resolvers))
+	for i, r := range resolvers {
+		annotated[i] = fx.Annotate(r, fx.As(new(SecretResolver)), fx.ResultTags(`group:"liquor-config-resolvers"`))
 	}
-	if file == "" {
-		return nil, fmt.Errorf("config file not found try: [%v]", files)
+	return fx.Module("liquor-config-resolvers", fx.Provide(annotated...))
+}
+
+type configParams struct {
+	fx.In
+
+	Options   LoadOptions      `optional:"true"`
+	Resolvers []SecretResolver `group:"liquor-config-resolvers"`
+}
+
+// readConfigFile loads the layered configuration (see Load); the app supplies the
+// LoadOptions of the `--config` flag.
+func readConfigFile(p configParams) (*Config, error) {
+	opts := p.Options
+	opts.Resolvers = append(append([]SecretResolver(nil), opts.Resolvers...), p.Resolvers...)
+	return Load(opts)
+}
+
+// refreshSecrets resolves the secrets again every `config.secrets.refresh` while the app runs.
+func refreshSecrets(cfg *Config, lc fx.Lifecycle) {
+	interval := cfg.GetDuration("config.secrets.refresh")
+	if interval <= 0 {
+		return
 	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			go func() {
+				defer close(done)
+				ticker := time.NewTicker(interval)
+				defer ticker.Stop()
+				for {
+					select {
+					case <-ctx.Done():
+						return
+					case <-ticker.C:
+						// the failures are reported to the OnReload callbacks
+						_ = cfg.RefreshSecrets()
+					}
+				}
+			}()
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			cancel()
+			<-done
+			return nil
+		},
+	})
+}
 
-	vp.AutomaticEnv()
-	vp.SetConfigFile(file)
-	if err := vp.ReadInConfig(); err != nil {
-		return nil, fmt.Errorf("failed to read config file: %w", err)
+// watchConfigFiles watches the config files while the app runs when `config.watch` is set.
+func watchConfigFiles(cfg *Config, lc fx.Lifecycle) {
+	if !cfg.GetBool("config.watch") {
+		return
 	}
-	return &Config{stg: vp}, nil
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			fw, files, err := cfg.watchFiles()
+			if err != nil {
+				return err
+			}
+			go func() {
+				defer close(done)
+				cfg.watchLoop(ctx, fw, files)
+			}()
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			cancel()
+			<-done
+			return nil
+		},
+	})
 }
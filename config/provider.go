@@ -0,0 +1,394 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/fx"
+	"gopkg.in/yaml.v3"
+)
+
+// Provider is a remote source of configuration, merged over the config files (see
+// RegisterProviders). The providers are polled every `config.providers.interval`.
+type Provider interface {
+	// Name identifies the source as <kind>:<path>, e.g. "consul:apps/payments".
+	Name() string
+	// Fetch returns the current values, as nested maps.
+	Fetch(ctx context.Context) (map[string]interface{}, error)
+}
+
+// WatchingProvider is a Provider notified of its changes (e.g. a Consul blocking query),
+// watched instead of polled.
+type WatchingProvider interface {
+	Provider
+	// Watch calls update with the new values until ctx is done. An error restarts the
+	// watch after `config.providers.interval`.
+	Watch(ctx context.Context, update func(values map[string]interface{})) error
+}
+
+// providerTimeout bounds each fetch of a provider, and the requests of the clients of the
+// built-in providers (see providerClient).
+const providerTimeout = 30 * time.Second
+
+// providerClient returns the default HTTP client of the built-in providers, whose requests
+// time out after timeout, so a store that stops answering doesn't block the refreshes.
+func providerClient(timeout time.Duration) *http.Client {
+	return &http.Client{Timeout: timeout}
+}
+
+// providerSource returns the source of the layer of a provider.
+func providerSource(p Provider) Source {
+	kind, path, ok := strings.Cut(p.Name(), ":")
+	if !ok {
+		return Source{Kind: "remote", Path: p.Name()}
+	}
+	return Source{Kind: kind, Path: path}
+}
+
+// unsafePath matches the characters replaced in the names of the cache files.
+var unsafePath = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// providerRunner merges the values of the providers and keeps the last known good ones:
+// a failed fetch or a rejected reload keeps the current layer, and the values are saved
+// in the cache directory for the restarts during an outage.
+type providerRunner struct {
+	config    *Config
+	providers []Provider
+	interval  time.Duration
+	cacheDir  string
+}
+
+// AddProvider fetches the values of a provider and merges them over the config files
+// (see SetLayer). When the provider fails, the values saved in `config.providers.cacheDir`
+// are used instead. The provider isn't refreshed: the providers registered with
+// RegisterProviders are.
+//
+// Parameters:
+// - ctx: Bounds the fetch.
+// - p: The provider.
+//
+// Returns:
+// - An error if the provider fails without saved values, or the values are rejected.
+func (c *Config) AddProvider(ctx context.Context, p Provider) error {
+	return c.providerRunner(nil).load(ctx, p)
+}
+
+func (c *Config) providerRunner(providers []Provider) *providerRunner {
+	interval := c.GetDuration("config.providers.interval")
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	return &providerRunner{
+		config:    c,
+		providers: providers,
+		interval:  interval,
+		cacheDir:  c.GetString("config.providers.cacheDir"),
+	}
+}
+
+func (r *providerRunner) load(ctx context.Context, p Provider) error {
+	values, err := p.Fetch(ctx)
+	if err != nil {
+		cached, cacheErr := r.cached(p)
+		if cacheErr != nil {
+			return fmt.Errorf("config: provider %s failed: %w", p.Name(), err)
+		}
+		r.config.notify(nil, fmt.Errorf("config: provider %s failed, using the saved values: %w", p.Name(), err))
+		return r.config.SetLayer(providerSource(p), cached)
+	}
+	return r.apply(p, values)
+}
+
+func (r *providerRunner) apply(p Provider, values map[string]interface{}) error {
+	if err := r.config.SetLayer(providerSource(p), values); err != nil {
+		return err
+	}
+	r.save(p, values)
+	return nil
+}
+
+// run refreshes a provider until ctx is done.
+func (r *providerRunner) run(ctx context.Context, p Provider) {
+	if w, ok := p.(WatchingProvider); ok {
+		for ctx.Err() == nil {
+			err := w.Watch(ctx, func(values map[string]interface{}) {
+				// the rejected values are reported to the OnReload callbacks
+				_ = r.apply(p, values)
+			})
+			if err != nil && ctx.Err() == nil {
+				r.config.notify(nil, fmt.Errorf("config: provider %s failed: %w", p.Name(), err))
+			}
+			r.sleep(ctx)
+		}
+		return
+	}
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			fetchCtx, cancel := context.WithTimeout(ctx, providerTimeout)
+			values, err := p.Fetch(fetchCtx)
+			cancel()
+			if err != nil {
+				if ctx.Err() == nil {
+					r.config.notify(nil, fmt.Errorf("config: provider %s failed: %w", p.Name(), err))
+				}
+				continue
+			}
+			_ = r.apply(p, values)
+		}
+	}
+}
+
+func (r *providerRunner) sleep(ctx context.Context) {
+	select {
+	case <-ctx.Done():
+	case <-time.After(r.interval):
+	}
+}
+
+func (r *providerRunner) cacheFile(p Provider) string {
+	return filepath.Join(r.cacheDir, unsafePath.ReplaceAllString(p.Name(), "_")+".json")
+}
+
+// save writes the values of a provider in the cache directory, if any.
+func (r *providerRunner) save(p Provider, values map[string]interface{}) {
+	if r.cacheDir == "" {
+		return
+	}
+	content, err := json.Marshal(values)
+	if err == nil {
+		err = os.MkdirAll(r.cacheDir, 0o700)
+	}
+	if err == nil {
+		tmp := r.cacheFile(p) + ".tmp"
+		if err = os.WriteFile(tmp, content, 0o600); err == nil {
+			err = os.Rename(tmp, r.cacheFile(p))
+		}
+	}
+	if err != nil {
+		r.config.notify(nil, fmt.Errorf("config: failed to save the values of %s: %w", p.Name(), err))
+	}
+}
+
+// cached reads the values of a provider saved in the cache directory.
+func (r *providerRunner) cached(p Provider) (map[string]interface{}, error) {
+	if r.cacheDir == "" {
+		return nil, errors.New("no cache directory")
+	}
+	content, err := os.ReadFile(r.cacheFile(p))
+	if err != nil {
+		return nil, err
+	}
+	values := map[string]interface{}{}
+	return values, json.Unmarshal(content, &values)
+}
+
+// RegisterProviders register the constructors of remote config providers (see Provider).
+// The providers are fetched when the config is loaded, before the other components are
+// created, in the order of their names, then refreshed while the app runs.
+//
+// Config keys:
+// - config.providers.interval: The polling interval (default 30s).
+// - config.providers.cacheDir: The directory saving the last known good values, used
+// when a provider fails on start (optional).
+//
+// Example:
+//
+//	app.NewApp(
+//	    config.ConsulProviderModule,
+//	    config.RegisterProviders(NewVaultProvider),
+//	)
+func RegisterProviders(providers ...any) fx.Option {
+	annotated := make([]any, len(providers))
+	for i, p := range providers {
+		annotated[i] = fx.Annotate(p, fx.As(new(Provider)), fx.ResultTags(`group:"liquor-config-providers"`))
+	}
+	return fx.Module("liquor-config-providers", fx.Provide(annotated...))
+}
+
+// ConsulProviderModule merges the Consul KV store (see NewConsulProvider).
+var ConsulProviderModule = RegisterProviders(NewConsulProvider)
+
+// EtcdProviderModule merges etcd (see NewEtcdProvider).
+var EtcdProviderModule = RegisterProviders(NewEtcdProvider)
+
+// HTTPProviderModule merges a remote document (see NewHTTPProvider).
+var HTTPProviderModule = RegisterProviders(NewHTTPProvider)
+
+type providersParams struct {
+	fx.In
+
+	Config    *Config
+	Lifecycle fx.Lifecycle
+	Providers []Provider `group:"liquor-config-providers"`
+}
+
+// startProviders merges the providers, then refreshes them while the app runs.
+func startProviders(p providersParams) error {
+	if len(p.Providers) == 0 {
+		return nil
+	}
+	providers := append([]Provider(nil), p.Providers...)
+	sort.Slice(providers, func(i, j int) bool { return providers[i].Name() < providers[j].Name() })
+	runner := p.Config.providerRunner(providers)
+
+	ctx, cancel := context.WithTimeout(context.Background(), providerTimeout)
+	defer cancel()
+	for _, provider := range providers {
+		if err := runner.load(ctx, provider); err != nil {
+			return err
+		}
+	}
+
+	runCtx, stop := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	p.Lifecycle.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			for _, provider := range providers {
+				wg.Add(1)
+				go func(provider Provider) {
+					defer wg.Done()
+					runner.run(runCtx, provider)
+				}(provider)
+			}
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			stop()
+			wg.Wait()
+			return nil
+		},
+	})
+	return nil
+}
+
+// StaticProvider is a Provider of fixed values, a stand-in of the remote providers in the
+// tests: Set simulates a change, and Fail an outage.
+type StaticProvider struct {
+	name string
+
+	mu      sync.Mutex
+	values  map[string]interface{}
+	err     error
+	changed chan struct{}
+}
+
+// NewStaticProvider creates a StaticProvider.
+//
+// Parameters:
+// - name: The name of the provider, e.g. "consul:apps/payments".
+// - values: The values, as nested maps.
+//
+// Returns:
+// - The provider.
+//
+// Example:
+//
+//	remote := config.NewStaticProvider("consul:apps/payments", map[string]interface{}{
+//	    "log": map[string]interface{}{"level": "debug"},
+//	})
+//	app.NewApp(config.RegisterProviders(func() *config.StaticProvider { return remote }))
+//	remote.Set(map[string]interface{}{"log": map[string]interface{}{"level": "warn"}})
+func NewStaticProvider(name string, values map[string]interface{}) *StaticProvider {
+	return &StaticProvider{name: name, values: values, changed: make(chan struct{}, 1)}
+}
+
+// Name implements Provider.
+func (p *StaticProvider) Name() string {
+	return p.name
+}
+
+// Fetch implements Provider.
+func (p *StaticProvider) Fetch(context.Context) (map[string]interface{}, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.err != nil {
+		return nil, p.err
+	}
+	return p.values, nil
+}
+
+// Watch implements WatchingProvider.
+func (p *StaticProvider) Watch(ctx context.Context, update func(values map[string]interface{})) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-p.changed:
+			if values, err := p.Fetch(ctx); err == nil {
+				update(values)
+			}
+		}
+	}
+}
+
+// Set replaces the values and notifies the watch.
+//
+// Parameters:
+// - values: The new values.
+func (p *StaticProvider) Set(values map[string]interface{}) {
+	p.mu.Lock()
+	p.values, p.err = values, nil
+	p.mu.Unlock()
+	select {
+	case p.changed <- struct{}{}:
+	default:
+	}
+}
+
+// Fail makes the next fetches fail with err, until Set.
+//
+// Parameters:
+// - err: The error.
+func (p *StaticProvider) Fail(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.err = err
+}
+
+// decodeDocument decodes a YAML or JSON document of values.
+func decodeDocument(content []byte) (map[string]interface{}, error) {
+	values := map[string]interface{}{}
+	if err := yaml.Unmarshal(content, &values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// setPath sets a value in nested maps, the path split by sep (e.g. "database/host").
+func setPath(values map[string]interface{}, path, sep string, value interface{}) {
+	parts := strings.Split(strings.Trim(path, sep), sep)
+	for _, part := range parts[:len(parts)-1] {
+		nested, ok := values[part].(map[string]interface{})
+		if !ok {
+			nested = map[string]interface{}{}
+			values[part] = nested
+		}
+		values = nested
+	}
+	values[parts[len(parts)-1]] = value
+}
+
+// statusError returns the error of an unexpected HTTP response, with the start of its body.
+func statusError(service string, res *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(res.Body, 512))
+	if msg := strings.TrimSpace(string(body)); msg != "" {
+		return fmt.Errorf("%s: %s: %s", service, res.Status, msg)
+	}
+	return fmt.Errorf("%s: %s", service, res.Status)
+}
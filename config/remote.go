@@ -0,0 +1,115 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// HTTPOptions configures an HTTPProvider.
+type HTTPOptions struct {
+	// URL is the address of the YAML or JSON document.
+	URL string
+	// Headers are added to the requests, e.g. Authorization.
+	Headers map[string]string
+	// Client is the HTTP client (default: a client timing out after 30s).
+	Client *http.Client
+}
+
+// HTTPProvider is a polled Provider of a YAML or JSON document served over HTTP. The
+// document is downloaded again only when its ETag changes.
+type HTTPProvider struct {
+	opts HTTPOptions
+
+	mu     sync.Mutex
+	etag   string
+	values map[string]interface{}
+}
+
+// NewHTTPProvider creates the provider of the remote document from the configuration.
+//
+// Config keys:
+// - config.remote.url: The address of the document (required).
+// - config.remote.headers: The headers of the requests (optional).
+//
+// Parameters:
+// - cfg: The configuration.
+//
+// Returns:
+// - The provider, or an error if the URL is missing.
+func NewHTTPProvider(cfg *Config) (*HTTPProvider, error) {
+	headers := map[string]string{}
+	for name, value := range cfg.GetStringMap("config.remote.headers") {
+		headers[name] = fmt.Sprint(value)
+	}
+	return NewHTTPProviderWith(HTTPOptions{URL: cfg.GetString("config.remote.url"), Headers: headers})
+}
+
+// NewHTTPProviderWith creates a provider of a remote document.
+//
+// Parameters:
+// - opts: The options.
+//
+// Returns:
+// - The provider, or an error if the URL is missing.
+func NewHTTPProviderWith(opts HTTPOptions) (*HTTPProvider, error) {
+	if opts.URL == "" {
+		return nil, fmt.Errorf("config: config.remote.url is required")
+	}
+	if opts.Client == nil {
+		opts.Client = providerClient(providerTimeout)
+	}
+	return &HTTPProvider{opts: opts}, nil
+}
+
+// Name implements Provider.
+func (p *HTTPProvider) Name() string {
+	return "http:" + strings.TrimPrefix(strings.TrimPrefix(p.opts.URL, "https://"), "http://")
+}
+
+// Fetch implements Provider.
+func (p *HTTPProvider) Fetch(ctx context.Context) (map[string]interface{}, error) {
+	// the lock isn't held during the request, so a slow server doesn't block the other
+	// fetches
+	p.mu.Lock()
+	etag, current := p.etag, p.values
+	p.mu.Unlock()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.opts.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	for name, value := range p.opts.Headers {
+		req.Header.Set(name, value)
+	}
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	res, err := p.opts.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	switch res.StatusCode {
+	case http.StatusNotModified:
+		return current, nil
+	case http.StatusOK:
+	default:
+		return nil, statusError("http", res)
+	}
+	content, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	values, err := decodeDocument(content)
+	if err != nil {
+		return nil, fmt.Errorf("invalid document %s: %w", p.opts.URL, err)
+	}
+	p.mu.Lock()
+	p.etag, p.values = res.Header.Get("ETag"), values
+	p.mu.Unlock()
+	return values, nil
+}
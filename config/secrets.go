@@ -0,0 +1,301 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Redacted replaces the secrets in the redacted outputs.
+const Redacted = "******"
+
+// resolveTimeout bounds the resolution of the references of a load.
+const resolveTimeout = 30 * time.Second
+
+// refPattern matches the references: ${<scheme>:<ref>}.
+var refPattern = regexp.MustCompile(`\$\{([a-z0-9-]+):([^}]+)\}`)
+
+// SecretResolver resolves the references of a scheme in the config values:
+// ${aws-sm:prod/db#dsn} is resolved by the resolver of the "aws-sm" scheme.
+type SecretResolver interface {
+	// Scheme returns the scheme of the references, e.g. "aws-sm".
+	Scheme() string
+	// Resolve returns the value of a reference (the part after the scheme). cfg is the
+	// configuration being loaded, with the file and env references resolved, to configure
+	// the resolver (e.g. the region of aws-sm).
+	Resolve(ctx context.Context, cfg *Config, ref string) (string, error)
+}
+
+// FileResolver resolves ${file:/run/secrets/db-password} to the content of the file,
+// without its trailing newline.
+type FileResolver struct{}
+
+// Scheme implements SecretResolver.
+func (FileResolver) Scheme() string {
+	return "file"
+}
+
+// Resolve implements SecretResolver.
+func (FileResolver) Resolve(_ context.Context, _ *Config, ref string) (string, error) {
+	content, err := os.ReadFile(ref)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(content), "\r\n"), nil
+}
+
+// EnvResolver resolves ${env:DB_PASSWORD} to the value of the environment variable.
+type EnvResolver struct{}
+
+// Scheme implements SecretResolver.
+func (EnvResolver) Scheme() string {
+	return "env"
+}
+
+// Resolve implements SecretResolver.
+func (EnvResolver) Resolve(_ context.Context, _ *Config, ref string) (string, error) {
+	value, ok := os.LookupEnv(ref)
+	if !ok {
+		return "", fmt.Errorf("variable %s is not set", ref)
+	}
+	return value, nil
+}
+
+// secretStore resolves the references with a cache, kept across the reloads until
+// the secrets are refreshed.
+type secretStore struct {
+	resolvers map[string]SecretResolver
+	// keepUnknown keeps the references without resolver (see LoadOptions.KeepUnresolved)
+	keepUnknown bool
+
+	mu    sync.Mutex
+	cache map[string]string
+}
+
+func newSecretStore(resolvers []SecretResolver, keepUnknown bool) *secretStore {
+	s := &secretStore{
+		resolvers:   map[string]SecretResolver{},
+		keepUnknown: keepUnknown,
+		cache:       map[string]string{},
+	}
+	for _, r := range append([]SecretResolver{FileResolver{}, EnvResolver{}}, resolvers...) {
+		s.resolvers[r.Scheme()] = r
+	}
+	return s
+}
+
+// sensitiveNames are the parts of the keys holding secrets even without reference
+// (e.g. database.password), redacted too. "dns" is the connection string of the database
+// modules (database.postgres.dns), with its password.
+var sensitiveNames = []string{"password", "passwd", "secret", "token", "apikey", "accesskey", "privatekey", "dsn", "dns", "credential", "authorization"}
+
+// sensitive reports whether the name of a key suggests a secret.
+func sensitive(key string) bool {
+	name := strings.ToLower(key[strings.LastIndex(key, ".")+1:])
+	for _, s := range sensitiveNames {
+		if strings.Contains(name, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// builtin reports whether a scheme is resolved in the first pass, before the resolvers
+// reading the configuration.
+func builtin(scheme string) bool {
+	return scheme == "file" || scheme == "env"
+}
+
+// resolve replaces the references of the config values, the file and env references
+// first, and records the keys holding secrets and their values: the resolved keys and
+// the keys named like secrets (see sensitive).
+func (s *secretStore) resolve(cfg *Config) error {
+	ctx, cancel := context.WithTimeout(context.Background(), resolveTimeout)
+	defer cancel()
+
+	cfg.secretKeys = map[string]bool{}
+	cfg.secretValues = nil
+	var errs []error
+	for _, first := range []bool{true, false} {
+		for _, key := range cfg.stg.AllKeys() {
+			value, changed, err := s.resolveValue(ctx, cfg, cfg.stg.Get(key), first)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", key, err))
+				continue
+			}
+			if changed {
+				cfg.stg.Set(key, value)
+				cfg.secretKeys[key] = true
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("config: failed to resolve the secrets: %w", errors.Join(errs...))
+	}
+	for _, key := range cfg.stg.AllKeys() {
+		if !sensitive(key) {
+			continue
+		}
+		cfg.secretKeys[key] = true
+		if value, ok := cfg.stg.Get(key).(string); ok && value != "" {
+			cfg.secretValues = append(cfg.secretValues, value)
+		}
+	}
+	// the longest first, so a secret containing another one is redacted whole
+	sort.Slice(cfg.secretValues, func(i, j int) bool {
+		return len(cfg.secretValues[i]) > len(cfg.secretValues[j])
+	})
+	return nil
+}
+
+func (s *secretStore) resolveValue(ctx context.Context, cfg *Config, value interface{}, first bool) (interface{}, bool, error) {
+	switch v := value.(type) {
+	case string:
+		return s.resolveString(ctx, cfg, v, first)
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		changed := false
+		for i, item := range v {
+			resolved, itemChanged, err := s.resolveValue(ctx, cfg, item, first)
+			if err != nil {
+				return nil, false, err
+			}
+			out[i], changed = resolved, changed || itemChanged
+		}
+		return out, changed, nil
+	}
+	return value, false, nil
+}
+
+func (s *secretStore) resolveString(ctx context.Context, cfg *Config, value string, first bool) (string, bool, error) {
+	if !strings.Contains(value, "${") {
+		return value, false, nil
+	}
+	var errs []error
+	changed := false
+	resolved := refPattern.ReplaceAllStringFunc(value, func(match string) string {
+		parts := refPattern.FindStringSubmatch(match)
+		scheme, ref := parts[1], parts[2]
+		if builtin(scheme) != first {
+			return match
+		}
+		if _, ok := s.resolvers[scheme]; !ok && s.keepUnknown {
+			return match
+		}
+		secret, err := s.lookup(ctx, cfg, scheme, ref)
+		if err != nil {
+			errs = append(errs, err)
+			return match
+		}
+		changed = true
+		cfg.secretValues = append(cfg.secretValues, secret)
+		return secret
+	})
+	return resolved, changed, errors.Join(errs...)
+}
+
+func (s *secretStore) lookup(ctx context.Context, cfg *Config, scheme, ref string) (string, error) {
+	id := scheme + ":" + ref
+	s.mu.Lock()
+	cached, ok := s.cache[id]
+	s.mu.Unlock()
+	if ok {
+		return cached, nil
+	}
+	resolver, ok := s.resolvers[scheme]
+	if !ok {
+		return "", fmt.Errorf("no resolver for ${%s:...}", scheme)
+	}
+	value, err := resolver.Resolve(ctx, cfg, ref)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve ${%s:%s}: %w", scheme, ref, err)
+	}
+	s.mu.Lock()
+	s.cache[id] = value
+	s.mu.Unlock()
+	return value, nil
+}
+
+func (s *secretStore) clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cache = map[string]string{}
+}
+
+// RefreshSecrets resolves the references again, bypassing the cache, then reloads the
+// configuration, so the subscriptions of the keys see the rotated secrets (see Reload).
+//
+// Returns:
+// - An error if a reference can't be resolved or the reload is rejected.
+func (c *Config) RefreshSecrets() error {
+	c.secrets.clear()
+	return c.Reload()
+}
+
+// IsSecret reports whether a key holds a secret: its value was resolved from a reference,
+// or its name suggests a secret (password, token, dsn...).
+//
+// Parameters:
+// - key: The key.
+//
+// Returns:
+// - true if the key holds a secret.
+func (c *Config) IsSecret(key string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.secretKeys[strings.ToLower(key)]
+}
+
+// Redact replaces the secrets of the configuration found in a text, e.g. a log message.
+//
+// Parameters:
+// - text: The text.
+//
+// Returns:
+// - The text with the secrets replaced by Redacted.
+func (c *Config) Redact(text string) string {
+	c.mu.RLock()
+	values := c.secretValues
+	c.mu.RUnlock()
+	for _, secret := range values {
+		// the short values would redact unrelated text
+		if len(secret) >= 4 {
+			text = strings.ReplaceAll(text, secret, Redacted)
+		}
+	}
+	return text
+}
+
+// RedactedSettings returns every configuration value as a nested map, with the secrets
+// replaced by Redacted.
+//
+// Returns:
+// - The configuration values, safe to print.
+func (c *Config) RedactedSettings() map[string]interface{} {
+	c.mu.RLock()
+	settings := c.stg.AllSettings()
+	keys := c.secretKeys
+	c.mu.RUnlock()
+	for key := range keys {
+		path := strings.Split(key, ".")
+		values := settings
+		for _, segment := range path[:len(path)-1] {
+			nested, ok := values[segment].(map[string]interface{})
+			if !ok {
+				values = nil
+				break
+			}
+			values = nested
+		}
+		if values != nil {
+			values[path[len(path)-1]] = Redacted
+		}
+	}
+	return settings
+}
@@ -0,0 +1,111 @@
+package config
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/viper"
+	"go.uber.org/fx"
+)
+
+// NewFromMap creates a configuration from values, without reading the config files or
+// the environment, e.g. in the tests. The keys are nested maps or dotted keys, and the
+// values are taken as is (the references aren't resolved). The subscriptions, layers and
+// overrides work as on a loaded configuration.
+//
+// Parameters:
+// - values: The values.
+//
+// Returns:
+// - The configuration.
+//
+// Example:
+//
+//	cfg := config.NewFromMap(map[string]interface{}{
+//	    "app.name": "payments",
+//	    "database": map[string]interface{}{"driver": "sqlite", "dsn": "file::memory:"},
+//	})
+func NewFromMap(values map[string]interface{}) *Config {
+	if values == nil {
+		values = map[string]interface{}{}
+	}
+	cfg, err := Load(LoadOptions{Values: values})
+	if err != nil {
+		// unreachable: the values are merged as is
+		panic(err)
+	}
+	return cfg
+}
+
+// NewFromReader creates a configuration from a document, without reading the config files
+// or the environment (see NewFromMap).
+//
+// Parameters:
+// - format: The format of the document: yaml, json, toml, dotenv...
+// - r: The document.
+//
+// Returns:
+// - The configuration.
+// - An error if the document is invalid.
+//
+// Example:
+//
+//	cfg, err := config.NewFromReader("yaml", strings.NewReader(`
+//	app:
+//	  name: payments
+//	`))
+func NewFromReader(format string, r io.Reader) (*Config, error) {
+	vp := viper.New()
+	vp.SetConfigType(format)
+	if err := vp.ReadConfig(r); err != nil {
+		return nil, fmt.Errorf("config: failed to read the %s document: %w", format, err)
+	}
+	return NewFromMap(vp.AllSettings()), nil
+}
+
+// TestModule replaces ConfigModule in the tests of a module: the *Config is built from
+// values (see NewFromMap) and the registered providers and resolvers work as in the app.
+// With app.New, use app.WithConfigValues instead.
+//
+// Parameters:
+// - values: The values, nested maps or dotted keys.
+//
+// Example:
+//
+//	app := fxtest.New(t,
+//	    config.TestModule(map[string]interface{}{"redis.addr": mr.Addr()}),
+//	    redis.RedisModule,
+//	    fx.Populate(&client),
+//	)
+func TestModule(values map[string]interface{}) fx.Option {
+	if values == nil {
+		values = map[string]interface{}{}
+	}
+	return fx.Module("liquor-config-test",
+		fx.Supply(fx.Private, LoadOptions{Values: values}),
+		ConfigModule,
+	)
+}
+
+// Override sets keys of the configuration provided in the app (by ConfigModule or
+// TestModule), e.g. to change a value in a single test. The overrides are kept across the
+// reloads (see Config.Set). Given to fx.New (or app.New), it applies to every module.
+//
+// Parameters:
+// - values: The values, by dotted key.
+//
+// Example:
+//
+//	app := fxtest.New(t,
+//	    config.TestModule(defaults),
+//	    config.Override(map[string]interface{}{"outbox.batchSize": 1}),
+//	    outbox.RelayModule,
+//	)
+func Override(values map[string]interface{}) fx.Option {
+	return fx.Decorate(func(cfg *Config) *Config {
+		for _, key := range sortedKeys(values) {
+			cfg.Set(key, values[key])
+		}
+		return cfg
+	})
+}
@@ -0,0 +1,343 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// watchDebounce groups the file events of a single update (editors and ConfigMap
+// updates write several times).
+const watchDebounce = 200 * time.Millisecond
+
+// layer is an in-memory source merged over the secrets (see SetLayer).
+type layer struct {
+	source Source
+	values map[string]interface{}
+}
+
+// watcher is a subscription to the changes of the keys under prefix: prepare checks the
+// next configuration and returns the function applying it.
+type watcher struct {
+	prefix  string
+	prepare func(next *Config) (apply func(), err error)
+}
+
+// OnChange calls fn after each reload changing the keys under prefix.
+//
+// Parameters:
+// - prefix: The section (e.g. "server.http.cors"), or "" for every key.
+// - fn: The callback, reading the new values from the configuration.
+func (c *Config) OnChange(prefix string, fn func(cfg *Config)) {
+	c.watch(prefix, func(*Config) (func(), error) {
+		return func() { fn(c) }, nil
+	})
+}
+
+// Validate checks the reloads changing the keys under prefix: an error rejects the whole
+// reload, and the current configuration is kept.
+//
+// Parameters:
+// - prefix: The section, or "" for every key.
+// - check: The check of the next configuration.
+func (c *Config) Validate(prefix string, check func(next *Config) error) {
+	c.watch(prefix, func(next *Config) (func(), error) {
+		return nil, check(next)
+	})
+}
+
+// Watch calls apply with the section bound to T (see Bind) after each reload changing it.
+// An invalid section rejects the whole reload, so apply only receives valid values.
+//
+// Parameters:
+// - c: The configuration.
+// - prefix: The section.
+// - apply: The callback receiving the new section.
+//
+// Example:
+//
+//	type RateLimit struct {
+//	    Requests int `default:"100" validate:"min=1"`
+//	}
+//
+//	config.Watch(cfg, "ratelimit", func(rl RateLimit) {
+//	    limiter.SetLimit(rl.Requests)
+//	})
+func Watch[T any](c *Config, prefix string, apply func(T)) {
+	c.watch(prefix, func(next *Config) (func(), error) {
+		value, err := Bind[T](next, prefix)
+		if err != nil {
+			return nil, err
+		}
+		return func() { apply(value) }, nil
+	})
+}
+
+// OnReload calls fn after each reload, with the changed keys or the error that rejected it.
+//
+// Parameters:
+// - fn: The callback.
+func (c *Config) OnReload(fn func(changed []string, err error)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.listeners = append(c.listeners, fn)
+}
+
+func (c *Config) watch(prefix string, prepare func(next *Config) (func(), error)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.watchers = append(c.watchers, &watcher{prefix: strings.ToLower(prefix), prepare: prepare})
+}
+
+// Reload reads the sources of the configuration again and applies the changes atomically:
+// the subscriptions of the changed keys check the next configuration first, and a single
+// failure keeps the current one. The overrides (Set) are kept.
+//
+// Returns:
+// - An error if a source is invalid or a subscription rejected the changes.
+func (c *Config) Reload() error {
+	c.reloadMu.Lock()
+	defer c.reloadMu.Unlock()
+	c.mu.RLock()
+	layers := c.layers
+	c.mu.RUnlock()
+	return c.reload(layers)
+}
+
+// SetLayer merges values over the config files (after the secrets), replacing the layer
+// of the same source, then reloads the configuration (see Reload). The layer is
+// discarded when the reload is rejected.
+//
+// Parameters:
+// - source: The source of the values (e.g. Source{Kind: "configmap", Path: "default/app"}).
+// - values: The nested values.
+//
+// Returns:
+// - An error if the reload is rejected.
+func (c *Config) SetLayer(source Source, values map[string]interface{}) error {
+	c.reloadMu.Lock()
+	defer c.reloadMu.Unlock()
+	c.mu.RLock()
+	layers := make([]layer, 0, len(c.layers)+1)
+	replaced := false
+	for _, l := range c.layers {
+		if l.source == source {
+			l.values, replaced = values, true
+		}
+		layers = append(layers, l)
+	}
+	c.mu.RUnlock()
+	if !replaced {
+		layers = append(layers, layer{source: source, values: values})
+	}
+	return c.reload(layers)
+}
+
+func (c *Config) reload(layers []layer) error {
+	c.mu.RLock()
+	opts := c.opts
+	overrides := make(map[string]interface{}, len(c.overrides))
+	for k, v := range c.overrides {
+		overrides[k] = v
+	}
+	watchers := append([]*watcher(nil), c.watchers...)
+	current := c.stg.AllSettings()
+	c.mu.RUnlock()
+
+	next, err := load(opts, layers, c.secrets)
+	if err != nil {
+		err = fmt.Errorf("config: reload failed: %w", err)
+		c.notify(nil, err)
+		return err
+	}
+	for k, v := range overrides {
+		next.stg.Set(k, v)
+	}
+	next.overrides = overrides
+
+	changed := changedKeys(current, next.stg.AllSettings())
+	var applies []func()
+	var errs []error
+	for _, w := range watchers {
+		if !affects(w.prefix, changed) {
+			continue
+		}
+		apply, err := w.prepare(next)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if apply != nil {
+			applies = append(applies, apply)
+		}
+	}
+	if len(errs) > 0 {
+		err := fmt.Errorf("config: reload rejected: %w", errors.Join(errs...))
+		c.notify(changed, err)
+		return err
+	}
+
+	c.mu.Lock()
+	c.stg = next.stg
+	c.sources = next.sources
+	c.envPrefix = next.envPrefix
+	c.envOverrides = next.envOverrides
+	c.secretKeys = next.secretKeys
+	c.secretValues = next.secretValues
+	c.keySources = next.keySources
+	c.paths = next.paths
+	c.layers = layers
+	c.mu.Unlock()
+
+	if len(changed) == 0 {
+		return nil
+	}
+	for _, apply := range applies {
+		apply()
+	}
+	c.notify(changed, nil)
+	return nil
+}
+
+func (c *Config) notify(changed []string, err error) {
+	c.mu.RLock()
+	listeners := append([]func([]string, error){}, c.listeners...)
+	c.mu.RUnlock()
+	for _, fn := range listeners {
+		fn(changed, err)
+	}
+}
+
+// WatchFiles reloads the configuration when its files change, until ctx is done.
+// The directories of the files are watched, so the files created later (a new profile)
+// and the Kubernetes ConfigMap volumes (updated by swapping a symlink) are seen too.
+// The errors are reported to the OnReload callbacks.
+//
+// Parameters:
+// - ctx: Stops the watch when done.
+//
+// Returns:
+// - An error if the watch can't start.
+func (c *Config) WatchFiles(ctx context.Context) error {
+	fw, files, err := c.watchFiles()
+	if err != nil {
+		return err
+	}
+	c.watchLoop(ctx, fw, files)
+	return nil
+}
+
+// watchFiles starts the watch of the directories of the config files.
+func (c *Config) watchFiles() (*fsnotify.Watcher, map[string]bool, error) {
+	fw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, nil, fmt.Errorf("config: failed to watch the config files: %w", err)
+	}
+
+	c.mu.RLock()
+	paths := append([]string(nil), c.paths...)
+	c.mu.RUnlock()
+	files := map[string]bool{}
+	dirs := map[string]bool{}
+	for _, path := range paths {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			continue
+		}
+		files[abs] = true
+		dir := filepath.Dir(abs)
+		if dirs[dir] || !exists(dir) {
+			continue
+		}
+		if err := fw.Add(dir); err != nil {
+			fw.Close()
+			return nil, nil, fmt.Errorf("config: failed to watch %s: %w", dir, err)
+		}
+		dirs[dir] = true
+	}
+	return fw, files, nil
+}
+
+// watchLoop reloads the configuration on the events of the files until ctx is done.
+func (c *Config) watchLoop(ctx context.Context, fw *fsnotify.Watcher, files map[string]bool) {
+	defer fw.Close()
+
+	var debounce *time.Timer
+	var fire <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-fw.Events:
+			if !ok {
+				return
+			}
+			// ConfigMap volumes swap the ..data symlink on update
+			if !files[event.Name] && filepath.Base(event.Name) != "..data" {
+				continue
+			}
+			if debounce == nil {
+				debounce = time.NewTimer(watchDebounce)
+			} else {
+				debounce.Reset(watchDebounce)
+			}
+			fire = debounce.C
+		case err, ok := <-fw.Errors:
+			if !ok {
+				return
+			}
+			c.notify(nil, fmt.Errorf("config: watch failed: %w", err))
+		case <-fire:
+			fire = nil
+			_ = c.Reload()
+		}
+	}
+}
+
+// changedKeys returns the keys whose value differs between two settings.
+func changedKeys(before, after map[string]interface{}) []string {
+	flatBefore, flatAfter := map[string]interface{}{}, map[string]interface{}{}
+	flatten("", before, flatBefore)
+	flatten("", after, flatAfter)
+	var changed []string
+	for k, v := range flatAfter {
+		if old, ok := flatBefore[k]; !ok || !reflect.DeepEqual(old, v) {
+			changed = append(changed, k)
+		}
+	}
+	for k := range flatBefore {
+		if _, ok := flatAfter[k]; !ok {
+			changed = append(changed, k)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+func flatten(prefix string, values map[string]interface{}, out map[string]interface{}) {
+	for k, v := range values {
+		key := joinKey(prefix, k)
+		if nested, ok := v.(map[string]interface{}); ok && len(nested) > 0 {
+			flatten(key, nested, out)
+			continue
+		}
+		out[key] = v
+	}
+}
+
+// affects reports whether a change of keys concerns the section prefix.
+func affects(prefix string, changed []string) bool {
+	for _, key := range changed {
+		if prefix == "" || key == prefix || strings.HasPrefix(key, prefix+".") || strings.HasPrefix(prefix, key+".") {
+			return true
+		}
+	}
+	return false
+}
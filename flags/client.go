@@ -0,0 +1,292 @@
+package flags
+
+import (
+	"context"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+)
+
+// Attributes are attributes of the subject of the evaluations, e.g. "tenant", "plan".
+type Attributes map[string]interface{}
+
+// AttributesFunc returns the attributes of the subject from a context, e.g. the tenant of
+// the request (see RegisterAttributes).
+type AttributesFunc func(ctx context.Context) Attributes
+
+type attributesKey struct{}
+
+// WithAttributes returns a context carrying attributes of the subject, merged over the
+// attributes of ctx.
+//
+// Parameters:
+//   - ctx: The parent context
+//   - attributes: The attributes
+//
+// Returns:
+//   - context.Context: The context with the attributes
+func WithAttributes(ctx context.Context, attributes Attributes) context.Context {
+	merged := Attributes{}
+	if parent, ok := ctx.Value(attributesKey{}).(Attributes); ok {
+		for k, v := range parent {
+			merged[k] = v
+		}
+	}
+	for k, v := range attributes {
+		merged[k] = v
+	}
+	return context.WithValue(ctx, attributesKey{}, merged)
+}
+
+// WithTargetingKey returns a context identifying the subject (e.g. the user ID), hashed
+// by the percentage rollouts.
+//
+// Parameters:
+//   - ctx: The parent context
+//   - key: The identifier of the subject
+//
+// Returns:
+//   - context.Context: The context with the targeting key
+func WithTargetingKey(ctx context.Context, key string) context.Context {
+	return WithAttributes(ctx, Attributes{TargetingKey: key})
+}
+
+// Client evaluates the flags of a Provider with the attributes of the context.
+type Client struct {
+	provider   Provider
+	attributes []AttributesFunc
+	logger     *zap.Logger
+}
+
+// NewClient creates a client.
+//
+// Parameters:
+//   - provider: The provider of the flags
+//   - logger: Logs the failed evaluations
+//   - attributes: Read attributes of the subject from the contexts (see RegisterAttributes)
+//
+// Returns:
+//   - *Client: The client
+func NewClient(provider Provider, logger *zap.Logger, attributes ...AttributesFunc) *Client {
+	return &Client{provider: provider, attributes: attributes, logger: logger}
+}
+
+// Provider returns the provider of the flags.
+//
+// Returns:
+//   - Provider: The provider
+func (c *Client) Provider() Provider {
+	return c.provider
+}
+
+// EvaluationContext returns the attributes of the subject of a context: the attributes of
+// the registered functions, then the attributes of the context.
+//
+// Parameters:
+//   - ctx: Context
+//
+// Returns:
+//   - FlattenedContext: The attributes
+func (c *Client) EvaluationContext(ctx context.Context) FlattenedContext {
+	evalCtx := FlattenedContext{}
+	for _, fn := range c.attributes {
+		for k, v := range fn(ctx) {
+			evalCtx[k] = v
+		}
+	}
+	if attributes, ok := ctx.Value(attributesKey{}).(Attributes); ok {
+		for k, v := range attributes {
+			evalCtx[k] = v
+		}
+	}
+	return evalCtx
+}
+
+func (c *Client) report(flag string, detail ProviderResolutionDetail) {
+	if detail.ResolutionError == nil {
+		return
+	}
+	log := c.logger.Warn
+	if detail.ResolutionError.Code == FlagNotFoundCode {
+		log = c.logger.Debug
+	}
+	log("failed to evaluate the flag", zap.String("flag", flag), zap.Error(detail.ResolutionError))
+}
+
+// Enabled reports whether a boolean flag is on for the subject of ctx. An unknown flag
+// is off.
+//
+// Parameters:
+//   - ctx: Context, with the attributes of the subject
+//   - flag: The name of the flag
+//
+// Returns:
+//   - bool: true if the flag is on
+func (c *Client) Enabled(ctx context.Context, flag string) bool {
+	return c.BoolDetails(ctx, flag, false).Value
+}
+
+// BoolDetails evaluates a boolean flag.
+//
+// Parameters:
+//   - ctx: Context, with the attributes of the subject
+//   - flag: The name of the flag
+//   - defaultValue: The value when the flag can't be evaluated
+//
+// Returns:
+//   - BoolResolutionDetail: The value, with the reason
+func (c *Client) BoolDetails(ctx context.Context, flag string, defaultValue bool) BoolResolutionDetail {
+	detail := c.provider.BooleanEvaluation(ctx, flag, defaultValue, c.EvaluationContext(ctx))
+	c.report(flag, detail.ProviderResolutionDetail)
+	return detail
+}
+
+// String evaluates a string flag.
+//
+// Parameters:
+//   - ctx: Context, with the attributes of the subject
+//   - flag: The name of the flag
+//   - defaultValue: The value when the flag can't be evaluated
+//
+// Returns:
+//   - string: The value
+func (c *Client) String(ctx context.Context, flag string, defaultValue string) string {
+	detail := c.provider.StringEvaluation(ctx, flag, defaultValue, c.EvaluationContext(ctx))
+	c.report(flag, detail.ProviderResolutionDetail)
+	return detail.Value
+}
+
+// Int evaluates an integer flag.
+//
+// Parameters:
+//   - ctx: Context, with the attributes of the subject
+//   - flag: The name of the flag
+//   - defaultValue: The value when the flag can't be evaluated
+//
+// Returns:
+//   - int64: The value
+func (c *Client) Int(ctx context.Context, flag string, defaultValue int64) int64 {
+	detail := c.provider.IntEvaluation(ctx, flag, defaultValue, c.EvaluationContext(ctx))
+	c.report(flag, detail.ProviderResolutionDetail)
+	return detail.Value
+}
+
+// Float evaluates a float flag.
+//
+// Parameters:
+//   - ctx: Context, with the attributes of the subject
+//   - flag: The name of the flag
+//   - defaultValue: The value when the flag can't be evaluated
+//
+// Returns:
+//   - float64: The value
+func (c *Client) Float(ctx context.Context, flag string, defaultValue float64) float64 {
+	detail := c.provider.FloatEvaluation(ctx, flag, defaultValue, c.EvaluationContext(ctx))
+	c.report(flag, detail.ProviderResolutionDetail)
+	return detail.Value
+}
+
+// Object evaluates a flag of any value, e.g. a map.
+//
+// Parameters:
+//   - ctx: Context, with the attributes of the subject
+//   - flag: The name of the flag
+//   - defaultValue: The value when the flag can't be evaluated
+//
+// Returns:
+//   - interface{}: The value
+func (c *Client) Object(ctx context.Context, flag string, defaultValue interface{}) interface{} {
+	detail := c.provider.ObjectEvaluation(ctx, flag, defaultValue, c.EvaluationContext(ctx))
+	c.report(flag, detail.ProviderResolutionDetail)
+	return detail.Value
+}
+
+// defaultClient is the client of the package functions, set by FlagsModule.
+var defaultClient atomic.Pointer[Client]
+
+// SetDefault sets the client of the package functions (Enabled, String...). FlagsModule
+// sets it on startup.
+//
+// Parameters:
+//   - client: The client
+func SetDefault(client *Client) {
+	defaultClient.Store(client)
+}
+
+// Default returns the client of the package functions, nil before FlagsModule starts.
+//
+// Returns:
+//   - *Client: The client
+func Default() *Client {
+	return defaultClient.Load()
+}
+
+// Enabled reports whether a boolean flag is on for the subject of ctx, with the default
+// client. Every flag is off without FlagsModule.
+//
+// Parameters:
+//   - ctx: Context, with the attributes of the subject
+//   - flag: The name of the flag
+//
+// Returns:
+//   - bool: true if the flag is on
+//
+// Example:
+//
+//	if flags.Enabled(ctx, "new-checkout") {
+//	    return s.newCheckout(ctx, cart)
+//	}
+func Enabled(ctx context.Context, flag string) bool {
+	if c := Default(); c != nil {
+		return c.Enabled(ctx, flag)
+	}
+	return false
+}
+
+// String evaluates a string flag with the default client.
+//
+// Parameters:
+//   - ctx: Context, with the attributes of the subject
+//   - flag: The name of the flag
+//   - defaultValue: The value when the flag can't be evaluated
+//
+// Returns:
+//   - string: The value
+func String(ctx context.Context, flag string, defaultValue string) string {
+	if c := Default(); c != nil {
+		return c.String(ctx, flag, defaultValue)
+	}
+	return defaultValue
+}
+
+// Int evaluates an integer flag with the default client.
+//
+// Parameters:
+//   - ctx: Context, with the attributes of the subject
+//   - flag: The name of the flag
+//   - defaultValue: The value when the flag can't be evaluated
+//
+// Returns:
+//   - int64: The value
+func Int(ctx context.Context, flag string, defaultValue int64) int64 {
+	if c := Default(); c != nil {
+		return c.Int(ctx, flag, defaultValue)
+	}
+	return defaultValue
+}
+
+// Float evaluates a float flag with the default client.
+//
+// Parameters:
+//   - ctx: Context, with the attributes of the subject
+//   - flag: The name of the flag
+//   - defaultValue: The value when the flag can't be evaluated
+//
+// Returns:
+//   - float64: The value
+func Float(ctx context.Context, flag string, defaultValue float64) float64 {
+	if c := Default(); c != nil {
+		return c.Float(ctx, flag, defaultValue)
+	}
+	return defaultValue
+}
@@ -0,0 +1,411 @@
+package flags
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/go-liquor/liquor-sdk/config"
+	"github.com/mitchellh/mapstructure"
+	"github.com/spf13/cast"
+)
+
+// Flag is the definition of a flag in the `flags` section. A flag defined as a value
+// (`new-checkout: true`) serves it to everyone.
+type Flag struct {
+	// Enabled disables the flag when false: it serves Default (default true).
+	Enabled *bool `mapstructure:"enabled"`
+	// Value is the value served (default true).
+	Value interface{} `mapstructure:"value"`
+	// Default is the value when no rule matches (default false).
+	Default interface{} `mapstructure:"default"`
+	// Rollout is the percentage of the subjects served Value when no rule matches.
+	Rollout *float64 `mapstructure:"rollout"`
+	// Stickiness is the attribute hashed by the rollouts (default targetingKey).
+	Stickiness string `mapstructure:"stickiness"`
+	// Rules target the subjects by attribute; the first matching rule applies.
+	Rules []Rule `mapstructure:"rules"`
+}
+
+// Rule serves a value to the subjects whose attribute matches.
+type Rule struct {
+	// Attribute is the attribute of the subject, e.g. "tenant".
+	Attribute string `mapstructure:"attribute"`
+	// Operator compares the attribute with Values: eq, neq, in, notIn, startsWith,
+	// endsWith, contains, matches (regular expressions), gt, gte, lt or lte (numbers).
+	Operator string `mapstructure:"operator"`
+	// Values are compared with the attribute: the rule matches when one of them does
+	// (none of them for neq and notIn).
+	Values []string `mapstructure:"values"`
+	// Value is the value served (default: the Value of the flag).
+	Value interface{} `mapstructure:"value"`
+	// Rollout is the percentage of the matching subjects served Value, the others are
+	// served the Default of the flag.
+	Rollout *float64 `mapstructure:"rollout"`
+}
+
+type flag struct {
+	name       string
+	enabled    bool
+	value      interface{}
+	def        interface{}
+	rollout    *float64
+	stickiness string
+	rules      []rule
+}
+
+type rule struct {
+	Rule
+	patterns []*regexp.Regexp
+	numbers  []float64
+}
+
+// operators are the operators of the rules.
+var operators = map[string]bool{
+	"eq": true, "neq": true, "in": true, "notin": true, "startswith": true, "endswith": true,
+	"contains": true, "matches": true, "gt": true, "gte": true, "lt": true, "lte": true,
+}
+
+// parseFlags reads the flags of the `flags` section.
+func parseFlags(cfg *config.Config) (map[string]*flag, error) {
+	flags := map[string]*flag{}
+	var errs []error
+	for name, def := range cfg.GetStringMap("flags") {
+		f, err := parseFlag(strings.ToLower(name), def)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("flag %s: %w", name, err))
+			continue
+		}
+		flags[f.name] = f
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("flags: invalid flags: %w", errors.Join(errs...))
+	}
+	return flags, nil
+}
+
+func parseFlag(name string, raw interface{}) (*flag, error) {
+	values, ok := raw.(map[string]interface{})
+	if !ok {
+		return &flag{name: name, enabled: true, value: raw, def: false}, nil
+	}
+	var def Flag
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook:       mapstructure.StringToSliceHookFunc(","),
+		WeaklyTypedInput: true,
+		ErrorUnused:      true,
+		Result:           &def,
+		MatchName:        strings.EqualFold,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := decoder.Decode(values); err != nil {
+		return nil, err
+	}
+
+	f := &flag{name: name, enabled: true, value: def.Value, def: def.Default, rollout: def.Rollout, stickiness: def.Stickiness}
+	if def.Enabled != nil {
+		f.enabled = *def.Enabled
+	}
+	if f.value == nil {
+		f.value = true
+	}
+	if f.def == nil {
+		f.def = false
+	}
+	if f.stickiness == "" {
+		f.stickiness = TargetingKey
+	}
+	if err := checkRollout(def.Rollout); err != nil {
+		return nil, err
+	}
+	for i, r := range def.Rules {
+		parsed, err := parseRule(r, f.value)
+		if err != nil {
+			return nil, fmt.Errorf("rule %d: %w", i, err)
+		}
+		f.rules = append(f.rules, parsed)
+	}
+	return f, nil
+}
+
+func parseRule(r Rule, value interface{}) (rule, error) {
+	parsed := rule{Rule: r}
+	parsed.Operator = strings.ToLower(r.Operator)
+	if parsed.Operator == "" {
+		parsed.Operator = "in"
+	}
+	if r.Attribute == "" {
+		return parsed, errors.New("attribute is required")
+	}
+	if !operators[parsed.Operator] {
+		return parsed, fmt.Errorf("unknown operator %q", r.Operator)
+	}
+	if len(r.Values) == 0 {
+		return parsed, errors.New("values are required")
+	}
+	if parsed.Value == nil {
+		parsed.Value = value
+	}
+	if err := checkRollout(r.Rollout); err != nil {
+		return parsed, err
+	}
+	switch parsed.Operator {
+	case "matches":
+		for _, v := range r.Values {
+			pattern, err := regexp.Compile(v)
+			if err != nil {
+				return parsed, fmt.Errorf("invalid pattern %q: %w", v, err)
+			}
+			parsed.patterns = append(parsed.patterns, pattern)
+		}
+	case "gt", "gte", "lt", "lte":
+		for _, v := range r.Values {
+			n, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return parsed, fmt.Errorf("%s expects numbers, got %q", r.Operator, v)
+			}
+			parsed.numbers = append(parsed.numbers, n)
+		}
+	}
+	return parsed, nil
+}
+
+func checkRollout(rollout *float64) error {
+	if rollout != nil && (*rollout < 0 || *rollout > 100) {
+		return fmt.Errorf("rollout must be between 0 and 100, got %v", *rollout)
+	}
+	return nil
+}
+
+// attribute returns an attribute of the subject as a string, matching its name without case.
+func attribute(evalCtx FlattenedContext, name string) (string, bool) {
+	value, ok := evalCtx[name]
+	if !ok {
+		for k, v := range evalCtx {
+			if strings.EqualFold(k, name) {
+				value, ok = v, true
+				break
+			}
+		}
+	}
+	if !ok || value == nil {
+		return "", false
+	}
+	return fmt.Sprint(value), true
+}
+
+// matches reports whether the subject matches the rule. A rule never matches a subject
+// without its attribute.
+func (r rule) matches(evalCtx FlattenedContext) bool {
+	value, ok := attribute(evalCtx, r.Attribute)
+	if !ok {
+		return false
+	}
+	switch r.Operator {
+	case "neq", "notin":
+		for _, v := range r.Values {
+			if value == v {
+				return false
+			}
+		}
+		return true
+	case "matches":
+		for _, p := range r.patterns {
+			if p.MatchString(value) {
+				return true
+			}
+		}
+		return false
+	case "gt", "gte", "lt", "lte":
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return false
+		}
+		for _, v := range r.numbers {
+			if (r.Operator == "gt" && n > v) || (r.Operator == "gte" && n >= v) ||
+				(r.Operator == "lt" && n < v) || (r.Operator == "lte" && n <= v) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, v := range r.Values {
+		switch {
+		case (r.Operator == "eq" || r.Operator == "in") && value == v,
+			r.Operator == "startswith" && strings.HasPrefix(value, v),
+			r.Operator == "endswith" && strings.HasSuffix(value, v),
+			r.Operator == "contains" && strings.Contains(value, v):
+			return true
+		}
+	}
+	return false
+}
+
+// inRollout reports whether the subject is in the first percentage of the subjects. The
+// stickiness attribute is hashed with the name of the flag, so a subject keeps its value
+// while the rollout grows, and the flags are rolled out to different subjects.
+func (f *flag) inRollout(percentage float64, evalCtx FlattenedContext) bool {
+	key, ok := attribute(evalCtx, f.stickiness)
+	if !ok || key == "" {
+		return false
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(f.name + "/" + key))
+	return float64(h.Sum32()%100000)/1000 < percentage
+}
+
+// evaluate returns the value of the flag for the subject.
+func (f *flag) evaluate(evalCtx FlattenedContext) (interface{}, ProviderResolutionDetail) {
+	if !f.enabled {
+		return f.def, ProviderResolutionDetail{Reason: DisabledReason, Variant: "default"}
+	}
+	for i, r := range f.rules {
+		if !r.matches(evalCtx) {
+			continue
+		}
+		variant := "rule-" + strconv.Itoa(i)
+		if r.Rollout == nil {
+			return r.Value, ProviderResolutionDetail{Reason: TargetingMatchReason, Variant: variant}
+		}
+		if f.inRollout(*r.Rollout, evalCtx) {
+			return r.Value, ProviderResolutionDetail{Reason: SplitReason, Variant: variant}
+		}
+		return f.def, ProviderResolutionDetail{Reason: SplitReason, Variant: "default"}
+	}
+	if f.rollout != nil {
+		if f.inRollout(*f.rollout, evalCtx) {
+			return f.value, ProviderResolutionDetail{Reason: SplitReason, Variant: "value"}
+		}
+		return f.def, ProviderResolutionDetail{Reason: SplitReason, Variant: "default"}
+	}
+	if len(f.rules) == 0 {
+		return f.value, ProviderResolutionDetail{Reason: StaticReason, Variant: "value"}
+	}
+	return f.def, ProviderResolutionDetail{Reason: DefaultReason, Variant: "default"}
+}
+
+// ConfigProvider is the Provider of the flags of the `flags` section. The flags are
+// updated on the reloads of the configuration, and an invalid flag rejects the reload.
+type ConfigProvider struct {
+	flags atomic.Pointer[map[string]*flag]
+}
+
+// NewConfigProvider creates the provider of the flags of the configuration.
+//
+// Parameters:
+//   - cfg: Configuration
+//
+// Returns:
+//   - *ConfigProvider: The provider
+//   - error: nil if successful, error if a flag is invalid
+func NewConfigProvider(cfg *config.Config) (*ConfigProvider, error) {
+	flags, err := parseFlags(cfg)
+	if err != nil {
+		return nil, err
+	}
+	p := &ConfigProvider{}
+	p.flags.Store(&flags)
+	cfg.Validate("flags", func(next *config.Config) error {
+		_, err := parseFlags(next)
+		return err
+	})
+	cfg.OnChange("flags", func(cfg *config.Config) {
+		// validated before the reload
+		if flags, err := parseFlags(cfg); err == nil {
+			p.flags.Store(&flags)
+		}
+	})
+	return p, nil
+}
+
+// Metadata implements Provider.
+func (p *ConfigProvider) Metadata() Metadata {
+	return Metadata{Name: "config"}
+}
+
+func (p *ConfigProvider) resolve(flagName string, evalCtx FlattenedContext) (interface{}, ProviderResolutionDetail) {
+	f, ok := (*p.flags.Load())[strings.ToLower(flagName)]
+	if !ok {
+		return nil, failed(FlagNotFoundCode, fmt.Sprintf("flag %s is not defined", flagName))
+	}
+	return f.evaluate(evalCtx)
+}
+
+func failed(code ErrorCode, message string) ProviderResolutionDetail {
+	return ProviderResolutionDetail{
+		ResolutionError: &ResolutionError{Code: code, Message: message},
+		Reason:          ErrorReason,
+	}
+}
+
+func mismatch(flagName string, err error) ProviderResolutionDetail {
+	return failed(TypeMismatchCode, fmt.Sprintf("flag %s: %v", flagName, err))
+}
+
+// BooleanEvaluation implements Provider.
+func (p *ConfigProvider) BooleanEvaluation(_ context.Context, flag string, defaultValue bool, evalCtx FlattenedContext) BoolResolutionDetail {
+	value, detail := p.resolve(flag, evalCtx)
+	if detail.ResolutionError != nil {
+		return BoolResolutionDetail{Value: defaultValue, ProviderResolutionDetail: detail}
+	}
+	b, err := cast.ToBoolE(value)
+	if err != nil {
+		return BoolResolutionDetail{Value: defaultValue, ProviderResolutionDetail: mismatch(flag, err)}
+	}
+	return BoolResolutionDetail{Value: b, ProviderResolutionDetail: detail}
+}
+
+// StringEvaluation implements Provider.
+func (p *ConfigProvider) StringEvaluation(_ context.Context, flag string, defaultValue string, evalCtx FlattenedContext) StringResolutionDetail {
+	value, detail := p.resolve(flag, evalCtx)
+	if detail.ResolutionError != nil {
+		return StringResolutionDetail{Value: defaultValue, ProviderResolutionDetail: detail}
+	}
+	s, err := cast.ToStringE(value)
+	if err != nil {
+		return StringResolutionDetail{Value: defaultValue, ProviderResolutionDetail: mismatch(flag, err)}
+	}
+	return StringResolutionDetail{Value: s, ProviderResolutionDetail: detail}
+}
+
+// FloatEvaluation implements Provider.
+func (p *ConfigProvider) FloatEvaluation(_ context.Context, flag string, defaultValue float64, evalCtx FlattenedContext) FloatResolutionDetail {
+	value, detail := p.resolve(flag, evalCtx)
+	if detail.ResolutionError != nil {
+		return FloatResolutionDetail{Value: defaultValue, ProviderResolutionDetail: detail}
+	}
+	f, err := cast.ToFloat64E(value)
+	if err != nil {
+		return FloatResolutionDetail{Value: defaultValue, ProviderResolutionDetail: mismatch(flag, err)}
+	}
+	return FloatResolutionDetail{Value: f, ProviderResolutionDetail: detail}
+}
+
+// IntEvaluation implements Provider.
+func (p *ConfigProvider) IntEvaluation(_ context.Context, flag string, defaultValue int64, evalCtx FlattenedContext) IntResolutionDetail {
+	value, detail := p.resolve(flag, evalCtx)
+	if detail.ResolutionError != nil {
+		return IntResolutionDetail{Value: defaultValue, ProviderResolutionDetail: detail}
+	}
+	i, err := cast.ToInt64E(value)
+	if err != nil {
+		return IntResolutionDetail{Value: defaultValue, ProviderResolutionDetail: mismatch(flag, err)}
+	}
+	return IntResolutionDetail{Value: i, ProviderResolutionDetail: detail}
+}
+
+// ObjectEvaluation implements Provider.
+func (p *ConfigProvider) ObjectEvaluation(_ context.Context, flag string, defaultValue interface{}, evalCtx FlattenedContext) InterfaceResolutionDetail {
+	value, detail := p.resolve(flag, evalCtx)
+	if detail.ResolutionError != nil {
+		return InterfaceResolutionDetail{Value: defaultValue, ProviderResolutionDetail: detail}
+	}
+	return InterfaceResolutionDetail{Value: value, ProviderResolutionDetail: detail}
+}
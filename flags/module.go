@@ -0,0 +1,81 @@
+package flags
+
+import (
+	"context"
+
+	"github.com/go-liquor/liquor-sdk/config"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// FlagsModule provides the *Client of the flags and sets it as the default client of
+// the package functions (flags.Enabled...). The flags are read from the `flags` section
+// (see ConfigProvider) unless a provider is registered with RegisterProvider.
+var FlagsModule = fx.Module("liquor-flags", fx.Provide(
+	newClient,
+),
+	fx.Invoke(SetDefault),
+)
+
+// RegisterProvider replaces the ConfigProvider with your provider, e.g. an adapter of an
+// OpenFeature provider.
+//
+// Example:
+//
+//	app.NewApp(
+//	    flags.FlagsModule,
+//	    flags.RegisterProvider(NewLaunchDarklyProvider),
+//	)
+func RegisterProvider(provider any) fx.Option {
+	return fx.Module("liquor-flags-provider", fx.Provide(
+		fx.Annotate(provider, fx.As(new(Provider)), fx.ResultTags(`name:"liquor-flags-provider"`)),
+	))
+}
+
+// RegisterAttributes register the functions (or their constructors) reading attributes
+// of the subject from the contexts of the evaluations, e.g. the user of the request.
+//
+// Example:
+//
+//	app.NewApp(
+//	    flags.FlagsModule,
+//	    flags.RegisterAttributes(func(ctx context.Context) flags.Attributes {
+//	        user := auth.UserFrom(ctx)
+//	        return flags.Attributes{flags.TargetingKey: user.ID, "plan": user.Plan}
+//	    }),
+//	)
+func RegisterAttributes(attributes ...any) fx.Option {
+	provides := make([]any, len(attributes))
+	for i, a := range attributes {
+		switch fn := a.(type) {
+		case AttributesFunc:
+			provides[i] = fx.Annotate(func() AttributesFunc { return fn }, fx.ResultTags(`group:"liquor-flags-attributes"`))
+		case func(context.Context) Attributes:
+			provides[i] = fx.Annotate(func() AttributesFunc { return fn }, fx.ResultTags(`group:"liquor-flags-attributes"`))
+		default:
+			provides[i] = fx.Annotate(a, fx.ResultTags(`group:"liquor-flags-attributes"`))
+		}
+	}
+	return fx.Module("liquor-flags-attributes", fx.Provide(provides...))
+}
+
+type clientParams struct {
+	fx.In
+
+	Config     *config.Config
+	Logger     *zap.Logger
+	Provider   Provider         `name:"liquor-flags-provider" optional:"true"`
+	Attributes []AttributesFunc `group:"liquor-flags-attributes"`
+}
+
+func newClient(p clientParams) (*Client, error) {
+	provider := p.Provider
+	if provider == nil {
+		cp, err := NewConfigProvider(p.Config)
+		if err != nil {
+			return nil, err
+		}
+		provider = cp
+	}
+	return NewClient(provider, p.Logger, p.Attributes...), nil
+}
@@ -0,0 +1,117 @@
+package flags
+
+import "context"
+
+// The types of this file mirror the provider API of OpenFeature
+// (https://openfeature.dev/specification/sections/providers), so an OpenFeature provider
+// (LaunchDarkly, flagd, Flagsmith...) can back the flags with a thin adapter, and the
+// ConfigProvider can back an OpenFeature client.
+
+// FlattenedContext is the evaluation context: the attributes of the subject (user,
+// tenant...) and its TargetingKey.
+type FlattenedContext map[string]interface{}
+
+// TargetingKey is the attribute identifying the subject, hashed by the rollouts.
+const TargetingKey = "targetingKey"
+
+// Reason explains the value of an evaluation.
+type Reason string
+
+const (
+	// StaticReason is the value of a flag without rules.
+	StaticReason Reason = "STATIC"
+	// DefaultReason is the default value: no rule matched.
+	DefaultReason Reason = "DEFAULT"
+	// TargetingMatchReason is the value of a matching rule.
+	TargetingMatchReason Reason = "TARGETING_MATCH"
+	// SplitReason is the value of a percentage rollout.
+	SplitReason Reason = "SPLIT"
+	// DisabledReason is the default value of a disabled flag.
+	DisabledReason Reason = "DISABLED"
+	// ErrorReason is the default value after an error.
+	ErrorReason Reason = "ERROR"
+)
+
+// ErrorCode is the code of a failed evaluation.
+type ErrorCode string
+
+const (
+	// FlagNotFoundCode reports an unknown flag.
+	FlagNotFoundCode ErrorCode = "FLAG_NOT_FOUND"
+	// TypeMismatchCode reports a value of another type than the requested one.
+	TypeMismatchCode ErrorCode = "TYPE_MISMATCH"
+	// ParseErrorCode reports an invalid flag definition.
+	ParseErrorCode ErrorCode = "PARSE_ERROR"
+	// ProviderNotReadyCode reports a provider not initialized yet.
+	ProviderNotReadyCode ErrorCode = "PROVIDER_NOT_READY"
+	// GeneralCode reports the other errors.
+	GeneralCode ErrorCode = "GENERAL"
+)
+
+// ResolutionError is the error of a failed evaluation.
+type ResolutionError struct {
+	Code    ErrorCode
+	Message string
+}
+
+func (e *ResolutionError) Error() string {
+	if e.Message == "" {
+		return string(e.Code)
+	}
+	return string(e.Code) + ": " + e.Message
+}
+
+// Metadata describes a provider.
+type Metadata struct {
+	Name string
+}
+
+// ProviderResolutionDetail describes an evaluation.
+type ProviderResolutionDetail struct {
+	// ResolutionError is set when the evaluation failed: the value is the default one.
+	ResolutionError *ResolutionError
+	Reason          Reason
+	// Variant names the value served, e.g. "on", "rule-0".
+	Variant string
+}
+
+// BoolResolutionDetail is the result of a boolean evaluation.
+type BoolResolutionDetail struct {
+	Value bool
+	ProviderResolutionDetail
+}
+
+// StringResolutionDetail is the result of a string evaluation.
+type StringResolutionDetail struct {
+	Value string
+	ProviderResolutionDetail
+}
+
+// FloatResolutionDetail is the result of a float evaluation.
+type FloatResolutionDetail struct {
+	Value float64
+	ProviderResolutionDetail
+}
+
+// IntResolutionDetail is the result of an integer evaluation.
+type IntResolutionDetail struct {
+	Value int64
+	ProviderResolutionDetail
+}
+
+// InterfaceResolutionDetail is the result of an object evaluation.
+type InterfaceResolutionDetail struct {
+	Value interface{}
+	ProviderResolutionDetail
+}
+
+// Provider evaluates the flags. Each evaluation returns defaultValue with a
+// ResolutionError when the flag can't be evaluated (e.g. FlagNotFoundCode).
+type Provider interface {
+	Metadata() Metadata
+	BooleanEvaluation(ctx context.Context, flag string, defaultValue bool, evalCtx FlattenedContext) BoolResolutionDetail
+	StringEvaluation(ctx context.Context, flag string, defaultValue string, evalCtx FlattenedContext) StringResolutionDetail
+	FloatEvaluation(ctx context.Context, flag string, defaultValue float64, evalCtx FlattenedContext) FloatResolutionDetail
+	IntEvaluation(ctx context.Context, flag string, defaultValue int64, evalCtx FlattenedContext) IntResolutionDetail
+	ObjectEvaluation(ctx context.Context, flag string, defaultValue interface{}, evalCtx FlattenedContext) InterfaceResolutionDetail
+}
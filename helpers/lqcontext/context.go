@@ -0,0 +1,173 @@
+package lqcontext
+
+import (
+	"context"
+	"strings"
+)
+
+type contextKey string
+
+const (
+	requestIDKey   contextKey = "liquor-request-id"
+	authTokenKey   contextKey = "liquor-auth-token"
+	traceParentKey contextKey = "liquor-trace-parent"
+	traceStateKey  contextKey = "liquor-trace-state"
+)
+
+const (
+	// HeaderRequestID is the header (and gRPC metadata key) used to carry the request ID.
+	HeaderRequestID = "X-Request-Id"
+	// HeaderAuthorization is the header (and gRPC metadata key) used to carry the auth token.
+	HeaderAuthorization = "Authorization"
+	// HeaderTraceParent is the W3C trace context header.
+	HeaderTraceParent = "Traceparent"
+	// HeaderTraceState is the W3C trace state header.
+	HeaderTraceState = "Tracestate"
+)
+
+// WithRequestID returns a copy of ctx carrying the request ID.
+//
+// Parameters:
+//   - ctx: The parent context
+//   - requestID: The request ID to store
+//
+// Returns:
+//   - context.Context: The new context
+//
+// Example:
+//
+//	ctx = lqcontext.WithRequestID(ctx, c.GetHeader(lqcontext.HeaderRequestID))
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+// RequestID retrieves the request ID stored in ctx.
+//
+// Parameters:
+//   - ctx: The context to read from
+//
+// Returns:
+//   - string: The request ID, or an empty string if not set
+func RequestID(ctx context.Context) string {
+	v, _ := ctx.Value(requestIDKey).(string)
+	return v
+}
+
+// WithAuthToken returns a copy of ctx carrying the auth token (without the "Bearer " prefix).
+//
+// Parameters:
+//   - ctx: The parent context
+//   - token: The auth token to store
+//
+// Returns:
+//   - context.Context: The new context
+func WithAuthToken(ctx context.Context, token string) context.Context {
+	return context.WithValue(ctx, authTokenKey, token)
+}
+
+// AuthToken retrieves the auth token stored in ctx.
+//
+// Parameters:
+//   - ctx: The context to read from
+//
+// Returns:
+//   - string: The auth token, or an empty string if not set
+func AuthToken(ctx context.Context) string {
+	v, _ := ctx.Value(authTokenKey).(string)
+	return v
+}
+
+// WithTraceContext returns a copy of ctx carrying the W3C trace context.
+//
+// Parameters:
+//   - ctx: The parent context
+//   - traceParent: The traceparent value
+//   - traceState: The tracestate value (optional)
+//
+// Returns:
+//   - context.Context: The new context
+func WithTraceContext(ctx context.Context, traceParent, traceState string) context.Context {
+	ctx = context.WithValue(ctx, traceParentKey, traceParent)
+	return context.WithValue(ctx, traceStateKey, traceState)
+}
+
+// TraceContext retrieves the W3C trace context stored in ctx.
+//
+// Parameters:
+//   - ctx: The context to read from
+//
+// Returns:
+//   - string: The traceparent value, or an empty string if not set
+//   - string: The tracestate value, or an empty string if not set
+func TraceContext(ctx context.Context) (string, string) {
+	parent, _ := ctx.Value(traceParentKey).(string)
+	state, _ := ctx.Value(traceStateKey).(string)
+	return parent, state
+}
+
+// Headers returns the propagation headers stored in ctx, skipping empty values: the request
+// ID and the trace context. The auth token isn't included, the clients forward it only when
+// configured to (see AuthHeader).
+//
+// Parameters:
+//   - ctx: The context to read from
+//
+// Returns:
+//   - map[string]string: Header name to value
+//
+// Example:
+//
+//	for k, v := range lqcontext.Headers(ctx) {
+//	    req.Header.Set(k, v)
+//	}
+func Headers(ctx context.Context) map[string]string {
+	headers := map[string]string{}
+	if v := RequestID(ctx); v != "" {
+		headers[HeaderRequestID] = v
+	}
+	parent, state := TraceContext(ctx)
+	if parent != "" {
+		headers[HeaderTraceParent] = parent
+	}
+	if state != "" {
+		headers[HeaderTraceState] = state
+	}
+	return headers
+}
+
+// AuthHeader returns the Authorization header of the auth token stored in ctx.
+//
+// Parameters:
+//   - ctx: The context to read from
+//
+// Returns:
+//   - string: The "Bearer <token>" value, or an empty string if not set
+func AuthHeader(ctx context.Context) string {
+	if v := AuthToken(ctx); v != "" {
+		return "Bearer " + v
+	}
+	return ""
+}
+
+// FromHeaders returns a copy of ctx carrying the propagation headers of an incoming request:
+// the request ID, the bearer token and the W3C trace context. The HTTP and gRPC servers call
+// it for every request, so the clients forward them (see Headers and AuthHeader).
+//
+// Parameters:
+//   - ctx: The parent context
+//   - header: Returns the value of a header (or gRPC metadata key)
+//
+// Returns:
+//   - context.Context: The new context
+func FromHeaders(ctx context.Context, header func(name string) string) context.Context {
+	if v := header(HeaderRequestID); v != "" {
+		ctx = WithRequestID(ctx, v)
+	}
+	if v := header(HeaderAuthorization); len(v) > 7 && strings.EqualFold(v[:7], "bearer ") {
+		ctx = WithAuthToken(ctx, strings.TrimSpace(v[7:]))
+	}
+	if parent := header(HeaderTraceParent); parent != "" {
+		ctx = WithTraceContext(ctx, parent, header(HeaderTraceState))
+	}
+	return ctx
+}
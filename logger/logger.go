@@ -1,14 +1,14 @@
 package logger
 
 import (
-	"log"
+	"fmt"
 
 	"github.com/go-liquor/liquor-sdk/config"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
 
-func instanceLogger(config *config.Config) *zap.Logger {
+func instanceLogger(config *config.Config) (*zap.Logger, error) {
 	cfg := zap.NewProductionConfig()
 	cfg.DisableCaller = true
 	cfg.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
@@ -19,30 +19,52 @@ func instanceLogger(config *config.Config) *zap.Logger {
 		cfg.EncoderConfig.EncodeLevel = zapcore.CapitalColorLevelEncoder
 	}
 
-	var level zapcore.Level
-	switch config.GetLogLevel() {
-	case "debug":
+	level := parseLevel(config.GetLogLevel())
+	if level == zapcore.DebugLevel {
 		cfg.DisableCaller = false
 		cfg.DisableStacktrace = false
-		level = zapcore.DebugLevel
+	}
+	cfg.Level = zap.NewAtomicLevelAt(level)
+
+	watchLevel(config, cfg.Level)
+
+	logger, err := cfg.Build(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return &redactCore{Core: core, config: config}
+	}))
+	if err != nil {
+		return nil, fmt.Errorf("logger: failed to build logger (log.level=%q, log.format=%q): %w", config.GetLogLevel(), config.GetLogFormat(), err)
+	}
+	return logger, nil
+}
+
+// logConfig is the section of the logger watched for reloads.
+type logConfig struct {
+	Level string `validate:"omitempty,oneof=debug info warn error dpanic panic fatal"`
+}
+
+// watchLevel applies the changes of `log.level` to the logger without restart; an
+// unknown level rejects the reload.
+func watchLevel(cfg *config.Config, level zap.AtomicLevel) {
+	config.Watch(cfg, "log", func(l logConfig) {
+		level.SetLevel(parseLevel(l.Level))
+	})
+}
+
+func parseLevel(level string) zapcore.Level {
+	switch level {
+	case "debug":
+		return zapcore.DebugLevel
 	case "warn":
-		level = zapcore.WarnLevel
+		return zapcore.WarnLevel
 	case "error":
-		level = zapcore.ErrorLevel
+		return zapcore.ErrorLevel
 	case "dpanic":
-		level = zapcore.DPanicLevel
+		return zapcore.DPanicLevel
 	case "panic":
-		level = zapcore.PanicLevel
+		return zapcore.PanicLevel
 	case "fatal":
-		level = zapcore.FatalLevel
+		return zapcore.FatalLevel
 	default:
-		level = zapcore.InfoLevel
-	}
-	cfg.Level = zap.NewAtomicLevelAt(level)
-
-	logger, err := cfg.Build()
-	if err != nil {
-		log.Fatalf("failed to build logger: %v", err)
+		return zapcore.InfoLevel
 	}
-	return logger
 }
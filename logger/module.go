@@ -1,10 +1,68 @@
 package logger
 
 import (
+	"sort"
+
+	"github.com/go-liquor/liquor-sdk/config"
 	"go.uber.org/fx"
+	"go.uber.org/zap"
 )
 
 // LoggerModule enable the logger (is required)
 var LoggerModule = fx.Module("liquor-logger", fx.Provide(
 	instanceLogger,
-))
+),
+	fx.Invoke(logConfigSources, logConfigReloads),
+)
+
+// logConfigSources logs the sources of the configuration, lowest precedence first.
+func logConfigSources(logger *zap.Logger, cfg *config.Config) {
+	sources := cfg.Sources()
+	names := make([]string, len(sources))
+	for i, s := range sources {
+		names[i] = s.String()
+	}
+	overrides := cfg.EnvOverrides()
+	keys := make([]string, 0, len(overrides))
+	for k := range overrides {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	logger.Info("configuration loaded",
+		zap.String("app", cfg.GetAppName()),
+		zap.Strings("sources", names),
+		zap.String("env", cfg.GetAppEnv()),
+		zap.Strings("envOverrides", keys),
+	)
+}
+
+// logConfigReloads logs the reloads of the configuration.
+func logConfigReloads(logger *zap.Logger, cfg *config.Config) {
+	cfg.OnReload(func(changed []string, err error) {
+		if err != nil {
+			logger.Error("configuration reload failed", zap.Error(err))
+			return
+		}
+		logger.Info("configuration reloaded", zap.Strings("changed", changed))
+	})
+}
+
+// WarnUnusedConfigKeys warns about the keys of the config files that the app never read,
+// often typos or leftovers. app.Application calls it once every start hook ran, since
+// the servers read some keys (e.g. their ports) on start.
+//
+// Config keys:
+//   - config.unusedKeys.warn: Warns about the unused keys (default true)
+//   - config.unusedKeys.ignore: The sections read later, e.g. on each request (optional)
+//
+// Parameters:
+//   - logger: The logger
+//   - cfg: The configuration
+func WarnUnusedConfigKeys(logger *zap.Logger, cfg *config.Config) {
+	if cfg.IsSet("config.unusedKeys.warn") && !cfg.GetBool("config.unusedKeys.warn") {
+		return
+	}
+	if unused := cfg.UnusedKeys(cfg.GetStringSlice("config.unusedKeys.ignore")...); len(unused) > 0 {
+		logger.Warn("config keys never read", zap.Strings("keys", unused))
+	}
+}
@@ -0,0 +1,59 @@
+package logger
+
+import (
+	"github.com/go-liquor/liquor-sdk/config"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// redactCore replaces the secrets of the configuration (see config.Config.Redact) in the
+// messages and the string and error fields of the logs.
+type redactCore struct {
+	zapcore.Core
+	config *config.Config
+}
+
+func (c *redactCore) With(fields []zapcore.Field) zapcore.Core {
+	return &redactCore{Core: c.Core.With(c.redact(fields)), config: c.config}
+}
+
+func (c *redactCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+func (c *redactCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	entry.Message = c.config.Redact(entry.Message)
+	return c.Core.Write(entry, c.redact(fields))
+}
+
+func (c *redactCore) redact(fields []zapcore.Field) []zapcore.Field {
+	out := fields
+	for i, f := range fields {
+		var text string
+		switch f.Type {
+		case zapcore.StringType:
+			text = f.String
+		case zapcore.ErrorType:
+			err, ok := f.Interface.(error)
+			if !ok || err == nil {
+				continue
+			}
+			text = err.Error()
+		default:
+			continue
+		}
+		redacted := c.config.Redact(text)
+		if redacted == text {
+			continue
+		}
+		if &out[0] == &fields[0] {
+			// the fields of the caller are left untouched
+			out = append([]zapcore.Field(nil), fields...)
+		}
+		out[i] = zap.String(f.Key, redacted)
+	}
+	return out
+}
@@ -0,0 +1,47 @@
+package migrate
+
+import (
+	"context"
+	"time"
+)
+
+// DefaultLockTTL is the age after which a lock row (or document) is stale: its holder
+// stopped refreshing it, e.g. it was killed while migrating, and another replica takes it over.
+const DefaultLockTTL = time.Minute
+
+// KeepLock refreshes a lock every third of its TTL until the returned function is called,
+// so a lock held by a long migration isn't taken over as stale.
+//
+// Parameters:
+//   - ttl: The TTL of the lock (DefaultLockTTL if zero)
+//   - refresh: The function updating the lock time, called with a context bounded by the TTL
+//
+// Returns:
+//   - func(): The function stopping the refresh, to call before releasing the lock
+func KeepLock(ttl time.Duration, refresh func(ctx context.Context) error) func() {
+	if ttl <= 0 {
+		ttl = DefaultLockTTL
+	}
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(ttl / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				ctx, cancel := context.WithTimeout(context.Background(), ttl)
+				// a failed refresh is retried on the next tick, before the lock gets stale
+				_ = refresh(ctx)
+				cancel()
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
@@ -0,0 +1,161 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+// Func is the body of a Go migration. db is the handle given by the store:
+// a bun.IDB (the migration transaction) for the sql stores and a *mongo.Database for MongoDB.
+type Func func(ctx context.Context, db any) error
+
+// Migration is a numbered schema change. Up (or UpSQL) applies it and Down (or DownSQL)
+// rolls it back; a migration without down cannot be rolled back.
+type Migration struct {
+	Version int64
+	Name    string
+	Up      Func
+	Down    Func
+	UpSQL   string
+	DownSQL string
+}
+
+// Go creates a migration written in Go.
+//
+// Parameters:
+//   - version: The migration number (migrations are applied in ascending order)
+//   - name: A short description of the migration
+//   - up: The function applying the migration
+//   - down: The function rolling back the migration (optional)
+//
+// Returns:
+//   - Migration: The migration
+//
+// Example:
+//
+//	migrate.Go(2, "backfill_users", func(ctx context.Context, db bun.IDB) error {
+//	    _, err := db.NewUpdate().Table("users").Set("active = true").Where("1 = 1").Exec(ctx)
+//	    return err
+//	}, nil)
+func Go[DB any](version int64, name string, up, down func(ctx context.Context, db DB) error) Migration {
+	return Migration{
+		Version: version,
+		Name:    name,
+		Up:      typed(up),
+		Down:    typed(down),
+	}
+}
+
+// SQL creates a migration from SQL statements.
+//
+// Parameters:
+//   - version: The migration number
+//   - name: A short description of the migration
+//   - up: The statements applying the migration
+//   - down: The statements rolling back the migration (optional)
+//
+// Returns:
+//   - Migration: The migration
+func SQL(version int64, name, up, down string) Migration {
+	return Migration{
+		Version: version,
+		Name:    name,
+		UpSQL:   up,
+		DownSQL: down,
+	}
+}
+
+var fileName = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+// FromFS reads the SQL migrations of a directory. Files are named
+// `<version>_<name>.up.sql` and `<version>_<name>.down.sql`; the down file is optional.
+//
+// Parameters:
+//   - fsys: The file system (e.g. an embed.FS)
+//   - dir: The directory holding the migrations ("." for the root)
+//
+// Returns:
+//   - []Migration: The migrations sorted by version
+//   - error: nil if successful, error otherwise
+//
+// Example:
+//
+//	//go:embed migrations/*.sql
+//	var migrations embed.FS
+//
+//	list, err := migrate.FromFS(migrations, "migrations")
+func FromFS(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("migrate: failed to read migrations in %q: %w", dir, err)
+	}
+
+	byVersion := map[int64]*Migration{}
+	for _, entry := range entries {
+		match := fileName.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("migrate: invalid version in %q: %w", entry.Name(), err)
+		}
+		content, err := fs.ReadFile(fsys, joinPath(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("migrate: failed to read %q: %w", entry.Name(), err)
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		}
+		if m.Name != match[2] {
+			return nil, fmt.Errorf("migrate: version %d is used by %q and %q", version, m.Name, match[2])
+		}
+		if match[3] == "up" {
+			m.UpSQL = string(content)
+		} else {
+			m.DownSQL = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.UpSQL == "" {
+			return nil, fmt.Errorf("migrate: migration %d_%s has no up file", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+func joinPath(dir, name string) string {
+	if dir == "" || dir == "." {
+		return name
+	}
+	return dir + "/" + name
+}
+
+// typed adapts a function receiving the store handle as DB.
+func typed[DB any](fn func(ctx context.Context, db DB) error) Func {
+	if fn == nil {
+		return nil
+	}
+	return func(ctx context.Context, db any) error {
+		handle, ok := db.(DB)
+		if !ok {
+			return fmt.Errorf("migrate: migration expects a %s handle, the store gives %T",
+				reflect.TypeOf((*DB)(nil)).Elem(), db)
+		}
+		return fn(ctx, handle)
+	}
+}
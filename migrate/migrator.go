@@ -0,0 +1,242 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Options configures a Migrator.
+type Options struct {
+	// DryRun prints the migrations that would run instead of running them.
+	DryRun bool
+	// Output receives the dry-run plan (os.Stdout if nil).
+	Output io.Writer
+}
+
+// Migrator applies and rolls back versioned migrations.
+type Migrator struct {
+	store      Store
+	migrations []Migration
+	logger     *zap.Logger
+	options    Options
+}
+
+// NewMigrator creates a Migrator.
+//
+// Parameters:
+//   - store: The database store
+//   - migrations: The migrations (in any order)
+//   - logger: The logger (may be nil)
+//   - options: The migrator options
+//
+// Returns:
+//   - *Migrator: The migrator
+//   - error: An error if two migrations share a version or a migration is empty
+func NewMigrator(store Store, migrations []Migration, logger *zap.Logger, options Options) (*Migrator, error) {
+	sorted := append([]Migration(nil), migrations...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Version < sorted[j].Version
+	})
+	for i, m := range sorted {
+		if m.Version <= 0 {
+			return nil, fmt.Errorf("migrate: migration %q has an invalid version %d", m.Name, m.Version)
+		}
+		if m.Up == nil && m.UpSQL == "" {
+			return nil, fmt.Errorf("migrate: migration %d_%s has no up migration", m.Version, m.Name)
+		}
+		if i > 0 && sorted[i-1].Version == m.Version {
+			return nil, fmt.Errorf("migrate: version %d is used by %q and %q", m.Version, sorted[i-1].Name, m.Name)
+		}
+	}
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	if options.Output == nil {
+		options.Output = os.Stdout
+	}
+	return &Migrator{store: store, migrations: sorted, logger: logger, options: options}, nil
+}
+
+// Up applies the pending migrations in ascending version order.
+//
+// Parameters:
+//   - ctx: Context for the migrations
+//
+// Returns:
+//   - error: nil if successful, error otherwise (the migrations applied before the failure are kept)
+func (m *Migrator) Up(ctx context.Context) error {
+	if m.options.DryRun {
+		pending, err := m.Pending(ctx)
+		if err != nil {
+			return err
+		}
+		for _, migration := range pending {
+			m.plan("apply", migration, migration.UpSQL)
+		}
+		return nil
+	}
+
+	unlock, err := m.store.Lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer m.release(unlock)
+
+	// the pending migrations are read with the lock held: another replica may have applied them
+	pending, err := m.Pending(ctx)
+	if err != nil {
+		return err
+	}
+	for _, migration := range pending {
+		start := time.Now()
+		if err := m.store.Apply(ctx, migration); err != nil {
+			return fmt.Errorf("migrate: migration %d_%s failed: %w", migration.Version, migration.Name, err)
+		}
+		m.logger.Info("migration applied",
+			zap.Int64("version", migration.Version),
+			zap.String("name", migration.Name),
+			zap.Duration("duration", time.Since(start)))
+	}
+	return nil
+}
+
+// Down rolls back the last applied migrations.
+//
+// Parameters:
+//   - ctx: Context for the migrations
+//   - steps: The number of migrations to roll back
+//
+// Returns:
+//   - error: nil if successful, error otherwise
+func (m *Migrator) Down(ctx context.Context, steps int) error {
+	if !m.options.DryRun {
+		unlock, err := m.store.Lock(ctx)
+		if err != nil {
+			return err
+		}
+		defer m.release(unlock)
+	}
+
+	applied, err := m.store.Applied(ctx)
+	if err != nil {
+		return err
+	}
+	for i := len(applied) - 1; i >= 0 && steps > 0; i, steps = i-1, steps-1 {
+		migration, ok := m.find(applied[i].Version)
+		if !ok {
+			return fmt.Errorf("migrate: applied migration %d_%s is not registered", applied[i].Version, applied[i].Name)
+		}
+		if migration.Down == nil && migration.DownSQL == "" {
+			return fmt.Errorf("migrate: migration %d_%s has no down migration", migration.Version, migration.Name)
+		}
+		if m.options.DryRun {
+			m.plan("roll back", migration, migration.DownSQL)
+			continue
+		}
+		if err := m.store.Revert(ctx, migration); err != nil {
+			return fmt.Errorf("migrate: rollback of %d_%s failed: %w", migration.Version, migration.Name, err)
+		}
+		m.logger.Info("migration rolled back",
+			zap.Int64("version", migration.Version),
+			zap.String("name", migration.Name))
+	}
+	return nil
+}
+
+// Pending returns the migrations not applied yet.
+//
+// Parameters:
+//   - ctx: Context for the query
+//
+// Returns:
+//   - []Migration: The pending migrations in ascending version order
+//   - error: nil if successful, error otherwise
+func (m *Migrator) Pending(ctx context.Context) ([]Migration, error) {
+	applied, err := m.appliedVersions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var pending []Migration
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; !ok {
+			pending = append(pending, migration)
+		}
+	}
+	return pending, nil
+}
+
+// Status writes a table with every migration and whether it is applied.
+//
+// Parameters:
+//   - ctx: Context for the query
+//   - w: The output
+//
+// Returns:
+//   - error: nil if successful, error otherwise
+func (m *Migrator) Status(ctx context.Context, w io.Writer) error {
+	applied, err := m.appliedVersions(ctx)
+	if err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+	for _, migration := range m.migrations {
+		record, ok := applied[migration.Version]
+		if !ok {
+			fmt.Fprintf(tw, "%d\t%s\tpending\t-\n", migration.Version, migration.Name)
+			continue
+		}
+		fmt.Fprintf(tw, "%d\t%s\tapplied\t%s\n", migration.Version, migration.Name, record.AppliedAt.Format(time.RFC3339))
+		delete(applied, migration.Version)
+	}
+	for _, record := range applied {
+		fmt.Fprintf(tw, "%d\t%s\tunknown\t%s\n", record.Version, record.Name, record.AppliedAt.Format(time.RFC3339))
+	}
+	return tw.Flush()
+}
+
+func (m *Migrator) appliedVersions(ctx context.Context) (map[int64]Record, error) {
+	records, err := m.store.Applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	applied := make(map[int64]Record, len(records))
+	for _, r := range records {
+		applied[r.Version] = r
+	}
+	return applied, nil
+}
+
+func (m *Migrator) find(version int64) (Migration, bool) {
+	for _, migration := range m.migrations {
+		if migration.Version == version {
+			return migration, true
+		}
+	}
+	return Migration{}, false
+}
+
+func (m *Migrator) plan(action string, migration Migration, query string) {
+	fmt.Fprintf(m.options.Output, "-- %s %d_%s\n", action, migration.Version, migration.Name)
+	if query == "" {
+		fmt.Fprintln(m.options.Output, "-- (go migration)")
+		return
+	}
+	fmt.Fprintln(m.options.Output, query)
+}
+
+func (m *Migrator) release(unlock func(context.Context) error) {
+	// the lock is released even if ctx is canceled
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := unlock(ctx); err != nil {
+		m.logger.Error("failed to release the migration lock", zap.Error(err))
+	}
+}
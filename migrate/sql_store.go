@@ -0,0 +1,210 @@
+package migrate
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"time"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect"
+)
+
+// lockRetry is the wait between two attempts to take a lock held by another replica.
+const lockRetry = time.Second
+
+// SQLStore is the Store of the bun databases (postgres, mysql and sqlite).
+// Go migrations receive the migration transaction as a bun.IDB.
+//
+// The lock is a session advisory lock on postgres (pg_advisory_lock) and mysql (GET_LOCK);
+// other dialects use a row in the `<table>_lock` table, refreshed while held and taken over
+// once older than the lock TTL.
+type SQLStore struct {
+	db      *bun.DB
+	table   string
+	lockTTL time.Duration
+}
+
+// NewSQLStore creates a Store for a bun database.
+//
+// Parameters:
+//   - db: The database
+//   - table: The table tracking the applied migrations (DefaultTable if empty)
+//   - lockTTL: The age of a stale lock row (DefaultLockTTL if zero)
+//
+// Returns:
+//   - *SQLStore: The store
+func NewSQLStore(db *bun.DB, table string, lockTTL time.Duration) *SQLStore {
+	if table == "" {
+		table = DefaultTable
+	}
+	if lockTTL <= 0 {
+		lockTTL = DefaultLockTTL
+	}
+	return &SQLStore{db: db, table: table, lockTTL: lockTTL}
+}
+
+// Lock implements Store.
+func (s *SQLStore) Lock(ctx context.Context) (func(context.Context) error, error) {
+	switch s.db.Dialect().Name() {
+	case dialect.PG:
+		return s.advisoryLock(ctx, "SELECT pg_advisory_lock(?)", "SELECT pg_advisory_unlock(?)", s.lockKey())
+	case dialect.MySQL:
+		return s.mysqlLock(ctx)
+	default:
+		return s.tableLock(ctx)
+	}
+}
+
+func (s *SQLStore) lockKey() int64 {
+	h := fnv.New64a()
+	h.Write([]byte(s.table))
+	return int64(h.Sum64())
+}
+
+// advisoryLock holds a session lock, so the connection is kept until the unlock.
+func (s *SQLStore) advisoryLock(ctx context.Context, lock, unlock string, key any) (func(context.Context) error, error) {
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("migrate: failed to get a connection for the lock: %w", err)
+	}
+	if _, err := conn.ExecContext(ctx, lock, key); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("migrate: failed to take the migration lock: %w", err)
+	}
+	return func(ctx context.Context) error {
+		defer conn.Close()
+		_, err := conn.ExecContext(ctx, unlock, key)
+		return err
+	}, nil
+}
+
+func (s *SQLStore) mysqlLock(ctx context.Context) (func(context.Context) error, error) {
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("migrate: failed to get a connection for the lock: %w", err)
+	}
+	for {
+		var acquired int
+		if err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, 5)", s.table).Scan(&acquired); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("migrate: failed to take the migration lock: %w", err)
+		}
+		if acquired == 1 {
+			break
+		}
+		if ctx.Err() != nil {
+			conn.Close()
+			return nil, fmt.Errorf("migrate: failed to take the migration lock: %w", ctx.Err())
+		}
+	}
+	return func(ctx context.Context) error {
+		defer conn.Close()
+		_, err := conn.ExecContext(ctx, "SELECT RELEASE_LOCK(?)", s.table)
+		return err
+	}, nil
+}
+
+func (s *SQLStore) tableLock(ctx context.Context) (func(context.Context) error, error) {
+	lockTable := bun.Ident(s.table + "_lock")
+	if _, err := s.db.ExecContext(ctx,
+		"CREATE TABLE IF NOT EXISTS ? (id INTEGER PRIMARY KEY, owner VARCHAR(64) NOT NULL, locked_at TIMESTAMP NOT NULL)",
+		lockTable); err != nil {
+		return nil, fmt.Errorf("migrate: failed to create the lock table: %w", err)
+	}
+	owner, err := lockOwner()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		now := time.Now().UTC()
+		if _, err := s.db.ExecContext(ctx, "INSERT INTO ? (id, owner, locked_at) VALUES (1, ?, ?)", lockTable, owner, now); err == nil {
+			break
+		}
+		// the holder of a stale lock stopped refreshing it: take it over
+		res, err := s.db.ExecContext(ctx, "UPDATE ? SET owner = ?, locked_at = ? WHERE id = 1 AND locked_at < ?",
+			lockTable, owner, now, now.Add(-s.lockTTL))
+		if err == nil {
+			if n, _ := res.RowsAffected(); n == 1 {
+				break
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("migrate: failed to take the migration lock (held in %s): %w", s.table+"_lock", ctx.Err())
+		case <-time.After(lockRetry):
+		}
+	}
+	stop := KeepLock(s.lockTTL, func(ctx context.Context) error {
+		_, err := s.db.ExecContext(ctx, "UPDATE ? SET locked_at = ? WHERE id = 1 AND owner = ?", lockTable, time.Now().UTC(), owner)
+		return err
+	})
+	return func(ctx context.Context) error {
+		stop()
+		_, err := s.db.ExecContext(ctx, "DELETE FROM ? WHERE id = 1 AND owner = ?", lockTable, owner)
+		return err
+	}, nil
+}
+
+// lockOwner returns a random token identifying the holder of a lock row.
+func lockOwner() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("migrate: failed to generate the lock owner: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// Applied implements Store.
+func (s *SQLStore) Applied(ctx context.Context) ([]Record, error) {
+	if _, err := s.db.ExecContext(ctx,
+		"CREATE TABLE IF NOT EXISTS ? (version BIGINT PRIMARY KEY, name VARCHAR(255) NOT NULL, applied_at TIMESTAMP NOT NULL)",
+		bun.Ident(s.table)); err != nil {
+		return nil, fmt.Errorf("migrate: failed to create table %s: %w", s.table, err)
+	}
+	var records []Record
+	if err := s.db.NewRaw("SELECT version, name, applied_at FROM ? ORDER BY version", bun.Ident(s.table)).
+		Scan(ctx, &records); err != nil {
+		return nil, fmt.Errorf("migrate: failed to read table %s: %w", s.table, err)
+	}
+	return records, nil
+}
+
+// Apply implements Store.
+func (s *SQLStore) Apply(ctx context.Context, m Migration) error {
+	return s.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		if err := s.run(ctx, tx, m.UpSQL, m.Up); err != nil {
+			return err
+		}
+		_, err := tx.ExecContext(ctx, "INSERT INTO ? (version, name, applied_at) VALUES (?, ?, ?)",
+			bun.Ident(s.table), m.Version, m.Name, time.Now().UTC())
+		return err
+	})
+}
+
+// Revert implements Store.
+func (s *SQLStore) Revert(ctx context.Context, m Migration) error {
+	return s.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		if err := s.run(ctx, tx, m.DownSQL, m.Down); err != nil {
+			return err
+		}
+		_, err := tx.ExecContext(ctx, "DELETE FROM ? WHERE version = ?", bun.Ident(s.table), m.Version)
+		return err
+	})
+}
+
+func (s *SQLStore) run(ctx context.Context, tx bun.Tx, query string, fn Func) error {
+	switch {
+	case fn != nil:
+		return fn(ctx, tx)
+	case query != "":
+		// the statements are sent as is: bun would read `?` as placeholders
+		_, err := tx.Tx.ExecContext(ctx, query)
+		return err
+	default:
+		return errors.New("migrate: migration has nothing to run")
+	}
+}
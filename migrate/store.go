@@ -0,0 +1,30 @@
+package migrate
+
+import (
+	"context"
+	"time"
+)
+
+// DefaultTable is the table (or collection) tracking the applied migrations.
+const DefaultTable = "liquor_migrations"
+
+// Record is an applied migration.
+type Record struct {
+	Version   int64     `bun:"version" bson:"version"`
+	Name      string    `bun:"name" bson:"name"`
+	AppliedAt time.Time `bun:"applied_at" bson:"appliedAt"`
+}
+
+// Store runs the migrations against a database and tracks the applied ones.
+type Store interface {
+	// Lock blocks until the migration lock is held, so only one replica migrates at a time.
+	// It returns the function releasing the lock.
+	Lock(ctx context.Context) (unlock func(context.Context) error, err error)
+	// Applied returns the applied migrations sorted by version,
+	// creating the tracking table if needed.
+	Applied(ctx context.Context) ([]Record, error)
+	// Apply runs the up migration and records it.
+	Apply(ctx context.Context, m Migration) error
+	// Revert runs the down migration and removes its record.
+	Revert(ctx context.Context, m Migration) error
+}
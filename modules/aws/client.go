@@ -2,6 +2,7 @@ package aws
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	awsconfig "github.com/aws/aws-sdk-go-v2/config"
@@ -11,7 +12,7 @@ import (
 
 // AwsClient creates and returns an AWS configuration using the provided Config and logger.
 // It loads the default AWS configuration with the region specified in the config.
-// If the configuration loading fails, it logs a fatal error using the provided logger.
+// If the configuration loading fails, the error is returned so the application fails to start.
 //
 // Parameters:
 //   - config: A pointer to the Config struct containing AWS configuration settings
@@ -19,7 +20,8 @@ import (
 //
 // Returns:
 //   - aws.Config: The AWS configuration object
-func AwsClient(config *config.Config, logger *zap.Logger) aws.Config {
+//   - error: nil if successful, error otherwise
+func AwsClient(config *config.Config, logger *zap.Logger) (aws.Config, error) {
 	cfg, err := awsconfig.LoadDefaultConfig(context.TODO(),
 		awsconfig.WithRegion(config.GetString("aws.region")),
 		awsconfig.WithCredentialsProvider(aws.CredentialsProviderFunc(func(ctx context.Context) (aws.Credentials, error) {
@@ -29,7 +31,7 @@ func AwsClient(config *config.Config, logger *zap.Logger) aws.Config {
 			}, nil
 		})))
 	if err != nil {
-		logger.Fatal("failed to load aws config", zap.Error(err))
+		return aws.Config{}, fmt.Errorf("aws: failed to load aws config for region %q (aws.region): %w", config.GetString("aws.region"), err)
 	}
-	return cfg
+	return cfg, nil
 }
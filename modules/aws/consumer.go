@@ -0,0 +1,350 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/go-liquor/liquor-sdk/config"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// deleteInterval is the longest wait of a processed message before its batch is deleted.
+const deleteInterval = time.Second
+
+// SQSHandler handles the messages of an SQS queue (see HandleSQS).
+type SQSHandler struct {
+	// Queue is the name of the SQS queue.
+	Queue string
+	// Handle processes a message: the message is deleted when it returns nil, and
+	// received again after its visibility timeout otherwise.
+	Handle func(ctx context.Context, msg types.Message) error
+}
+
+// HandleSQS creates the handler of an SQS queue.
+//
+// Parameters:
+//   - queue: The name of the SQS queue
+//   - handle: The function processing a message
+//
+// Returns:
+//   - SQSHandler: The handler, to return from a constructor given to RegisterSQSHandlers
+func HandleSQS(queue string, handle func(ctx context.Context, msg types.Message) error) SQSHandler {
+	return SQSHandler{Queue: queue, Handle: handle}
+}
+
+// HandleSQSJSON creates the handler of an SQS queue whose message bodies are JSON.
+//
+// Parameters:
+//   - queue: The name of the SQS queue
+//   - handle: The function processing a decoded body
+//
+// Returns:
+//   - SQSHandler: The handler, to return from a constructor given to RegisterSQSHandlers
+//
+// Example:
+//
+//	aws.HandleSQSJSON("orders", func(ctx context.Context, order OrderCreated) error {
+//	    return svc.Ship(ctx, order.ID)
+//	})
+func HandleSQSJSON[T any](queue string, handle func(ctx context.Context, body T) error) SQSHandler {
+	return HandleSQS(queue, func(ctx context.Context, msg types.Message) error {
+		var body T
+		if err := json.Unmarshal([]byte(aws.ToString(msg.Body)), &body); err != nil {
+			return fmt.Errorf("aws: failed to decode message %s: %w", aws.ToString(msg.MessageId), err)
+		}
+		return handle(ctx, body)
+	})
+}
+
+// SQSConsumer long-polls the SQS queues of the registered handlers and runs the handlers
+// on the received messages. It is registered as the "sqs-consumer" worker.
+type SQSConsumer struct {
+	client   SQSClient
+	config   *config.Config
+	logger   *zap.Logger
+	handlers []SQSHandler
+}
+
+type sqsConsumerParams struct {
+	fx.In
+
+	Client   SQSClient
+	Config   *config.Config
+	Logger   *zap.Logger
+	Handlers []SQSHandler `group:"liquor-sqs-handlers"`
+}
+
+// consumerOptions are the options of the consumer of a queue.
+type consumerOptions struct {
+	url         string
+	concurrency int
+	waitTime    time.Duration
+	visibility  time.Duration
+}
+
+// NewSQSConsumer creates the consumer of the registered handlers.
+//
+// Config keys (per queue):
+//   - aws.sqs.consumers.<queue>.url: The URL of the queue (resolved from its name if empty)
+//   - aws.sqs.consumers.<queue>.concurrency: The messages processed at the same time (default 10)
+//   - aws.sqs.consumers.<queue>.waitTime: The long polling wait (default 20s)
+//   - aws.sqs.consumers.<queue>.visibilityTimeout: The visibility timeout of the received messages
+//     (default 30s); the visibility of a message is extended while its handler runs
+//   - app.stopTimeout: On stop, the running handlers finish within it (default 15s), then
+//     their context is canceled
+//
+// Returns:
+//   - *SQSConsumer: The consumer
+//   - error: An error if two handlers consume the same queue
+func NewSQSConsumer(p sqsConsumerParams) (*SQSConsumer, error) {
+	seen := map[string]bool{}
+	for _, h := range p.Handlers {
+		if seen[h.Queue] {
+			return nil, fmt.Errorf("aws: the sqs queue %q has two handlers", h.Queue)
+		}
+		seen[h.Queue] = true
+	}
+	return &SQSConsumer{client: p.Client, config: p.Config, logger: p.Logger, handlers: p.Handlers}, nil
+}
+
+// Name implements app.Worker.
+func (c *SQSConsumer) Name() string {
+	return "sqs-consumer"
+}
+
+// Run implements app.Worker: it consumes the queues until ctx is canceled, then waits for
+// the running handlers and deletes their processed messages. The handlers aren't canceled
+// with ctx: their context is canceled when they don't finish within the drain timeout
+// (see NewSQSConsumer).
+func (c *SQSConsumer) Run(ctx context.Context) error {
+	options := make([]consumerOptions, len(c.handlers))
+	for i, h := range c.handlers {
+		opts, err := c.options(ctx, h.Queue)
+		if err != nil {
+			return err
+		}
+		options[i] = opts
+	}
+
+	var wg sync.WaitGroup
+	for i, h := range c.handlers {
+		wg.Add(1)
+		go func(h SQSHandler, opts consumerOptions) {
+			defer wg.Done()
+			c.consume(ctx, h, opts)
+		}(h, options[i])
+	}
+	wg.Wait()
+	return nil
+}
+
+func (c *SQSConsumer) options(ctx context.Context, queue string) (consumerOptions, error) {
+	prefix := "aws.sqs.consumers." + queue + "."
+	opts := consumerOptions{
+		url:         c.config.GetString(prefix + "url"),
+		concurrency: c.config.GetInt(prefix + "concurrency"),
+		waitTime:    20 * time.Second,
+		visibility:  30 * time.Second,
+	}
+	if c.config.IsSet(prefix + "waitTime") {
+		opts.waitTime = c.config.GetDuration(prefix + "waitTime")
+	}
+	if v := c.config.GetDuration(prefix + "visibilityTimeout"); v > 0 {
+		opts.visibility = v
+	}
+	if opts.concurrency <= 0 {
+		opts.concurrency = 10
+	}
+	if opts.url == "" {
+		url, err := c.client.GetQueueURL(ctx, queue)
+		if err != nil {
+			return opts, fmt.Errorf("aws: failed to resolve the url of the sqs queue %q: %w", queue, err)
+		}
+		opts.url = aws.ToString(url)
+	}
+	return opts, nil
+}
+
+// consume receives messages while handlers are free, so at most opts.concurrency
+// messages are processed (and hidden from the other consumers) at the same time.
+func (c *SQSConsumer) consume(ctx context.Context, h SQSHandler, opts consumerOptions) {
+	log := c.logger.With(zap.String("queue", h.Queue))
+	deleter := newBatchDeleter(c.client, opts.url, log)
+	go deleter.run(context.WithoutCancel(ctx))
+
+	// the handlers finish the received messages on stop, within the drain timeout
+	handlerCtx, cancel := drainContext(ctx, c.drainTimeout())
+	defer cancel()
+
+	slots := make(chan struct{}, opts.concurrency)
+	var wg sync.WaitGroup
+	for ctx.Err() == nil {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			continue
+		}
+		free := min(10, cap(slots)-len(slots)+1)
+		messages, err := c.client.WaitMessages(ctx, opts.url, int32(free), opts.waitTime, opts.visibility)
+		if err != nil && ctx.Err() == nil {
+			log.Error("aws: failed to receive sqs messages", zap.Error(err))
+			select {
+			case <-ctx.Done():
+			case <-time.After(time.Second):
+			}
+		}
+		if len(messages) == 0 {
+			<-slots
+			continue
+		}
+		for i, msg := range messages {
+			if i > 0 {
+				slots <- struct{}{}
+			}
+			wg.Add(1)
+			go func(msg types.Message) {
+				defer func() {
+					<-slots
+					wg.Done()
+				}()
+				if c.handle(handlerCtx, h, opts, msg, log) {
+					deleter.add(aws.ToString(msg.ReceiptHandle))
+				}
+			}(msg)
+		}
+	}
+
+	wg.Wait()
+	deleter.close()
+}
+
+// drainTimeout returns how long the running handlers have to finish once the consumer
+// stops: the stop timeout of the app, less the time to delete their messages.
+func (c *SQSConsumer) drainTimeout() time.Duration {
+	timeout := c.config.GetDuration("app.stopTimeout")
+	if timeout <= 0 {
+		timeout = fx.DefaultTimeout
+	}
+	return max(timeout-2*deleteInterval, timeout/2)
+}
+
+// drainContext returns a context that isn't canceled with ctx, but timeout after it.
+func drainContext(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	drain, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	go func() {
+		select {
+		case <-ctx.Done():
+			select {
+			case <-time.After(timeout):
+			case <-drain.Done():
+			}
+		case <-drain.Done():
+		}
+		cancel()
+	}()
+	return drain, cancel
+}
+
+// handle runs the handler on a message and reports whether it succeeded. The visibility
+// of the message is extended every half visibility timeout while the handler runs.
+func (c *SQSConsumer) handle(ctx context.Context, h SQSHandler, opts consumerOptions, msg types.Message, log *zap.Logger) bool {
+	log = log.With(zap.String("message", aws.ToString(msg.MessageId)))
+	receipt := aws.ToString(msg.ReceiptHandle)
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		ticker := time.NewTicker(opts.visibility / 2)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := c.client.ChangeMessageVisibility(context.WithoutCancel(ctx), opts.url, receipt, opts.visibility); err != nil {
+					log.Warn("aws: failed to extend the visibility of the sqs message", zap.Error(err))
+				}
+			}
+		}
+	}()
+
+	start := time.Now()
+	err := safeHandle(ctx, h, msg)
+	if err != nil {
+		log.Error("failed to handle sqs message", zap.Duration("duration", time.Since(start)), zap.Error(err))
+		return false
+	}
+	log.Debug("sqs message handled", zap.Duration("duration", time.Since(start)))
+	return true
+}
+
+func safeHandle(ctx context.Context, h SQSHandler, msg types.Message) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v\n%s", r, debug.Stack())
+		}
+	}()
+	return h.Handle(ctx, msg)
+}
+
+// batchDeleter deletes the processed messages by batches of 10, or after deleteInterval.
+type batchDeleter struct {
+	client  SQSClient
+	url     string
+	logger  *zap.Logger
+	handles chan string
+	done    chan struct{}
+}
+
+func newBatchDeleter(client SQSClient, url string, logger *zap.Logger) *batchDeleter {
+	return &batchDeleter{client: client, url: url, logger: logger, handles: make(chan string, 10), done: make(chan struct{})}
+}
+
+func (d *batchDeleter) add(receipt string) {
+	d.handles <- receipt
+}
+
+// close deletes the pending messages and waits for the deleter to stop.
+func (d *batchDeleter) close() {
+	close(d.handles)
+	<-d.done
+}
+
+func (d *batchDeleter) run(ctx context.Context) {
+	defer close(d.done)
+	ticker := time.NewTicker(deleteInterval)
+	defer ticker.Stop()
+
+	var batch []string
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := d.client.DeleteBatchMessages(ctx, d.url, batch); err != nil {
+			d.logger.Error("aws: failed to delete sqs messages", zap.Int("count", len(batch)), zap.Error(err))
+		}
+		batch = nil
+	}
+	for {
+		select {
+		case receipt, ok := <-d.handles:
+			if !ok {
+				flush()
+				return
+			}
+			batch = append(batch, receipt)
+			if len(batch) == 10 {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
@@ -1,6 +1,12 @@
 package aws
 
-import "go.uber.org/fx"
+import (
+	"github.com/go-liquor/liquor-sdk/app"
+	"github.com/go-liquor/liquor-sdk/config"
+	"github.com/go-liquor/liquor-sdk/outbox"
+	"github.com/go-liquor/liquor-sdk/queue"
+	"go.uber.org/fx"
+)
 
 // AwsClientModule is a module that provides all the AWS clients.
 var AwsClientModule = fx.Module("liquor-module-aws", fx.Provide(
@@ -10,3 +16,41 @@ var AwsClientModule = fx.Module("liquor-module-aws", fx.Provide(
 	NewKmsClient,
 	NewDynamoDBClient,
 ))
+
+// QueueBackendModule provides the QueueBackend as the queue.Backend of queue.QueueModule.
+var QueueBackendModule = fx.Module("liquor-module-aws-queue", fx.Provide(
+	fx.Annotate(NewQueueBackend, fx.As(new(queue.Backend))),
+))
+
+// SQSConsumerModule provides the *SQSConsumer and runs it as the "sqs-consumer" worker.
+var SQSConsumerModule = fx.Module("liquor-module-aws-sqs-consumer", fx.Provide(
+	NewSQSConsumer,
+),
+	app.RegisterWorkers(func(c *SQSConsumer) *SQSConsumer { return c }),
+)
+
+// RegisterSQSHandlers register the constructors of your SQS handlers (see HandleSQS).
+//
+// Example:
+//
+//	app.NewApp(
+//	    aws.AwsClientModule,
+//	    aws.SQSConsumerModule,
+//	    aws.RegisterSQSHandlers(func(svc *OrderService) aws.SQSHandler {
+//	        return aws.HandleSQSJSON("orders", svc.OnOrderCreated)
+//	    }),
+//	)
+func RegisterSQSHandlers(handlers ...any) fx.Option {
+	annotated := make([]any, len(handlers))
+	for i, h := range handlers {
+		annotated[i] = fx.Annotate(h, fx.ResultTags(`group:"liquor-sqs-handlers"`))
+	}
+	return fx.Module("liquor-module-aws-sqs-handlers", fx.Provide(annotated...))
+}
+
+// OutboxPublisherModule provides the "sqs" publisher of outbox.RelayModule.
+var OutboxPublisherModule = outbox.RegisterPublishers(NewOutboxPublisher)
+
+// SecretResolversModule resolves the ${aws-sm:...} and ${aws-kms:...} references of the
+// configuration (see SecretsManagerResolver and KMSResolver).
+var SecretResolversModule = config.RegisterResolvers(NewSecretsManagerResolver, NewKMSResolver)
@@ -0,0 +1,53 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/go-liquor/liquor-sdk/config"
+	"github.com/go-liquor/liquor-sdk/outbox"
+)
+
+// OutboxPublisher delivers the messages of the outbox to SQS queues. The body of a
+// message is the payload.
+type OutboxPublisher struct {
+	client SQSClient
+	config *config.Config
+}
+
+// NewOutboxPublisher creates the "sqs" publisher of the outbox relay.
+//
+// Config keys:
+//   - outbox.topics.<topic>.destination: The URL of the SQS queue of a topic
+//     (resolved from the queue named after the topic if empty)
+//
+// Parameters:
+//   - client: SQS client
+//   - cfg: Configuration
+//
+// Returns:
+//   - *OutboxPublisher: The publisher
+func NewOutboxPublisher(client SQSClient, cfg *config.Config) *OutboxPublisher {
+	cfg.Uses("outbox.topics")
+	return &OutboxPublisher{client: client, config: cfg}
+}
+
+// Name implements outbox.Publisher.
+func (p *OutboxPublisher) Name() string {
+	return "sqs"
+}
+
+// Publish implements outbox.Publisher.
+func (p *OutboxPublisher) Publish(ctx context.Context, msg outbox.Message) error {
+	url := p.config.GetString("outbox.topics." + msg.Topic + ".destination")
+	if url == "" {
+		resolved, err := p.client.GetQueueURL(ctx, msg.Topic)
+		if err != nil {
+			return fmt.Errorf("aws: failed to resolve the url of the sqs queue %q: %w", msg.Topic, err)
+		}
+		url = aws.ToString(resolved)
+	}
+	_, err := p.client.SendMessage(ctx, url, string(msg.Payload))
+	return err
+}
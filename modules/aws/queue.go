@@ -0,0 +1,140 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/go-liquor/liquor-sdk/config"
+	"github.com/go-liquor/liquor-sdk/queue"
+)
+
+// maxDelay is the longest delay of an SQS message.
+const maxDelay = 15 * time.Minute
+
+// QueueBackend stores the jobs of the job queue in SQS queues. Each job queue maps to an
+// SQS queue URL; a job is a message whose body is the job in JSON.
+//
+// SQS delays messages up to 15 minutes: a job due later is delivered early and sent again
+// with the remaining delay. Retries are sent as new delayed messages.
+type QueueBackend struct {
+	client SQSClient
+	config *config.Config
+}
+
+// NewQueueBackend creates a backend storing the jobs in SQS.
+//
+// Config keys:
+//   - queue.sqs.queues.<name>.url: The URL of the SQS queue of the job queue <name>
+//   - queue.sqs.queues.<name>.deadLetterUrl: The URL of the SQS queue of its dead letters
+//     (the dead letters are dropped when empty)
+//
+// Parameters:
+//   - client: SQS client
+//   - cfg: Configuration
+//
+// Returns:
+//   - *QueueBackend: The backend
+func NewQueueBackend(client SQSClient, cfg *config.Config) *QueueBackend {
+	cfg.Uses("queue.sqs.queues")
+	return &QueueBackend{client: client, config: cfg}
+}
+
+func (b *QueueBackend) url(name string) (string, error) {
+	url := b.config.GetString("queue.sqs.queues." + name + ".url")
+	if url == "" {
+		return "", fmt.Errorf("aws: queue.sqs.queues.%s.url is not set", name)
+	}
+	return url, nil
+}
+
+func (b *QueueBackend) send(ctx context.Context, url string, job queue.Job) error {
+	raw, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("aws: failed to encode job %s: %w", job.ID, err)
+	}
+	delay := min(max(time.Until(job.RunAt), 0), maxDelay)
+	if _, err := b.client.SendDelayedMessage(ctx, url, string(raw), delay); err != nil {
+		return fmt.Errorf("aws: failed to send job %s: %w", job.ID, err)
+	}
+	return nil
+}
+
+// Enqueue implements queue.Backend.
+func (b *QueueBackend) Enqueue(ctx context.Context, job queue.Job) error {
+	url, err := b.url(job.Queue)
+	if err != nil {
+		return err
+	}
+	return b.send(ctx, url, job)
+}
+
+// Dequeue implements queue.Backend. The job is reserved for the visibility timeout of the SQS queue.
+func (b *QueueBackend) Dequeue(ctx context.Context, name string) (*queue.Job, error) {
+	url, err := b.url(name)
+	if err != nil {
+		return nil, err
+	}
+	messages, err := b.client.ReceiveMessages(ctx, url, 1)
+	if err != nil {
+		return nil, fmt.Errorf("aws: failed to receive from %s: %w", name, err)
+	}
+	if len(messages) == 0 || messages[0].Body == nil || messages[0].ReceiptHandle == nil {
+		return nil, nil
+	}
+	message := messages[0]
+
+	var job queue.Job
+	if err := json.Unmarshal([]byte(*message.Body), &job); err != nil {
+		return nil, fmt.Errorf("aws: failed to decode a job of %s: %w", name, err)
+	}
+	job.Receipt = *message.ReceiptHandle
+	if job.RunAt.After(time.Now()) {
+		// delivered early because of the 15 minutes limit: send it again with the remaining delay
+		if err := b.send(ctx, url, job); err != nil {
+			return nil, err
+		}
+		return nil, b.client.DeleteMessage(ctx, url, job.Receipt)
+	}
+	return &job, nil
+}
+
+// Ack implements queue.Backend.
+func (b *QueueBackend) Ack(ctx context.Context, job *queue.Job) error {
+	url, err := b.url(job.Queue)
+	if err != nil {
+		return err
+	}
+	return b.client.DeleteMessage(ctx, url, job.Receipt)
+}
+
+// Retry implements queue.Backend.
+func (b *QueueBackend) Retry(ctx context.Context, job *queue.Job) error {
+	url, err := b.url(job.Queue)
+	if err != nil {
+		return err
+	}
+	if err := b.send(ctx, url, *job); err != nil {
+		return err
+	}
+	return b.client.DeleteMessage(ctx, url, job.Receipt)
+}
+
+// DeadLetter implements queue.Backend.
+func (b *QueueBackend) DeadLetter(ctx context.Context, job *queue.Job) error {
+	url, err := b.url(job.Queue)
+	if err != nil {
+		return err
+	}
+	if deadLetterURL := b.config.GetString("queue.sqs.queues." + job.Queue + ".deadLetterUrl"); deadLetterURL != "" {
+		raw, err := json.Marshal(job)
+		if err != nil {
+			return fmt.Errorf("aws: failed to encode job %s: %w", job.ID, err)
+		}
+		if _, err := b.client.SendMessage(ctx, deadLetterURL, string(raw)); err != nil {
+			return fmt.Errorf("aws: failed to dead-letter job %s: %w", job.ID, err)
+		}
+	}
+	return b.client.DeleteMessage(ctx, url, job.Receipt)
+}
@@ -0,0 +1,127 @@
+package aws
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/go-liquor/liquor-sdk/config"
+	"go.uber.org/zap"
+)
+
+// SecretsManagerResolver resolves the ${aws-sm:<secret>#<field>} references of the
+// configuration to the values of AWS Secrets Manager:
+//   - ${aws-sm:prod/db}: the secret string
+//   - ${aws-sm:prod/db#dsn}: the dsn field of a JSON secret
+//
+// The client is created on the first reference, with the aws.* keys of the configuration.
+type SecretsManagerResolver struct {
+	mu     sync.Mutex
+	client *secretsmanager.Client
+}
+
+// NewSecretsManagerResolver creates the "aws-sm" resolver.
+//
+// Returns:
+//   - *SecretsManagerResolver: The resolver
+func NewSecretsManagerResolver() *SecretsManagerResolver {
+	return &SecretsManagerResolver{}
+}
+
+// Scheme implements config.SecretResolver.
+func (r *SecretsManagerResolver) Scheme() string {
+	return "aws-sm"
+}
+
+// Resolve implements config.SecretResolver.
+func (r *SecretsManagerResolver) Resolve(ctx context.Context, cfg *config.Config, ref string) (string, error) {
+	r.mu.Lock()
+	if r.client == nil {
+		awsCfg, err := AwsClient(cfg, zap.NewNop())
+		if err != nil {
+			r.mu.Unlock()
+			return "", err
+		}
+		r.client = secretsmanager.NewFromConfig(awsCfg)
+	}
+	client := r.client
+	r.mu.Unlock()
+
+	name, field, _ := strings.Cut(ref, "#")
+	out, err := client.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{SecretId: aws.String(name)})
+	if err != nil {
+		return "", fmt.Errorf("aws: failed to get secret %s: %w", name, err)
+	}
+	value := aws.ToString(out.SecretString)
+	if out.SecretString == nil {
+		value = string(out.SecretBinary)
+	}
+	if field == "" {
+		return value, nil
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal([]byte(value), &fields); err != nil {
+		return "", fmt.Errorf("aws: secret %s is not a JSON object: %w", name, err)
+	}
+	v, ok := fields[field]
+	if !ok {
+		return "", fmt.Errorf("aws: secret %s has no field %s", name, field)
+	}
+	if s, ok := v.(string); ok {
+		return s, nil
+	}
+	return fmt.Sprint(v), nil
+}
+
+// KMSResolver resolves the ${aws-kms:<base64 ciphertext>} references of the configuration
+// to their plaintext, decrypted with the KMSClient. The ciphertext is the output of
+// `aws kms encrypt`.
+//
+// The client is created on the first reference, with the aws.* keys of the configuration.
+type KMSResolver struct {
+	mu     sync.Mutex
+	client KMSClient
+}
+
+// NewKMSResolver creates the "aws-kms" resolver.
+//
+// Returns:
+//   - *KMSResolver: The resolver
+func NewKMSResolver() *KMSResolver {
+	return &KMSResolver{}
+}
+
+// Scheme implements config.SecretResolver.
+func (r *KMSResolver) Scheme() string {
+	return "aws-kms"
+}
+
+// Resolve implements config.SecretResolver.
+func (r *KMSResolver) Resolve(ctx context.Context, cfg *config.Config, ref string) (string, error) {
+	r.mu.Lock()
+	if r.client == nil {
+		awsCfg, err := AwsClient(cfg, zap.NewNop())
+		if err != nil {
+			r.mu.Unlock()
+			return "", err
+		}
+		r.client = NewKmsClient(awsCfg)
+	}
+	client := r.client
+	r.mu.Unlock()
+
+	ciphertext, err := base64.StdEncoding.DecodeString(strings.TrimSpace(ref))
+	if err != nil {
+		return "", fmt.Errorf("aws: invalid base64 ciphertext: %w", err)
+	}
+	plaintext, err := client.Decrypt(ctx, ciphertext)
+	if err != nil {
+		return "", fmt.Errorf("aws: failed to decrypt: %w", err)
+	}
+	return string(plaintext), nil
+}
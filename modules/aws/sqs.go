@@ -2,6 +2,10 @@ package aws
 
 import (
 	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/sqs"
@@ -11,8 +15,12 @@ import (
 // SQSClient defines the interface for interacting with AWS SQS operations.
 type SQSClient interface {
 	SendMessage(ctx context.Context, queueURL string, messageBody string) (*string, error)
+	SendDelayedMessage(ctx context.Context, queueURL string, messageBody string, delay time.Duration) (*string, error)
 	ReceiveMessages(ctx context.Context, queueURL string, maxMessages int32) ([]types.Message, error)
+	WaitMessages(ctx context.Context, queueURL string, maxMessages int32, wait, visibility time.Duration) ([]types.Message, error)
+	ChangeMessageVisibility(ctx context.Context, queueURL string, receiptHandle string, timeout time.Duration) error
 	DeleteMessage(ctx context.Context, queueURL string, receiptHandle string) error
+	DeleteBatchMessages(ctx context.Context, queueURL string, receiptHandles []string) error
 	GetQueueURL(ctx context.Context, queueName string) (*string, error)
 	SendBatchMessages(ctx context.Context, queueURL string, messages []string) error
 }
@@ -60,6 +68,32 @@ func (s *sqsClient) SendMessage(ctx context.Context, queueURL string, messageBod
 	return result.MessageId, nil
 }
 
+// SendDelayedMessage sends a single message to an SQS queue, delivered after a delay.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - queueURL: The URL of the SQS queue
+//   - messageBody: The message content to send
+//   - delay: The delay before the message is delivered (up to 15 minutes, rounded down to seconds)
+//
+// Returns:
+//   - *string: The MessageId of the sent message
+//   - error: nil if successful, error otherwise
+func (s *sqsClient) SendDelayedMessage(ctx context.Context, queueURL string, messageBody string, delay time.Duration) (*string, error) {
+	input := &sqs.SendMessageInput{
+		QueueUrl:     aws.String(queueURL),
+		MessageBody:  aws.String(messageBody),
+		DelaySeconds: int32(delay / time.Second),
+	}
+
+	result, err := s.client.SendMessage(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.MessageId, nil
+}
+
 // ReceiveMessages retrieves messages from an SQS queue.
 //
 // Parameters:
@@ -84,6 +118,59 @@ func (s *sqsClient) ReceiveMessages(ctx context.Context, queueURL string, maxMes
 	return result.Messages, nil
 }
 
+// WaitMessages retrieves messages from an SQS queue with long polling: the call waits
+// until a message is available or the wait time elapses.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - queueURL: The URL of the SQS queue
+//   - maxMessages: Maximum number of messages to receive (1-10)
+//   - wait: How long to wait for a message (up to 20 seconds)
+//   - visibility: How long the received messages are hidden from the other consumers
+//     (0 for the visibility timeout of the queue)
+//
+// Returns:
+//   - []types.Message: Array of received messages (empty when none arrived)
+//   - error: nil if successful, error otherwise
+func (s *sqsClient) WaitMessages(ctx context.Context, queueURL string, maxMessages int32, wait, visibility time.Duration) ([]types.Message, error) {
+	input := &sqs.ReceiveMessageInput{
+		QueueUrl:                    aws.String(queueURL),
+		MaxNumberOfMessages:         maxMessages,
+		WaitTimeSeconds:             int32(wait / time.Second),
+		VisibilityTimeout:           int32(visibility / time.Second),
+		MessageSystemAttributeNames: []types.MessageSystemAttributeName{types.MessageSystemAttributeNameAll},
+		MessageAttributeNames:       []string{"All"},
+	}
+
+	result, err := s.client.ReceiveMessage(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Messages, nil
+}
+
+// ChangeMessageVisibility changes how long a received message stays hidden from the other consumers.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - queueURL: The URL of the SQS queue
+//   - receiptHandle: The receipt handle of the message
+//   - timeout: The new visibility timeout, from now (0 makes the message visible again)
+//
+// Returns:
+//   - error: nil if successful, error otherwise
+func (s *sqsClient) ChangeMessageVisibility(ctx context.Context, queueURL string, receiptHandle string, timeout time.Duration) error {
+	input := &sqs.ChangeMessageVisibilityInput{
+		QueueUrl:          aws.String(queueURL),
+		ReceiptHandle:     aws.String(receiptHandle),
+		VisibilityTimeout: int32(timeout / time.Second),
+	}
+
+	_, err := s.client.ChangeMessageVisibility(ctx, input)
+	return err
+}
+
 // DeleteMessage removes a message from the queue after processing.
 //
 // Parameters:
@@ -103,6 +190,43 @@ func (s *sqsClient) DeleteMessage(ctx context.Context, queueURL string, receiptH
 	return err
 }
 
+// DeleteBatchMessages removes up to 10 messages from the queue in a single request.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - queueURL: The URL of the SQS queue
+//   - receiptHandles: The receipt handles of the messages to delete
+//
+// Returns:
+//   - error: nil if successful, an error listing the failed deletions otherwise
+func (s *sqsClient) DeleteBatchMessages(ctx context.Context, queueURL string, receiptHandles []string) error {
+	var entries []types.DeleteMessageBatchRequestEntry
+	for i, handle := range receiptHandles {
+		entries = append(entries, types.DeleteMessageBatchRequestEntry{
+			Id:            aws.String(strconv.Itoa(i)),
+			ReceiptHandle: aws.String(handle),
+		})
+	}
+
+	input := &sqs.DeleteMessageBatchInput{
+		QueueUrl: aws.String(queueURL),
+		Entries:  entries,
+	}
+
+	result, err := s.client.DeleteMessageBatch(ctx, input)
+	if err != nil {
+		return err
+	}
+	if len(result.Failed) > 0 {
+		var failures []string
+		for _, f := range result.Failed {
+			failures = append(failures, aws.ToString(f.Id)+": "+aws.ToString(f.Message))
+		}
+		return fmt.Errorf("aws: failed to delete %d messages: %s", len(result.Failed), strings.Join(failures, ", "))
+	}
+	return nil
+}
+
 // GetQueueURL retrieves the URL of an SQS queue by its name.
 //
 // Parameters:
@@ -12,6 +12,7 @@ package aws
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	types "github.com/aws/aws-sdk-go-v2/service/sqs/types"
 	gomock "go.uber.org/mock/gomock"
@@ -41,6 +42,34 @@ func (m *MockSQSClient) EXPECT() *MockSQSClientMockRecorder {
 	return m.recorder
 }
 
+// ChangeMessageVisibility mocks base method.
+func (m *MockSQSClient) ChangeMessageVisibility(ctx context.Context, queueURL, receiptHandle string, timeout time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangeMessageVisibility", ctx, queueURL, receiptHandle, timeout)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ChangeMessageVisibility indicates an expected call of ChangeMessageVisibility.
+func (mr *MockSQSClientMockRecorder) ChangeMessageVisibility(ctx, queueURL, receiptHandle, timeout any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeMessageVisibility", reflect.TypeOf((*MockSQSClient)(nil).ChangeMessageVisibility), ctx, queueURL, receiptHandle, timeout)
+}
+
+// DeleteBatchMessages mocks base method.
+func (m *MockSQSClient) DeleteBatchMessages(ctx context.Context, queueURL string, receiptHandles []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteBatchMessages", ctx, queueURL, receiptHandles)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteBatchMessages indicates an expected call of DeleteBatchMessages.
+func (mr *MockSQSClientMockRecorder) DeleteBatchMessages(ctx, queueURL, receiptHandles any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteBatchMessages", reflect.TypeOf((*MockSQSClient)(nil).DeleteBatchMessages), ctx, queueURL, receiptHandles)
+}
+
 // DeleteMessage mocks base method.
 func (m *MockSQSClient) DeleteMessage(ctx context.Context, queueURL, receiptHandle string) error {
 	m.ctrl.T.Helper()
@@ -99,6 +128,21 @@ func (mr *MockSQSClientMockRecorder) SendBatchMessages(ctx, queueURL, messages a
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendBatchMessages", reflect.TypeOf((*MockSQSClient)(nil).SendBatchMessages), ctx, queueURL, messages)
 }
 
+// SendDelayedMessage mocks base method.
+func (m *MockSQSClient) SendDelayedMessage(ctx context.Context, queueURL, messageBody string, delay time.Duration) (*string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendDelayedMessage", ctx, queueURL, messageBody, delay)
+	ret0, _ := ret[0].(*string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendDelayedMessage indicates an expected call of SendDelayedMessage.
+func (mr *MockSQSClientMockRecorder) SendDelayedMessage(ctx, queueURL, messageBody, delay any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendDelayedMessage", reflect.TypeOf((*MockSQSClient)(nil).SendDelayedMessage), ctx, queueURL, messageBody, delay)
+}
+
 // SendMessage mocks base method.
 func (m *MockSQSClient) SendMessage(ctx context.Context, queueURL, messageBody string) (*string, error) {
 	m.ctrl.T.Helper()
@@ -113,3 +157,18 @@ func (mr *MockSQSClientMockRecorder) SendMessage(ctx, queueURL, messageBody any)
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMessage", reflect.TypeOf((*MockSQSClient)(nil).SendMessage), ctx, queueURL, messageBody)
 }
+
+// WaitMessages mocks base method.
+func (m *MockSQSClient) WaitMessages(ctx context.Context, queueURL string, maxMessages int32, wait, visibility time.Duration) ([]types.Message, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WaitMessages", ctx, queueURL, maxMessages, wait, visibility)
+	ret0, _ := ret[0].([]types.Message)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WaitMessages indicates an expected call of WaitMessages.
+func (mr *MockSQSClientMockRecorder) WaitMessages(ctx, queueURL, maxMessages, wait, visibility any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WaitMessages", reflect.TypeOf((*MockSQSClient)(nil).WaitMessages), ctx, queueURL, maxMessages, wait, visibility)
+}
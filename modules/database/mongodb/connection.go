@@ -1,6 +1,8 @@
 package mongodb
 
 import (
+	"fmt"
+
 	"github.com/go-liquor/liquor-sdk/config"
 	"go.mongodb.org/mongo-driver/v2/mongo"
 	"go.mongodb.org/mongo-driver/v2/mongo/options"
@@ -11,13 +13,18 @@ import (
 //
 // Returns:
 // - *mongo.Client: a new connection to mongodb database
-func NewConnection(config *config.Config, logger *zap.Logger) *mongo.Client {
+// - error: nil if successful, error otherwise
+func NewConnection(config *config.Config, logger *zap.Logger) (*mongo.Client, error) {
+	uri := config.GetString("database.mongodb.dns")
+	if uri == "" {
+		return nil, fmt.Errorf("database/mongodb: missing config key %q", "database.mongodb.dns")
+	}
 	client, err := mongo.Connect(options.Client().
-		ApplyURI(config.GetString("database.mongodb.dns")))
+		ApplyURI(uri))
 	if err != nil {
-		logger.Fatal("failed to connect in database", zap.Error(err))
+		return nil, fmt.Errorf("database/mongodb: failed to connect in database using %q: %w", "database.mongodb.dns", err)
 	}
-	return client
+	return client, nil
 }
 
 // UseDatabase use a database in mongodb
@@ -0,0 +1,130 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-liquor/liquor-sdk/config"
+	"github.com/go-liquor/liquor-sdk/migrate"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// lockRetry is the wait between two attempts to take a lock held by another replica.
+const lockRetry = time.Second
+
+// MigrationStore is the migrate.Store of mongodb. Go migrations receive the *mongo.Database;
+// SQL migrations are not supported.
+//
+// The lock is a document in the `<collection>_lock` collection, refreshed while held and
+// taken over once older than `database.migrations.lockTTL` (default migrate.DefaultLockTTL).
+type MigrationStore struct {
+	db         *mongo.Database
+	collection string
+	lockTTL    time.Duration
+}
+
+// NewMigrationStore create the store of the versioned migrations (see app.RegisterMigrations)
+//
+// Returns:
+// - migrate.Store: a store tracking the migrations in the `database.migrations.table` collection
+func NewMigrationStore(db *mongo.Database, config *config.Config) migrate.Store {
+	collection := config.GetString("database.migrations.table")
+	if collection == "" {
+		collection = migrate.DefaultTable
+	}
+	lockTTL := config.GetDuration("database.migrations.lockTTL")
+	if lockTTL <= 0 {
+		lockTTL = migrate.DefaultLockTTL
+	}
+	return &MigrationStore{db: db, collection: collection, lockTTL: lockTTL}
+}
+
+// Lock implements migrate.Store.
+func (s *MigrationStore) Lock(ctx context.Context) (func(context.Context) error, error) {
+	locks := s.db.Collection(s.collection + "_lock")
+	owner := bson.NewObjectID().Hex()
+	for {
+		now := time.Now().UTC()
+		_, err := locks.InsertOne(ctx, bson.D{{Key: "_id", Value: "lock"}, {Key: "owner", Value: owner}, {Key: "lockedAt", Value: now}})
+		if err == nil {
+			break
+		}
+		if !mongo.IsDuplicateKeyError(err) {
+			return nil, fmt.Errorf("database/mongodb: failed to take the migration lock: %w", err)
+		}
+		// the holder of a stale lock stopped refreshing it: take it over
+		res, err := locks.UpdateOne(ctx,
+			bson.D{{Key: "_id", Value: "lock"}, {Key: "lockedAt", Value: bson.D{{Key: "$lt", Value: now.Add(-s.lockTTL)}}}},
+			bson.D{{Key: "$set", Value: bson.D{{Key: "owner", Value: owner}, {Key: "lockedAt", Value: now}}}})
+		if err == nil && res.ModifiedCount == 1 {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("database/mongodb: failed to take the migration lock (held in %s): %w", s.collection+"_lock", ctx.Err())
+		case <-time.After(lockRetry):
+		}
+	}
+	stop := migrate.KeepLock(s.lockTTL, func(ctx context.Context) error {
+		_, err := locks.UpdateOne(ctx,
+			bson.D{{Key: "_id", Value: "lock"}, {Key: "owner", Value: owner}},
+			bson.D{{Key: "$set", Value: bson.D{{Key: "lockedAt", Value: time.Now().UTC()}}}})
+		return err
+	})
+	return func(ctx context.Context) error {
+		stop()
+		_, err := locks.DeleteOne(ctx, bson.D{{Key: "_id", Value: "lock"}, {Key: "owner", Value: owner}})
+		return err
+	}, nil
+}
+
+// Applied implements migrate.Store.
+func (s *MigrationStore) Applied(ctx context.Context) ([]migrate.Record, error) {
+	cursor, err := s.db.Collection(s.collection).Find(ctx, bson.D{},
+		options.Find().SetSort(bson.D{{Key: "version", Value: 1}}))
+	if err != nil {
+		return nil, fmt.Errorf("database/mongodb: failed to read collection %s: %w", s.collection, err)
+	}
+	var records []migrate.Record
+	if err := cursor.All(ctx, &records); err != nil {
+		return nil, fmt.Errorf("database/mongodb: failed to read collection %s: %w", s.collection, err)
+	}
+	return records, nil
+}
+
+// Apply implements migrate.Store.
+func (s *MigrationStore) Apply(ctx context.Context, m migrate.Migration) error {
+	if err := s.run(ctx, m.UpSQL, m.Up); err != nil {
+		return err
+	}
+	_, err := s.db.Collection(s.collection).InsertOne(ctx, migrate.Record{
+		Version:   m.Version,
+		Name:      m.Name,
+		AppliedAt: time.Now().UTC(),
+	})
+	return err
+}
+
+// Revert implements migrate.Store.
+func (s *MigrationStore) Revert(ctx context.Context, m migrate.Migration) error {
+	if err := s.run(ctx, m.DownSQL, m.Down); err != nil {
+		return err
+	}
+	_, err := s.db.Collection(s.collection).DeleteOne(ctx, bson.D{{Key: "version", Value: m.Version}})
+	return err
+}
+
+func (s *MigrationStore) run(ctx context.Context, query string, fn migrate.Func) error {
+	switch {
+	case fn != nil:
+		return fn(ctx, s.db)
+	case query != "":
+		return errors.New("database/mongodb: sql migrations are not supported")
+	default:
+		return errors.New("database/mongodb: migration has nothing to run")
+	}
+}
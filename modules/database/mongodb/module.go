@@ -6,3 +6,13 @@ var DatabaseMongoDBModule = fx.Module("liquor-database-mongodb", fx.Provide(
 	NewConnection,
 	UseDatabase,
 ))
+
+// MigrationsModule provides the migrate.Store used by app.RegisterMigrations.
+var MigrationsModule = fx.Module("liquor-database-mongodb-migrations", fx.Provide(
+	NewMigrationStore,
+))
+
+// SeedsModule provides the seed.Store used by app.RegisterSeeds.
+var SeedsModule = fx.Module("liquor-database-mongodb-seeds", fx.Provide(
+	NewSeedStore,
+))
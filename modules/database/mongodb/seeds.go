@@ -0,0 +1,69 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-liquor/liquor-sdk/seed"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// SeedStore is the seed.Store of mongodb. Fixture tables are collections and
+// Go seeders receive the *mongo.Database.
+type SeedStore struct {
+	db *mongo.Database
+}
+
+// NewSeedStore create the store of the seeders (see app.RegisterSeeds)
+//
+// Returns:
+// - seed.Store: a store upserting the fixtures into collections
+func NewSeedStore(db *mongo.Database) seed.Store {
+	return &SeedStore{db: db}
+}
+
+// Handle implements seed.Store.
+func (s *SeedStore) Handle() any {
+	return s.db
+}
+
+// Upsert implements seed.Store. The key defaults to `_id` when the rows have one, `id` otherwise.
+func (s *SeedStore) Upsert(ctx context.Context, collection string, key []string, rows []map[string]any) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	if len(key) == 0 {
+		key = []string{"id"}
+		if _, ok := rows[0]["_id"]; ok {
+			key = []string{"_id"}
+		}
+	}
+
+	models := make([]mongo.WriteModel, 0, len(rows))
+	for _, row := range rows {
+		filter := bson.D{}
+		for _, k := range key {
+			filter = append(filter, bson.E{Key: k, Value: row[k]})
+		}
+		models = append(models, mongo.NewReplaceOneModel().
+			SetFilter(filter).
+			SetReplacement(bson.M(row)).
+			SetUpsert(true))
+	}
+	if _, err := s.db.Collection(collection).BulkWrite(ctx, models, options.BulkWrite().SetOrdered(true)); err != nil {
+		return fmt.Errorf("database/mongodb: failed to upsert into %s: %w", collection, err)
+	}
+	return nil
+}
+
+// Truncate implements seed.Store.
+func (s *SeedStore) Truncate(ctx context.Context, collections ...string) error {
+	for _, collection := range collections {
+		if _, err := s.db.Collection(collection).DeleteMany(ctx, bson.D{}); err != nil {
+			return fmt.Errorf("database/mongodb: failed to truncate %s: %w", collection, err)
+		}
+	}
+	return nil
+}
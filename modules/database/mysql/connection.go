@@ -2,6 +2,7 @@ package mysql
 
 import (
 	"database/sql"
+	"fmt"
 
 	"github.com/go-liquor/liquor-sdk/config"
 	_ "github.com/go-sql-driver/mysql"
@@ -14,12 +15,17 @@ import (
 //
 // Returns:
 // - *bun.DB: a new connection to mysql database
-func NewConnection(config *config.Config, logger *zap.Logger) *bun.DB {
-	sqldb, err := sql.Open("mysql", config.GetString("database.mysql.dsn"))
+// - error: nil if successful, error otherwise
+func NewConnection(config *config.Config, logger *zap.Logger) (*bun.DB, error) {
+	dsn := config.GetString("database.mysql.dsn")
+	if dsn == "" {
+		return nil, fmt.Errorf("database/mysql: missing config key %q", "database.mysql.dsn")
+	}
+	sqldb, err := sql.Open("mysql", dsn)
 	if err != nil {
-		logger.Fatal("failed to connect in database", zap.Error(err))
+		return nil, fmt.Errorf("database/mysql: failed to connect in database using %q: %w", "database.mysql.dsn", err)
 	}
 
 	db := bun.NewDB(sqldb, mysqldialect.New())
-	return db
+	return db, nil
 }
@@ -1,7 +1,36 @@
 package mysql
 
-import "go.uber.org/fx"
+import (
+	"github.com/go-liquor/liquor-sdk/app"
+	"go.uber.org/fx"
+)
 
 var DatabaseMysqlModule = fx.Module("liquor-database-mysql", fx.Provide(
 	NewConnection,
 ))
+
+// MigrationsModule provides the migrate.Store used by app.RegisterMigrations.
+var MigrationsModule = fx.Module("liquor-database-mysql-migrations", fx.Provide(
+	NewMigrationStore,
+))
+
+// SeedsModule provides the seed.Store used by app.RegisterSeeds.
+var SeedsModule = fx.Module("liquor-database-mysql-seeds", fx.Provide(
+	NewSeedStore,
+))
+
+// QueueBackendModule provides the queue.Backend used by queue.QueueModule.
+var QueueBackendModule = fx.Module("liquor-database-mysql-queue", fx.Provide(
+	NewQueueBackend,
+))
+
+// OutboxModule provides the *outbox.Outbox, also as the app.EventOutbox of the app.ToOutbox subscribers.
+var OutboxModule = fx.Module("liquor-database-mysql-outbox", fx.Provide(
+	fx.Annotate(NewOutbox, fx.As(fx.Self()), fx.As(new(app.EventOutbox))),
+))
+
+// TenantPoolModule provides the *tenancy.Pool of the tenants with a database, with
+// tenancy.TenancyModule.
+var TenantPoolModule = fx.Module("liquor-database-mysql-tenants", fx.Provide(
+	NewTenantPool,
+))
@@ -0,0 +1,19 @@
+package mysql
+
+import (
+	"github.com/go-liquor/liquor-sdk/config"
+	"github.com/go-liquor/liquor-sdk/queue"
+	"github.com/uptrace/bun"
+)
+
+// NewQueueBackend create the backend of the job queue (see queue.QueueModule)
+//
+// Config keys:
+// - queue.sql.table: the table of the jobs (default liquor_jobs)
+// - queue.visibilityTimeout: how long a running job is reserved (default 5m)
+//
+// Returns:
+// - queue.Backend: a backend storing the jobs in a table of the mysql database
+func NewQueueBackend(db *bun.DB, cfg *config.Config) queue.Backend {
+	return queue.NewSQLBackend(db, cfg.GetString("queue.sql.table"), cfg.GetDuration("queue.visibilityTimeout"))
+}
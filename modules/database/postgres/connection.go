@@ -2,6 +2,7 @@ package postgres
 
 import (
 	"database/sql"
+	"fmt"
 
 	"github.com/go-liquor/liquor-sdk/config"
 	"github.com/uptrace/bun"
@@ -14,9 +15,14 @@ import (
 //
 // Returns:
 // - *bun.DB: a new connection to postgres database
-func NewConnection(config *config.Config, logger *zap.Logger) *bun.DB {
-	sqldb := sql.OpenDB(pgdriver.NewConnector(pgdriver.WithDSN(config.GetString("database.postgres.dns"))))
+// - error: nil if successful, error otherwise
+func NewConnection(config *config.Config, logger *zap.Logger) (*bun.DB, error) {
+	dsn := config.GetString("database.postgres.dns")
+	if dsn == "" {
+		return nil, fmt.Errorf("database/postgres: missing config key %q", "database.postgres.dns")
+	}
+	sqldb := sql.OpenDB(pgdriver.NewConnector(pgdriver.WithDSN(dsn)))
 
 	db := bun.NewDB(sqldb, pgdialect.New())
-	return db
+	return db, nil
 }
@@ -0,0 +1,21 @@
+package postgres
+
+import (
+	"github.com/go-liquor/liquor-sdk/config"
+	"github.com/go-liquor/liquor-sdk/outbox"
+	"github.com/uptrace/bun"
+	"go.uber.org/fx"
+)
+
+// NewOutbox create the transactional outbox (see outbox.RelayModule), its table is created on start
+//
+// Config keys:
+// - outbox.table: the table of the outbox (default liquor_outbox)
+//
+// Returns:
+// - *outbox.Outbox: an outbox writing the events in the transactions of the postgres database
+func NewOutbox(db *bun.DB, cfg *config.Config, lc fx.Lifecycle) *outbox.Outbox {
+	box := outbox.NewOutbox(db, cfg.GetString("outbox.table"))
+	lc.Append(fx.StartHook(box.Init))
+	return box
+}
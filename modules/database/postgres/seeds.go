@@ -0,0 +1,14 @@
+package postgres
+
+import (
+	"github.com/go-liquor/liquor-sdk/seed"
+	"github.com/uptrace/bun"
+)
+
+// NewSeedStore create the store of the seeders (see app.RegisterSeeds)
+//
+// Returns:
+// - seed.Store: a store upserting the fixtures with the postgres dialect
+func NewSeedStore(db *bun.DB) seed.Store {
+	return seed.NewSQLStore(db)
+}
@@ -2,6 +2,7 @@ package sqlite
 
 import (
 	"database/sql"
+	"fmt"
 
 	"github.com/go-liquor/liquor-sdk/config"
 	"github.com/uptrace/bun"
@@ -14,12 +15,17 @@ import (
 //
 // Returns:
 // - *bun.DB: a new connection to sqlite database
-func NewConnection(config *config.Config, logger *zap.Logger) *bun.DB {
-	sqldb, err := sql.Open(sqliteshim.ShimName, config.GetString("database.sqlite.dsn"))
+// - error: nil if successful, error otherwise
+func NewConnection(config *config.Config, logger *zap.Logger) (*bun.DB, error) {
+	dsn := config.GetString("database.sqlite.dsn")
+	if dsn == "" {
+		return nil, fmt.Errorf("database/sqlite: missing config key %q", "database.sqlite.dsn")
+	}
+	sqldb, err := sql.Open(sqliteshim.ShimName, dsn)
 	if err != nil {
-		logger.Fatal("failed to connect in database", zap.Error(err))
+		return nil, fmt.Errorf("database/sqlite: failed to connect in database using %q: %w", "database.sqlite.dsn", err)
 	}
 
 	db := bun.NewDB(sqldb, sqlitedialect.New())
-	return db
+	return db, nil
 }
@@ -0,0 +1,15 @@
+package sqlite
+
+import (
+	"github.com/go-liquor/liquor-sdk/config"
+	"github.com/go-liquor/liquor-sdk/migrate"
+	"github.com/uptrace/bun"
+)
+
+// NewMigrationStore create the store of the versioned migrations (see app.RegisterMigrations)
+//
+// Returns:
+// - migrate.Store: a store tracking the migrations in `database.migrations.table`
+func NewMigrationStore(db *bun.DB, config *config.Config) migrate.Store {
+	return migrate.NewSQLStore(db, config.GetString("database.migrations.table"), config.GetDuration("database.migrations.lockTTL"))
+}
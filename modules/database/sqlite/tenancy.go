@@ -0,0 +1,30 @@
+package sqlite
+
+import (
+	"context"
+
+	"github.com/go-liquor/liquor-sdk/config"
+	"github.com/go-liquor/liquor-sdk/tenancy"
+	"github.com/uptrace/bun"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// NewTenantPool create the connections of the tenants (see tenancy.Pool)
+//
+// Config keys:
+// - tenants.<id>.database.sqlite.dsn: the database of a tenant, the others share the connection of the app
+//
+// Returns:
+// - *tenancy.Pool: the connections, closed when the app stops
+func NewTenantPool(t *tenancy.Tenancy, db *bun.DB, logger *zap.Logger, lc fx.Lifecycle) *tenancy.Pool {
+	pool := tenancy.NewPool(t, db, func(cfg *config.Config) (*bun.DB, error) {
+		return NewConnection(cfg, logger)
+	})
+	lc.Append(fx.Hook{
+		OnStop: func(ctx context.Context) error {
+			return pool.Close()
+		},
+	})
+	return pool
+}
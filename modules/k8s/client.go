@@ -1,6 +1,7 @@
 package k8s
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 
@@ -12,7 +13,7 @@ import (
 )
 
 // NewClient creates a new Kubernetes clientset using the provided configuration.
-// It will log a fatal error if the client creation fails.
+// It returns an error if the client creation fails.
 //
 // Parameters:
 //   - logger: A zap logger instance for error logging
@@ -20,12 +21,13 @@ import (
 //
 // Returns:
 //   - *kubernetes.Clientset: A Kubernetes client for interacting with the cluster
-func NewClient(logger *zap.Logger, config *rest.Config) *kubernetes.Clientset {
+//   - error: nil if successful, error otherwise
+func NewClient(logger *zap.Logger, config *rest.Config) (*kubernetes.Clientset, error) {
 	clientset, err := kubernetes.NewForConfig(config)
 	if err != nil {
-		logger.Fatal("error to create client For Config", zap.Error(err))
+		return nil, fmt.Errorf("k8s: error to create client For Config (host %s): %w", config.Host, err)
 	}
-	return clientset
+	return clientset, nil
 }
 
 // NewRestConfig creates a new Kubernetes REST configuration.
@@ -37,22 +39,24 @@ func NewClient(logger *zap.Logger, config *rest.Config) *kubernetes.Clientset {
 //
 // Returns:
 //   - *rest.Config: The Kubernetes REST configuration
-func NewRestConfig(logger *zap.Logger) *rest.Config {
+//   - error: nil if successful, error otherwise
+func NewRestConfig(logger *zap.Logger) (*rest.Config, error) {
 	config, err := rest.InClusterConfig()
 	if err != nil {
 		logger.Warn("Unable to use kubernetes config through cluster, trying local configuration", zap.Error(err))
 		home, _ := os.UserHomeDir()
-		config, err = clientcmd.BuildConfigFromFlags("", filepath.Join(home, ".kube", "config"))
+		kubeconfig := filepath.Join(home, ".kube", "config")
+		config, err = clientcmd.BuildConfigFromFlags("", kubeconfig)
 		if err != nil {
-			logger.Fatal("error to create config", zap.Error(err))
+			return nil, fmt.Errorf("k8s: error to create config from in-cluster or %s: %w", kubeconfig, err)
 		}
 	}
-	return config
+	return config, nil
 }
 
 // NewDynamicClient creates a new Kubernetes dynamic client using the provided configuration.
 // Dynamic client allows interaction with CRDs and other dynamic resources.
-// It will log a fatal error if the client creation fails.
+// It returns an error if the client creation fails.
 //
 // Parameters:
 //   - logger: A zap logger instance for error logging
@@ -60,10 +64,11 @@ func NewRestConfig(logger *zap.Logger) *rest.Config {
 //
 // Returns:
 //   - *dynamic.DynamicClient: A dynamic client for interacting with the cluster
-func NewDynamicClient(logger *zap.Logger, config *rest.Config) *dynamic.DynamicClient {
+//   - error: nil if successful, error otherwise
+func NewDynamicClient(logger *zap.Logger, config *rest.Config) (*dynamic.DynamicClient, error) {
 	clientset, err := dynamic.NewForConfig(config)
 	if err != nil {
-		logger.Fatal("error to create DynamicClient For Client", zap.Error(err))
+		return nil, fmt.Errorf("k8s: error to create DynamicClient For Client (host %s): %w", config.Host, err)
 	}
-	return clientset
+	return clientset, nil
 }
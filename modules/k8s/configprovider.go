@@ -0,0 +1,146 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/go-liquor/liquor-sdk/config"
+	"gopkg.in/yaml.v3"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+)
+
+const serviceAccountNamespace = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+
+// ConfigMapProvider is a config.Provider of a ConfigMap, watched through the API server:
+// unlike a mounted ConfigMap, the update is seen immediately, without waiting for the
+// kubelet sync.
+type ConfigMapProvider struct {
+	client    kubernetes.Interface
+	namespace string
+	name      string
+	key       string
+}
+
+// NewConfigMapProvider creates the provider of the configuration ConfigMap.
+//
+// Config keys:
+//   - config.k8s.configMap.name: The name of the ConfigMap (required)
+//   - config.k8s.configMap.namespace: Its namespace (default: POD_NAMESPACE, then the namespace of the pod)
+//   - config.k8s.configMap.key: The entry holding the YAML or JSON configuration (default config.yaml)
+//
+// Parameters:
+//   - client: The Kubernetes client
+//   - cfg: Configuration
+//
+// Returns:
+//   - *ConfigMapProvider: The provider
+//   - error: nil if successful, error if the name is missing
+func NewConfigMapProvider(client kubernetes.Interface, cfg *config.Config) (*ConfigMapProvider, error) {
+	p := &ConfigMapProvider{
+		client:    client,
+		namespace: cfg.GetString("config.k8s.configMap.namespace"),
+		name:      cfg.GetString("config.k8s.configMap.name"),
+		key:       cfg.GetString("config.k8s.configMap.key"),
+	}
+	if p.name == "" {
+		return nil, fmt.Errorf("k8s: config.k8s.configMap.name is not set")
+	}
+	if p.namespace == "" {
+		p.namespace = podNamespace()
+	}
+	if p.key == "" {
+		p.key = "config.yaml"
+	}
+	return p, nil
+}
+
+// Name implements config.Provider.
+func (p *ConfigMapProvider) Name() string {
+	return "configmap:" + p.namespace + "/" + p.name
+}
+
+// Fetch implements config.Provider.
+func (p *ConfigMapProvider) Fetch(ctx context.Context) (map[string]interface{}, error) {
+	values, _, err := p.get(ctx)
+	return values, err
+}
+
+// Watch implements config.WatchingProvider. It returns when the API server closes the
+// watch, to be restarted.
+func (p *ConfigMapProvider) Watch(ctx context.Context, update func(values map[string]interface{})) error {
+	values, version, err := p.get(ctx)
+	if err != nil {
+		return err
+	}
+	// the changes since the fetch
+	update(values)
+	stream, err := p.client.CoreV1().ConfigMaps(p.namespace).Watch(ctx, metav1.ListOptions{
+		FieldSelector:   "metadata.name=" + p.name,
+		ResourceVersion: version,
+	})
+	if err != nil {
+		return fmt.Errorf("k8s: error to watch ConfigMap %s/%s: %w", p.namespace, p.name, err)
+	}
+	defer stream.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-stream.ResultChan():
+			if !ok {
+				return nil
+			}
+			cm, isConfigMap := event.Object.(*corev1.ConfigMap)
+			if !isConfigMap {
+				// an expired version ends the watch with a Status
+				return fmt.Errorf("k8s: watch of ConfigMap %s/%s expired", p.namespace, p.name)
+			}
+			if event.Type != watch.Added && event.Type != watch.Modified {
+				continue
+			}
+			values, err := p.decode(cm)
+			if err != nil {
+				return err
+			}
+			update(values)
+		}
+	}
+}
+
+func (p *ConfigMapProvider) get(ctx context.Context) (map[string]interface{}, string, error) {
+	cm, err := p.client.CoreV1().ConfigMaps(p.namespace).Get(ctx, p.name, metav1.GetOptions{})
+	if err != nil {
+		return nil, "", fmt.Errorf("k8s: error to get ConfigMap %s/%s: %w", p.namespace, p.name, err)
+	}
+	values, err := p.decode(cm)
+	return values, cm.ResourceVersion, err
+}
+
+func (p *ConfigMapProvider) decode(cm *corev1.ConfigMap) (map[string]interface{}, error) {
+	data, ok := cm.Data[p.key]
+	if !ok {
+		return nil, fmt.Errorf("k8s: ConfigMap %s/%s has no %s entry", p.namespace, p.name, p.key)
+	}
+	values := map[string]interface{}{}
+	if err := yaml.Unmarshal([]byte(data), &values); err != nil {
+		return nil, fmt.Errorf("k8s: invalid %s of ConfigMap %s/%s: %w", p.key, p.namespace, p.name, err)
+	}
+	return values, nil
+}
+
+// podNamespace returns the namespace of the pod: POD_NAMESPACE, then the namespace of
+// the service account, then "default".
+func podNamespace() string {
+	if ns := os.Getenv("POD_NAMESPACE"); ns != "" {
+		return ns
+	}
+	if ns, err := os.ReadFile(serviceAccountNamespace); err == nil && len(strings.TrimSpace(string(ns))) > 0 {
+		return strings.TrimSpace(string(ns))
+	}
+	return "default"
+}
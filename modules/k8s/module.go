@@ -1,5 +1,24 @@
 package k8s
 
-import "go.uber.org/fx"
+import (
+	"github.com/go-liquor/liquor-sdk/config"
+	"go.uber.org/fx"
+	"k8s.io/client-go/kubernetes"
+)
 
 var K8sModule = fx.Module("liquor-module-k8s", fx.Provide())
+
+// ConfigMapProviderModule merges the ConfigMap of `config.k8s.configMap.name` over the
+// config files and reloads the configuration on its updates (see ConfigMapProvider).
+var ConfigMapProviderModule = fx.Module("liquor-module-k8s-configmap-provider",
+	fx.Provide(
+		fx.Private,
+		NewRestConfig,
+		fx.Annotate(NewClient, fx.As(new(kubernetes.Interface))),
+	),
+	config.RegisterProviders(NewConfigMapProvider),
+)
+
+// SecretResolverModule resolves the ${k8s-secret:...} references of the configuration
+// (see SecretResolver).
+var SecretResolverModule = config.RegisterResolvers(NewSecretResolver)
@@ -0,0 +1,77 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/go-liquor/liquor-sdk/config"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// SecretResolver resolves the ${k8s-secret:<namespace>/<name>#<key>} references of the
+// configuration to the entries of Kubernetes Secrets. Without namespace
+// (${k8s-secret:db#password}), the Secret is read in the namespace of the pod.
+//
+// The client is created on the first reference, from the in-cluster configuration
+// (or ~/.kube/config).
+type SecretResolver struct {
+	mu     sync.Mutex
+	client kubernetes.Interface
+}
+
+// NewSecretResolver creates the "k8s-secret" resolver.
+//
+// Returns:
+//   - *SecretResolver: The resolver
+func NewSecretResolver() *SecretResolver {
+	return &SecretResolver{}
+}
+
+// Scheme implements config.SecretResolver.
+func (r *SecretResolver) Scheme() string {
+	return "k8s-secret"
+}
+
+// Resolve implements config.SecretResolver.
+func (r *SecretResolver) Resolve(ctx context.Context, _ *config.Config, ref string) (string, error) {
+	r.mu.Lock()
+	if r.client == nil {
+		restConfig, err := NewRestConfig(zap.NewNop())
+		if err != nil {
+			r.mu.Unlock()
+			return "", err
+		}
+		client, err := NewClient(zap.NewNop(), restConfig)
+		if err != nil {
+			r.mu.Unlock()
+			return "", err
+		}
+		r.client = client
+	}
+	client := r.client
+	r.mu.Unlock()
+
+	path, key, ok := strings.Cut(ref, "#")
+	if !ok || key == "" {
+		return "", fmt.Errorf("k8s: the secret reference %s has no #key", ref)
+	}
+	namespace, name, ok := strings.Cut(path, "/")
+	if !ok {
+		namespace, name = podNamespace(), path
+	}
+	secret, err := client.CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("k8s: error to get Secret %s/%s: %w", namespace, name, err)
+	}
+	if value, ok := secret.Data[key]; ok {
+		return string(value), nil
+	}
+	if value, ok := secret.StringData[key]; ok {
+		return value, nil
+	}
+	return "", fmt.Errorf("k8s: Secret %s/%s has no %s entry", namespace, name, key)
+}
@@ -8,6 +8,9 @@ import (
 	goredis "github.com/redis/go-redis/v9"
 )
 
+// Nil is the error returned when popping an empty list.
+var Nil = goredis.Nil
+
 // RedisClient defines the interface for Redis operations
 type RedisClient interface {
 	Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error
@@ -26,6 +29,28 @@ type RedisClient interface {
 	RPop(ctx context.Context, key string) (string, error)
 }
 
+// Commands defines the Redis operations of the Locker and the OutboxPublisher that aren't
+// in RedisClient, so the implementations and mocks of RedisClient keep compiling. The
+// clients of NewRedisClient and NewInMemoryRedis implement it, and RedisModule provides it.
+type Commands interface {
+	SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error)
+	LRange(ctx context.Context, key string, start, stop int64) ([]string, error)
+	XAdd(ctx context.Context, stream string, values map[string]interface{}) (string, error)
+}
+
+// PubSub defines the interface for Redis pub/sub operations. The clients of NewRedisClient
+// and NewInMemoryRedis implement it, and RedisModule provides it.
+type PubSub interface {
+	Publish(ctx context.Context, channel string, message interface{}) error
+	Subscribe(ctx context.Context, channel string, handler func(payload string)) error
+}
+
+// Scripter runs Lua scripts on the server, e.g. to update several keys in one step. The
+// client of NewRedisClient implements it.
+type Scripter interface {
+	Eval(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error)
+}
+
 type redisClient struct {
 	client *goredis.Client
 }
@@ -61,6 +86,21 @@ func (r *redisClient) Set(ctx context.Context, key string, value interface{}, ex
 	return r.client.Set(ctx, key, value, expiration).Err()
 }
 
+// SetNX stores a key-value pair only if the key does not exist.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - key: Key to store
+//   - value: Value to store
+//   - expiration: Time until the key expires (0 for no expiration)
+//
+// Returns:
+//   - bool: true if the key was set, false if it already exists
+//   - error: nil if successful, error otherwise
+func (r *redisClient) SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error) {
+	return r.client.SetNX(ctx, key, value, expiration).Result()
+}
+
 // Get retrieves a value by its key.
 //
 // Parameters:
@@ -201,7 +241,7 @@ func (r *redisClient) LPush(ctx context.Context, key string, values ...interface
 //
 // Returns:
 //   - string: The popped element
-//   - error: nil if successful, error otherwise
+//   - error: Nil if the list is empty, error otherwise
 func (r *redisClient) LPop(ctx context.Context, key string) (string, error) {
 	return r.client.LPop(ctx, key).Result()
 }
@@ -227,7 +267,94 @@ func (r *redisClient) RPush(ctx context.Context, key string, values ...interface
 //
 // Returns:
 //   - string: The popped element
-//   - error: nil if successful, error otherwise
+//   - error: Nil if the list is empty, error otherwise
 func (r *redisClient) RPop(ctx context.Context, key string) (string, error) {
 	return r.client.RPop(ctx, key).Result()
 }
+
+// LRange returns the elements of a list between two indexes (negative indexes count from the tail).
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - key: List key
+//   - start: Index of the first element
+//   - stop: Index of the last element (-1 for the tail)
+//
+// Returns:
+//   - []string: The elements
+//   - error: nil if successful, error otherwise
+func (r *redisClient) LRange(ctx context.Context, key string, start, stop int64) ([]string, error) {
+	return r.client.LRange(ctx, key, start, stop).Result()
+}
+
+// XAdd appends an entry to a stream.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - stream: Stream key
+//   - values: Fields and values of the entry
+//
+// Returns:
+//   - string: The ID of the entry
+//   - error: nil if successful, error otherwise
+func (r *redisClient) XAdd(ctx context.Context, stream string, values map[string]interface{}) (string, error) {
+	return r.client.XAdd(ctx, &goredis.XAddArgs{Stream: stream, Values: values}).Result()
+}
+
+// Publish posts a message to a pub/sub channel.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - channel: Channel name
+//   - message: Message to publish
+//
+// Returns:
+//   - error: nil if successful, error otherwise
+func (r *redisClient) Publish(ctx context.Context, channel string, message interface{}) error {
+	return r.client.Publish(ctx, channel, message).Err()
+}
+
+// Subscribe listens to a pub/sub channel and calls handler for every message
+// until the context is canceled.
+//
+// Parameters:
+//   - ctx: Context controlling the subscription
+//   - channel: Channel name
+//   - handler: Function called with each message payload
+//
+// Returns:
+//   - error: nil when the context is canceled, error otherwise
+func (r *redisClient) Subscribe(ctx context.Context, channel string, handler func(payload string)) error {
+	sub := r.client.Subscribe(ctx, channel)
+	defer sub.Close()
+	if _, err := sub.Receive(ctx); err != nil {
+		return err
+	}
+	messages := sub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case msg, ok := <-messages:
+			if !ok {
+				return nil
+			}
+			handler(msg.Payload)
+		}
+	}
+}
+
+// Eval runs a Lua script atomically.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - script: Lua script
+//   - keys: Keys of the script (KEYS)
+//   - args: Arguments of the script (ARGV)
+//
+// Returns:
+//   - interface{}: The result of the script
+//   - error: Nil if the script returns nil or false, error otherwise
+func (r *redisClient) Eval(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error) {
+	return r.client.Eval(ctx, script, keys, args...).Result()
+}
@@ -3,6 +3,7 @@ package redis
 import (
 	"context"
 	"errors"
+	"fmt"
 	"sync"
 	"time"
 )
@@ -16,6 +17,8 @@ type inMemoryRedis struct {
 	data       map[string]inMemoryItem
 	hashData   map[string]map[string]string
 	listData   map[string][]string
+	streamData map[string][]map[string]string
+	subs       map[string][]chan string
 	mu         sync.RWMutex
 	cleanupInt time.Duration
 }
@@ -26,6 +29,8 @@ func NewInMemoryRedis() RedisClient {
 		data:       make(map[string]inMemoryItem),
 		hashData:   make(map[string]map[string]string),
 		listData:   make(map[string][]string),
+		streamData: make(map[string][]map[string]string),
+		subs:       make(map[string][]chan string),
 		cleanupInt: time.Minute,
 	}
 	go r.cleanup()
@@ -63,6 +68,27 @@ func (r *inMemoryRedis) Set(_ context.Context, key string, value interface{}, ex
 	return nil
 }
 
+func (r *inMemoryRedis) SetNX(_ context.Context, key string, value interface{}, expiration time.Duration) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if item, exists := r.data[key]; exists && (item.expiration == nil || time.Now().Before(*item.expiration)) {
+		return false, nil
+	}
+
+	var exp *time.Time
+	if expiration > 0 {
+		t := time.Now().Add(expiration)
+		exp = &t
+	}
+
+	r.data[key] = inMemoryItem{
+		value:      value,
+		expiration: exp,
+	}
+	return true, nil
+}
+
 func (r *inMemoryRedis) Get(_ context.Context, key string) (string, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
@@ -217,7 +243,7 @@ func (r *inMemoryRedis) LPop(_ context.Context, key string) (string, error) {
 		r.listData[key] = list[1:]
 		return value, nil
 	}
-	return "", errors.New("list is empty")
+	return "", Nil
 }
 
 func (r *inMemoryRedis) RPush(_ context.Context, key string, values ...interface{}) error {
@@ -244,5 +270,84 @@ func (r *inMemoryRedis) RPop(_ context.Context, key string) (string, error) {
 		r.listData[key] = list[:lastIdx]
 		return value, nil
 	}
-	return "", errors.New("list is empty")
+	return "", Nil
+}
+
+func (r *inMemoryRedis) LRange(_ context.Context, key string, start, stop int64) ([]string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	list := r.listData[key]
+	size := int64(len(list))
+	if start < 0 {
+		start = max(size+start, 0)
+	}
+	if stop < 0 {
+		stop = size + stop
+	}
+	stop = min(stop, size-1)
+	if start > stop {
+		return []string{}, nil
+	}
+	return append([]string(nil), list[start:stop+1]...), nil
+}
+
+func (r *inMemoryRedis) XAdd(_ context.Context, stream string, values map[string]interface{}) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry := make(map[string]string, len(values))
+	for k, v := range values {
+		entry[k] = fmt.Sprint(v)
+		if b, ok := v.([]byte); ok {
+			entry[k] = string(b)
+		}
+	}
+	r.streamData[stream] = append(r.streamData[stream], entry)
+	return fmt.Sprintf("%d-%d", time.Now().UnixMilli(), len(r.streamData[stream])), nil
+}
+
+func (r *inMemoryRedis) Publish(_ context.Context, channel string, message interface{}) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	payload := fmt.Sprint(message)
+	if b, ok := message.([]byte); ok {
+		payload = string(b)
+	}
+	for _, ch := range r.subs[channel] {
+		select {
+		case ch <- payload:
+		default:
+		}
+	}
+	return nil
+}
+
+func (r *inMemoryRedis) Subscribe(ctx context.Context, channel string, handler func(payload string)) error {
+	ch := make(chan string, 100)
+	r.mu.Lock()
+	r.subs[channel] = append(r.subs[channel], ch)
+	r.mu.Unlock()
+
+	defer func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		subs := r.subs[channel]
+		for i, c := range subs {
+			if c == ch {
+				r.subs[channel] = append(subs[:i], subs[i+1:]...)
+				break
+			}
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case payload := <-ch:
+			handler(payload)
+		}
+	}
 }
@@ -1,5 +1,43 @@
 package redis
 
-import "go.uber.org/fx"
+import (
+	"fmt"
 
-var RedisModule = fx.Module("liquor-redis-module", fx.Provide(NewRedisClient))
+	"github.com/go-liquor/liquor-sdk/outbox"
+	"github.com/go-liquor/liquor-sdk/queue"
+	"github.com/go-liquor/liquor-sdk/scheduler"
+	"go.uber.org/fx"
+)
+
+var RedisModule = fx.Module("liquor-redis-module", fx.Provide(NewRedisClient, newPubSub, newCommands))
+
+// newPubSub provides the pub/sub operations of the RedisClient.
+func newPubSub(client RedisClient) (PubSub, error) {
+	pubsub, ok := client.(PubSub)
+	if !ok {
+		return nil, fmt.Errorf("redis: %T doesn't implement redis.PubSub", client)
+	}
+	return pubsub, nil
+}
+
+// newCommands provides the operations of the RedisClient missing from its interface.
+func newCommands(client RedisClient) (Commands, error) {
+	commands, ok := client.(Commands)
+	if !ok {
+		return nil, fmt.Errorf("redis: %T doesn't implement redis.Commands", client)
+	}
+	return commands, nil
+}
+
+// LockerModule provides the Locker as the scheduler.Locker of the jobs registered WithLock.
+var LockerModule = fx.Module("liquor-redis-locker", fx.Provide(
+	fx.Annotate(NewLocker, fx.As(new(scheduler.Locker))),
+))
+
+// QueueBackendModule provides the QueueBackend as the queue.Backend of queue.QueueModule.
+var QueueBackendModule = fx.Module("liquor-redis-queue", fx.Provide(
+	fx.Annotate(NewQueueBackend, fx.As(new(queue.Backend))),
+))
+
+// OutboxPublisherModule provides the "redis" publisher of outbox.RelayModule.
+var OutboxPublisherModule = outbox.RegisterPublishers(NewOutboxPublisher)
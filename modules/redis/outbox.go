@@ -0,0 +1,52 @@
+package redis
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/go-liquor/liquor-sdk/config"
+	"github.com/go-liquor/liquor-sdk/outbox"
+)
+
+// OutboxPublisher delivers the messages of the outbox to Redis streams. An entry has the
+// fields id, topic, key and payload.
+type OutboxPublisher struct {
+	client Commands
+	config *config.Config
+}
+
+// NewOutboxPublisher creates the "redis" publisher of the outbox relay.
+//
+// Config keys:
+//   - outbox.topics.<topic>.destination: The stream of a topic (default: the topic)
+//
+// Parameters:
+//   - client: Redis commands (see RedisModule)
+//   - cfg: Configuration
+//
+// Returns:
+//   - *OutboxPublisher: The publisher
+func NewOutboxPublisher(client Commands, cfg *config.Config) *OutboxPublisher {
+	cfg.Uses("outbox.topics")
+	return &OutboxPublisher{client: client, config: cfg}
+}
+
+// Name implements outbox.Publisher.
+func (p *OutboxPublisher) Name() string {
+	return "redis"
+}
+
+// Publish implements outbox.Publisher.
+func (p *OutboxPublisher) Publish(ctx context.Context, msg outbox.Message) error {
+	stream := p.config.GetString("outbox.topics." + msg.Topic + ".destination")
+	if stream == "" {
+		stream = msg.Topic
+	}
+	_, err := p.client.XAdd(ctx, stream, map[string]interface{}{
+		"id":      strconv.FormatInt(msg.ID, 10),
+		"topic":   msg.Topic,
+		"key":     msg.Key,
+		"payload": string(msg.Payload),
+	})
+	return err
+}
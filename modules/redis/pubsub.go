@@ -0,0 +1,55 @@
+package redis
+
+import "context"
+
+// PubSubBroker publishes and receives byte payloads through a Redis pub/sub channel.
+// It satisfies the server/http HubBroker interface, so realtime hubs on every instance
+// receive the messages published by any of them.
+type PubSubBroker struct {
+	client  PubSub
+	channel string
+}
+
+// NewPubSubBroker creates a broker bound to a pub/sub channel.
+//
+// Parameters:
+//   - client: Redis pub/sub client
+//   - channel: Channel name
+//
+// Returns:
+//   - *PubSubBroker: The broker
+//
+// Example:
+//
+//	fx.Provide(func(client redis.PubSub) http.HubBroker {
+//	    return redis.NewPubSubBroker(client, "liquor:realtime")
+//	})
+func NewPubSubBroker(client PubSub, channel string) *PubSubBroker {
+	return &PubSubBroker{client: client, channel: channel}
+}
+
+// Publish sends a payload to every subscriber of the channel.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - payload: The payload
+//
+// Returns:
+//   - error: nil if successful, error otherwise
+func (b *PubSubBroker) Publish(ctx context.Context, payload []byte) error {
+	return b.client.Publish(ctx, b.channel, payload)
+}
+
+// Subscribe calls handler for every payload published on the channel until ctx is canceled.
+//
+// Parameters:
+//   - ctx: Context controlling the subscription
+//   - handler: Function called with each payload
+//
+// Returns:
+//   - error: nil when the context is canceled, error otherwise
+func (b *PubSubBroker) Subscribe(ctx context.Context, handler func(payload []byte)) error {
+	return b.client.Subscribe(ctx, b.channel, func(payload string) {
+		handler([]byte(payload))
+	})
+}
@@ -0,0 +1,325 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/go-liquor/liquor-sdk/config"
+	"github.com/go-liquor/liquor-sdk/queue"
+)
+
+// QueueBackend stores the jobs of the job queue in Redis. For each queue:
+//   - liquor:queue:<name> is the list of the due jobs
+//   - liquor:queue:<name>:pending is a hash of the delayed and running jobs
+//   - liquor:queue:<name>:schedule is a sorted set of the pending jobs, scored by the time
+//     they are due: their run time, or the end of the reservation of the running jobs
+//   - liquor:queue:<name>:dead is the list of the dead letters
+//
+// The pending jobs are moved to the list of due jobs when their time comes; a running
+// job whose reservation expires is moved back, so a job is run at least once. The
+// operations changing several keys run in Lua scripts, so a job is never lost between two
+// of them.
+type QueueBackend struct {
+	client     RedisClient
+	ops        queueOps
+	visibility time.Duration
+}
+
+// NewQueueBackend creates a backend storing the jobs in Redis.
+//
+// Config keys:
+//   - queue.visibilityTimeout: How long a running job is reserved (default 5m)
+//
+// Parameters:
+//   - client: Redis client, a Scripter (see NewRedisClient) or NewInMemoryRedis
+//   - cfg: Configuration
+//
+// Returns:
+//   - *QueueBackend: The backend
+//   - error: nil if successful, error if the client doesn't run scripts
+func NewQueueBackend(client RedisClient, cfg *config.Config) (*QueueBackend, error) {
+	visibility := cfg.GetDuration("queue.visibilityTimeout")
+	if visibility <= 0 {
+		visibility = 5 * time.Minute
+	}
+	var ops queueOps
+	switch c := client.(type) {
+	case Scripter:
+		ops = scriptOps{scripter: c}
+	case *inMemoryRedis:
+		ops = memoryOps{redis: c}
+	default:
+		return nil, fmt.Errorf("redis: the queue needs a client implementing redis.Scripter, got %T", client)
+	}
+	return &QueueBackend{client: client, ops: ops, visibility: visibility}, nil
+}
+
+func queueKey(name string) string {
+	return "liquor:queue:" + name
+}
+
+// promoteBatch is the maximum number of due pending jobs moved to the list of due jobs by
+// a dequeue.
+const promoteBatch = 100
+
+// Enqueue implements queue.Backend.
+func (b *QueueBackend) Enqueue(ctx context.Context, job queue.Job) error {
+	if job.RunAt.After(time.Now()) {
+		return b.schedule(ctx, &job, job.RunAt)
+	}
+	raw, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("redis: failed to encode job %s: %w", job.ID, err)
+	}
+	return b.client.LPush(ctx, queueKey(job.Queue), string(raw))
+}
+
+// Dequeue implements queue.Backend. The due pending jobs are moved to the list of due
+// jobs, then a job is popped and reserved until the visibility timeout, in one step.
+func (b *QueueBackend) Dequeue(ctx context.Context, name string) (*queue.Job, error) {
+	now := time.Now()
+	raw, err := b.ops.dequeue(ctx, queueKey(name), now.UnixMilli(), now.Add(b.visibility).UnixMilli())
+	if errors.Is(err, Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("redis: failed to dequeue from %s: %w", name, err)
+	}
+	var job queue.Job
+	if err := json.Unmarshal([]byte(raw), &job); err != nil {
+		return nil, fmt.Errorf("redis: failed to decode a job of %s: %w", name, err)
+	}
+	return &job, nil
+}
+
+// schedule stores a pending job, due at the given time.
+func (b *QueueBackend) schedule(ctx context.Context, job *queue.Job, at time.Time) error {
+	raw, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("redis: failed to encode job %s: %w", job.ID, err)
+	}
+	return b.ops.schedule(ctx, queueKey(job.Queue), job.ID, string(raw), at.UnixMilli())
+}
+
+// Ack implements queue.Backend.
+func (b *QueueBackend) Ack(ctx context.Context, job *queue.Job) error {
+	return b.ops.remove(ctx, queueKey(job.Queue), job.ID, "")
+}
+
+// Retry implements queue.Backend.
+func (b *QueueBackend) Retry(ctx context.Context, job *queue.Job) error {
+	return b.schedule(ctx, job, job.RunAt)
+}
+
+// DeadLetter implements queue.Backend.
+func (b *QueueBackend) DeadLetter(ctx context.Context, job *queue.Job) error {
+	raw, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("redis: failed to encode job %s: %w", job.ID, err)
+	}
+	return b.ops.remove(ctx, queueKey(job.Queue), job.ID, string(raw))
+}
+
+// DeadLetters implements queue.DeadLetterReader.
+func (b *QueueBackend) DeadLetters(ctx context.Context, name string, limit int) ([]queue.Job, error) {
+	stop := int64(-1)
+	if limit > 0 {
+		stop = int64(limit) - 1
+	}
+	values, err := b.ops.deadLetters(ctx, queueKey(name), stop)
+	if err != nil {
+		return nil, fmt.Errorf("redis: failed to read the dead letters of %s: %w", name, err)
+	}
+	jobs := make([]queue.Job, 0, len(values))
+	for _, raw := range values {
+		var job queue.Job
+		if err := json.Unmarshal([]byte(raw), &job); err != nil {
+			return nil, fmt.Errorf("redis: failed to decode a dead letter of %s: %w", name, err)
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
+
+// queueOps runs the operations of the queue changing several keys in one step. The key is
+// the key of the queue (see queueKey) and the times are unix milliseconds.
+type queueOps interface {
+	// schedule stores a pending job, due at the given time.
+	schedule(ctx context.Context, key, id, raw string, at int64) error
+	// dequeue moves the due pending jobs to the list of due jobs, then pops a job and keeps
+	// it pending until the deadline. It returns Nil when no job is due.
+	dequeue(ctx context.Context, key string, now, deadline int64) (string, error)
+	// remove removes a pending job, and adds the dead letter if any.
+	remove(ctx context.Context, key, id, deadLetter string) error
+	// deadLetters reads the dead letters, up to stop (-1 for all).
+	deadLetters(ctx context.Context, key string, stop int64) ([]string, error)
+}
+
+const scheduleScript = `
+redis.call('HSET', KEYS[1], ARGV[1], ARGV[2])
+redis.call('ZADD', KEYS[2], ARGV[3], ARGV[1])
+return 1
+`
+
+// dequeueScript moves the due pending jobs (ARGV[1] is the time, ARGV[3] the limit), then
+// pops a job and keeps it pending until its reservation deadline (ARGV[2]).
+const dequeueScript = `
+local due = redis.call('ZRANGEBYSCORE', KEYS[3], '-inf', ARGV[1], 'LIMIT', 0, tonumber(ARGV[3]))
+for _, id in ipairs(due) do
+	local raw = redis.call('HGET', KEYS[2], id)
+	if raw then
+		redis.call('LPUSH', KEYS[1], raw)
+		redis.call('HDEL', KEYS[2], id)
+	end
+	redis.call('ZREM', KEYS[3], id)
+end
+local raw = redis.call('RPOP', KEYS[1])
+if not raw then
+	return false
+end
+local id = cjson.decode(raw)['id']
+redis.call('HSET', KEYS[2], id, raw)
+redis.call('ZADD', KEYS[3], ARGV[2], id)
+return raw
+`
+
+const removeScript = `
+redis.call('HDEL', KEYS[1], ARGV[1])
+redis.call('ZREM', KEYS[2], ARGV[1])
+if ARGV[2] ~= '' then
+	redis.call('LPUSH', KEYS[3], ARGV[2])
+end
+return 1
+`
+
+const deadLettersScript = `
+return redis.call('LRANGE', KEYS[1], 0, tonumber(ARGV[1]))
+`
+
+// scriptOps runs the operations of the queue in Lua scripts.
+type scriptOps struct {
+	scripter Scripter
+}
+
+func (o scriptOps) schedule(ctx context.Context, key, id, raw string, at int64) error {
+	_, err := o.scripter.Eval(ctx, scheduleScript, []string{key + ":pending", key + ":schedule"}, id, raw, at)
+	return err
+}
+
+func (o scriptOps) dequeue(ctx context.Context, key string, now, deadline int64) (string, error) {
+	result, err := o.scripter.Eval(ctx, dequeueScript, []string{key, key + ":pending", key + ":schedule"}, now, deadline, promoteBatch)
+	if err != nil {
+		return "", err
+	}
+	raw, ok := result.(string)
+	if !ok {
+		return "", fmt.Errorf("unexpected result %T", result)
+	}
+	return raw, nil
+}
+
+func (o scriptOps) remove(ctx context.Context, key, id, deadLetter string) error {
+	_, err := o.scripter.Eval(ctx, removeScript, []string{key + ":pending", key + ":schedule", key + ":dead"}, id, deadLetter)
+	return err
+}
+
+func (o scriptOps) deadLetters(ctx context.Context, key string, stop int64) ([]string, error) {
+	result, err := o.scripter.Eval(ctx, deadLettersScript, []string{key + ":dead"}, stop)
+	if err != nil {
+		return nil, err
+	}
+	items, _ := result.([]interface{})
+	values := make([]string, 0, len(items))
+	for _, item := range items {
+		if value, ok := item.(string); ok {
+			values = append(values, value)
+		}
+	}
+	return values, nil
+}
+
+// memoryOps runs the operations of the queue on NewInMemoryRedis, under its lock. The
+// schedule is a hash of the due times.
+type memoryOps struct {
+	redis *inMemoryRedis
+}
+
+func (o memoryOps) schedule(_ context.Context, key, id, raw string, at int64) error {
+	r := o.redis
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	o.hash(key + ":pending")[id] = raw
+	o.hash(key + ":schedule")[id] = strconv.FormatInt(at, 10)
+	return nil
+}
+
+func (o memoryOps) dequeue(_ context.Context, key string, now, deadline int64) (string, error) {
+	r := o.redis
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	pending, schedule := o.hash(key+":pending"), o.hash(key+":schedule")
+
+	type dueJob struct {
+		id string
+		at int64
+	}
+	var due []dueJob
+	for id, value := range schedule {
+		if at, _ := strconv.ParseInt(value, 10, 64); at <= now {
+			due = append(due, dueJob{id: id, at: at})
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].at < due[j].at })
+	for _, job := range due[:min(len(due), promoteBatch)] {
+		if raw, ok := pending[job.id]; ok {
+			r.listData[key] = append([]string{raw}, r.listData[key]...)
+			delete(pending, job.id)
+		}
+		delete(schedule, job.id)
+	}
+
+	list := r.listData[key]
+	if len(list) == 0 {
+		return "", Nil
+	}
+	raw := list[len(list)-1]
+	r.listData[key] = list[:len(list)-1]
+	var job queue.Job
+	if err := json.Unmarshal([]byte(raw), &job); err != nil {
+		return "", err
+	}
+	pending[job.ID] = raw
+	schedule[job.ID] = strconv.FormatInt(deadline, 10)
+	return raw, nil
+}
+
+func (o memoryOps) remove(_ context.Context, key, id, deadLetter string) error {
+	r := o.redis
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(o.hash(key+":pending"), id)
+	delete(o.hash(key+":schedule"), id)
+	if deadLetter != "" {
+		r.listData[key+":dead"] = append([]string{deadLetter}, r.listData[key+":dead"]...)
+	}
+	return nil
+}
+
+func (o memoryOps) deadLetters(ctx context.Context, key string, stop int64) ([]string, error) {
+	return o.redis.LRange(ctx, key+":dead", 0, stop)
+}
+
+// hash returns a hash of the client, created if needed. The lock is held by the caller.
+func (o memoryOps) hash(key string) map[string]string {
+	hash, ok := o.redis.hashData[key]
+	if !ok {
+		hash = map[string]string{}
+		o.redis.hashData[key] = hash
+	}
+	return hash
+}
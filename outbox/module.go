@@ -0,0 +1,33 @@
+package outbox
+
+import (
+	"github.com/go-liquor/liquor-sdk/app"
+	"go.uber.org/fx"
+)
+
+// RelayModule provides the *Relay and runs it as the "outbox-relay" worker.
+// The *Outbox is provided by the OutboxModule of the database modules.
+var RelayModule = fx.Module("liquor-outbox-relay", fx.Provide(
+	NewRelay,
+),
+	app.RegisterWorkers(func(r *Relay) *Relay { return r }),
+)
+
+// WebhookPublisherModule provides the "webhook" publisher.
+var WebhookPublisherModule = RegisterPublishers(NewWebhookPublisher)
+
+// RegisterPublishers register the constructors of your publishers.
+//
+// Example:
+//
+//	app.NewApp(
+//	    outbox.RelayModule,
+//	    outbox.RegisterPublishers(NewKafkaPublisher),
+//	)
+func RegisterPublishers(publishers ...any) fx.Option {
+	annotated := make([]any, len(publishers))
+	for i, p := range publishers {
+		annotated[i] = fx.Annotate(p, fx.As(new(Publisher)), fx.ResultTags(`group:"liquor-outbox-publishers"`))
+	}
+	return fx.Module("liquor-outbox-publishers", fx.Provide(annotated...))
+}
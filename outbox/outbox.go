@@ -0,0 +1,268 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect"
+)
+
+// DefaultTable is the table of the outbox.
+const DefaultTable = "liquor_outbox"
+
+// Message is an event stored in the outbox.
+type Message struct {
+	// ID orders the messages.
+	ID int64 `json:"id"`
+	// Topic selects the publisher and the destination of the message.
+	Topic string `json:"topic"`
+	// Key orders the delivery: the messages of a key are delivered one after the other.
+	Key string `json:"key,omitempty"`
+	// Payload is the content of the message.
+	Payload []byte `json:"payload"`
+	// CreatedAt is the time the message was stored.
+	CreatedAt time.Time `json:"createdAt"`
+	// Attempts is the number of failed deliveries.
+	Attempts int `json:"attempts"`
+}
+
+type txKey struct{}
+
+// WithTx returns a context carrying a transaction: Store (and the app.ToOutbox
+// subscribers) write the events in it.
+//
+// Parameters:
+//   - ctx: The parent context
+//   - tx: The transaction
+//
+// Returns:
+//   - context.Context: The context carrying tx
+//
+// Example:
+//
+//	err := db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+//	    if _, err := tx.NewInsert().Model(user).Exec(ctx); err != nil {
+//	        return err
+//	    }
+//	    return bus.Publish(outbox.WithTx(ctx, tx), UserCreated{ID: user.ID})
+//	})
+func WithTx(ctx context.Context, tx bun.IDB) context.Context {
+	return context.WithValue(ctx, txKey{}, tx)
+}
+
+// Outbox writes events in the transactions of the application; the Relay delivers them
+// once committed, so an event is never lost nor sent for a rolled back change.
+type Outbox struct {
+	db    *bun.DB
+	table string
+}
+
+// NewOutbox creates the outbox of a bun database (postgres, mysql or sqlite).
+// The table is created by Init.
+//
+// Parameters:
+//   - db: The database
+//   - table: The table of the outbox (DefaultTable if empty)
+//
+// Returns:
+//   - *Outbox: The outbox
+func NewOutbox(db *bun.DB, table string) *Outbox {
+	if table == "" {
+		table = DefaultTable
+	}
+	return &Outbox{db: db, table: table}
+}
+
+// Init creates the table of the outbox and its indexes if they do not exist.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//
+// Returns:
+//   - error: nil if successful, error otherwise
+func (o *Outbox) Init(ctx context.Context) error {
+	id := "BIGINT AUTO_INCREMENT PRIMARY KEY"
+	switch o.db.Dialect().Name() {
+	case dialect.PG:
+		id = "BIGSERIAL PRIMARY KEY"
+	case dialect.SQLite:
+		id = "INTEGER PRIMARY KEY AUTOINCREMENT"
+	}
+	_, err := o.db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS ? (
+	id `+id+`,
+	topic VARCHAR(255) NOT NULL,
+	msg_key VARCHAR(255) NOT NULL,
+	payload TEXT NOT NULL,
+	created_at TIMESTAMP NOT NULL,
+	attempts INTEGER NOT NULL,
+	next_attempt_at TIMESTAMP NOT NULL,
+	locked_until TIMESTAMP NULL,
+	last_error TEXT NULL,
+	delivered_at TIMESTAMP NULL,
+	dead BOOLEAN NOT NULL
+)`, bun.Ident(o.table))
+	if err != nil {
+		return fmt.Errorf("outbox: failed to create table %s: %w", o.table, err)
+	}
+	// the due messages are read by claim, and the older messages of their key checked
+	for _, index := range []struct{ name, columns string }{
+		{name: "due", columns: "delivered_at, dead, next_attempt_at"},
+		{name: "key", columns: "msg_key, id"},
+	} {
+		if err := o.createIndex(ctx, o.table+"_"+index.name, index.columns); err != nil {
+			return fmt.Errorf("outbox: failed to create the %s index of %s: %w", index.name, o.table, err)
+		}
+	}
+	return nil
+}
+
+// createIndex creates an index of the table if it does not exist. MySQL has no
+// CREATE INDEX IF NOT EXISTS: the index is created unless information_schema lists it.
+func (o *Outbox) createIndex(ctx context.Context, name, columns string) error {
+	if o.db.Dialect().Name() != dialect.MySQL {
+		_, err := o.db.ExecContext(ctx, "CREATE INDEX IF NOT EXISTS ? ON ? ("+columns+")", bun.Ident(name), bun.Ident(o.table))
+		return err
+	}
+	var count int
+	err := o.db.NewRaw("SELECT COUNT(*) FROM information_schema.statistics WHERE table_schema = DATABASE() AND table_name = ? AND index_name = ?",
+		o.table, name).Scan(ctx, &count)
+	if err != nil || count > 0 {
+		return err
+	}
+	_, err = o.db.ExecContext(ctx, "CREATE INDEX ? ON ? ("+columns+")", bun.Ident(name), bun.Ident(o.table))
+	return err
+}
+
+// Add writes a message in a transaction.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - db: The transaction of the change (a bun.Tx), or the database
+//   - topic: The topic of the message
+//   - key: The key ordering the delivery (may be empty)
+//   - payload: The payload: []byte and json.RawMessage are written as is, other values in JSON
+//
+// Returns:
+//   - error: nil if successful, error otherwise
+//
+// Example:
+//
+//	err := db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+//	    if _, err := tx.NewUpdate().Model(order).WherePK().Exec(ctx); err != nil {
+//	        return err
+//	    }
+//	    return box.Add(ctx, tx, "orders", order.ID, OrderShipped{ID: order.ID})
+//	})
+func (o *Outbox) Add(ctx context.Context, db bun.IDB, topic, key string, payload any) error {
+	var raw []byte
+	switch v := payload.(type) {
+	case []byte:
+		raw = v
+	case json.RawMessage:
+		raw = v
+	default:
+		var err error
+		if raw, err = json.Marshal(payload); err != nil {
+			return fmt.Errorf("outbox: failed to encode the payload of %s: %w", topic, err)
+		}
+	}
+	now := time.Now().UTC()
+	_, err := db.ExecContext(ctx,
+		"INSERT INTO ? (topic, msg_key, payload, created_at, attempts, next_attempt_at, dead) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		bun.Ident(o.table), topic, key, string(raw), now, 0, now, false)
+	if err != nil {
+		return fmt.Errorf("outbox: failed to write into %s: %w", o.table, err)
+	}
+	return nil
+}
+
+// Store implements app.EventOutbox: the event is written in the transaction of ctx
+// (see WithTx), or directly in the database.
+func (o *Outbox) Store(ctx context.Context, topic, key string, payload []byte) error {
+	db, ok := ctx.Value(txKey{}).(bun.IDB)
+	if !ok {
+		db = o.db
+	}
+	return o.Add(ctx, db, topic, key, payload)
+}
+
+type messageRow struct {
+	ID        int64     `bun:"id"`
+	Topic     string    `bun:"topic"`
+	Key       string    `bun:"msg_key"`
+	Payload   string    `bun:"payload"`
+	CreatedAt time.Time `bun:"created_at"`
+	Attempts  int       `bun:"attempts"`
+}
+
+// claim reserves the next due messages. A message is due when no older message of its key
+// is waiting, so the messages of a key are delivered in order.
+func (o *Outbox) claim(ctx context.Context, limit int, lease time.Duration) ([]Message, error) {
+	now := time.Now().UTC()
+	var rows []messageRow
+	err := o.db.NewRaw(`SELECT m.id, m.topic, m.msg_key, m.payload, m.created_at, m.attempts FROM ? AS m
+WHERE m.delivered_at IS NULL AND m.dead = ? AND m.next_attempt_at <= ?
+AND (m.locked_until IS NULL OR m.locked_until < ?)
+AND (m.msg_key = '' OR NOT EXISTS (
+	SELECT 1 FROM ? AS p WHERE p.msg_key = m.msg_key AND p.id < m.id AND p.delivered_at IS NULL AND p.dead = ?
+))
+ORDER BY m.id LIMIT ?`, bun.Ident(o.table), false, now, now, bun.Ident(o.table), false, limit).Scan(ctx, &rows)
+	if err != nil {
+		return nil, fmt.Errorf("outbox: failed to read %s: %w", o.table, err)
+	}
+
+	messages := make([]Message, 0, len(rows))
+	for _, row := range rows {
+		res, err := o.db.ExecContext(ctx,
+			"UPDATE ? SET locked_until = ? WHERE id = ? AND delivered_at IS NULL AND (locked_until IS NULL OR locked_until < ?)",
+			bun.Ident(o.table), now.Add(lease), row.ID, now)
+		if err != nil {
+			return nil, fmt.Errorf("outbox: failed to reserve message %d: %w", row.ID, err)
+		}
+		if affected, _ := res.RowsAffected(); affected == 1 {
+			messages = append(messages, Message{
+				ID:        row.ID,
+				Topic:     row.Topic,
+				Key:       row.Key,
+				Payload:   []byte(row.Payload),
+				CreatedAt: row.CreatedAt,
+				Attempts:  row.Attempts,
+			})
+		}
+	}
+	return messages, nil
+}
+
+// delivered marks a message as delivered, or deletes it when the delivered messages are not kept.
+func (o *Outbox) delivered(ctx context.Context, id int64, keep bool) error {
+	var err error
+	if keep {
+		_, err = o.db.ExecContext(ctx, "UPDATE ? SET delivered_at = ?, locked_until = NULL WHERE id = ?",
+			bun.Ident(o.table), time.Now().UTC(), id)
+	} else {
+		_, err = o.db.ExecContext(ctx, "DELETE FROM ? WHERE id = ?", bun.Ident(o.table), id)
+	}
+	return err
+}
+
+// failed records a failed delivery: the message is retried at next, or set aside when dead.
+func (o *Outbox) failed(ctx context.Context, msg Message, cause error, next time.Time, dead bool) error {
+	_, err := o.db.ExecContext(ctx,
+		"UPDATE ? SET attempts = ?, last_error = ?, next_attempt_at = ?, locked_until = NULL, dead = ? WHERE id = ?",
+		bun.Ident(o.table), msg.Attempts, sql.NullString{String: cause.Error(), Valid: true}, next.UTC(), dead, msg.ID)
+	return err
+}
+
+// cleanup deletes the messages delivered before a time.
+func (o *Outbox) cleanup(ctx context.Context, before time.Time) (int64, error) {
+	res, err := o.db.ExecContext(ctx, "DELETE FROM ? WHERE delivered_at IS NOT NULL AND delivered_at < ?",
+		bun.Ident(o.table), before.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("outbox: failed to clean %s: %w", o.table, err)
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,285 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/go-liquor/liquor-sdk/config"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// Publisher delivers the messages of the outbox to a broker.
+type Publisher interface {
+	// Name identifies the publisher in `outbox.topics.<topic>.publisher`.
+	Name() string
+	// Publish delivers a message. The destination is usually read from
+	// `outbox.topics.<topic>.destination`.
+	Publish(ctx context.Context, msg Message) error
+}
+
+// RelayOptions configures the delivery of the messages.
+type RelayOptions struct {
+	// PollInterval is the wait when no message is due.
+	PollInterval time.Duration
+	// BatchSize is the number of messages read at once.
+	BatchSize int
+	// Concurrency is the number of messages delivered at the same time.
+	Concurrency int
+	// MaxAttempts is the number of attempts before a message is set aside (0 retries forever).
+	MaxAttempts int
+	// InitialBackoff is the delay before the first retry; it doubles at every attempt.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the delay between two attempts.
+	MaxBackoff time.Duration
+	// Retention is how long the delivered messages are kept (0 deletes them at once).
+	Retention time.Duration
+	// Lease is how long a message is reserved while it is delivered: a delivery is canceled
+	// when it lasts longer, so another replica never delivers the message at the same time.
+	Lease time.Duration
+}
+
+// Relay delivers the committed messages of the outbox to their publisher. It is registered
+// as the "outbox-relay" worker, and several replicas can run it.
+type Relay struct {
+	outbox     *Outbox
+	publishers map[string]Publisher
+	routes     func(topic string) string
+	logger     *zap.Logger
+	options    RelayOptions
+}
+
+type relayParams struct {
+	fx.In
+
+	Outbox     *Outbox
+	Config     *config.Config
+	Logger     *zap.Logger
+	Publishers []Publisher `group:"liquor-outbox-publishers"`
+}
+
+// NewRelay creates the relay of the outbox with the registered publishers.
+//
+// Config keys:
+//   - outbox.topics.<topic>.publisher: The publisher of a topic (optional with a single publisher)
+//   - outbox.topics.<topic>.destination: The destination of a topic (queue URL, stream, webhook URL)
+//   - outbox.pollInterval: The wait when no message is due (default 1s)
+//   - outbox.batchSize: The messages read at once (default 100)
+//   - outbox.concurrency: The messages delivered at the same time (default 10)
+//   - outbox.maxAttempts: The attempts before a message is set aside (default 0, retries forever)
+//   - outbox.backoff.initial: The delay before the first retry (default 1s)
+//   - outbox.backoff.max: The maximum delay between two attempts (default 10m)
+//   - outbox.retention: How long the delivered messages are kept (default 0, deleted at once)
+//   - outbox.lease: How long a message is reserved while it is delivered (default 1m)
+//
+// Returns:
+//   - *Relay: The relay
+//   - error: An error if two publishers share a name, or the lease doesn't exceed the
+//     timeout of the webhook publisher
+func NewRelay(p relayParams) (*Relay, error) {
+	options := RelayOptions{
+		PollInterval:   p.Config.GetDuration("outbox.pollInterval"),
+		BatchSize:      p.Config.GetInt("outbox.batchSize"),
+		Concurrency:    p.Config.GetInt("outbox.concurrency"),
+		MaxAttempts:    p.Config.GetInt("outbox.maxAttempts"),
+		InitialBackoff: p.Config.GetDuration("outbox.backoff.initial"),
+		MaxBackoff:     p.Config.GetDuration("outbox.backoff.max"),
+		Retention:      p.Config.GetDuration("outbox.retention"),
+		Lease:          p.Config.GetDuration("outbox.lease"),
+	}
+	// the routes are read for each message
+	p.Config.Uses("outbox.topics")
+	r := NewRelayWith(p.Outbox, p.Logger, options, p.Publishers...)
+	if len(r.publishers) != len(p.Publishers) {
+		return nil, errors.New("outbox: two publishers share a name")
+	}
+	if webhook, ok := r.publishers["webhook"].(*WebhookPublisher); ok && webhook.client.Timeout >= r.options.Lease {
+		return nil, fmt.Errorf("outbox: outbox.lease (%s) must exceed outbox.webhook.timeout (%s)", r.options.Lease, webhook.client.Timeout)
+	}
+	r.routes = func(topic string) string {
+		return p.Config.GetString("outbox.topics." + topic + ".publisher")
+	}
+	return r, nil
+}
+
+// NewRelayWith creates a relay without fx. Zero options take their default.
+//
+// Parameters:
+//   - outbox: The outbox
+//   - logger: The logger (may be nil)
+//   - options: The delivery options
+//   - publishers: The publishers
+//
+// Returns:
+//   - *Relay: The relay
+func NewRelayWith(outbox *Outbox, logger *zap.Logger, options RelayOptions, publishers ...Publisher) *Relay {
+	if options.PollInterval <= 0 {
+		options.PollInterval = time.Second
+	}
+	if options.BatchSize <= 0 {
+		options.BatchSize = 100
+	}
+	if options.Concurrency <= 0 {
+		options.Concurrency = 10
+	}
+	if options.InitialBackoff <= 0 {
+		options.InitialBackoff = time.Second
+	}
+	if options.MaxBackoff <= 0 {
+		options.MaxBackoff = 10 * time.Minute
+	}
+	if options.Lease <= 0 {
+		options.Lease = time.Minute
+	}
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	r := &Relay{
+		outbox:     outbox,
+		publishers: map[string]Publisher{},
+		routes:     func(string) string { return "" },
+		logger:     logger,
+		options:    options,
+	}
+	for _, p := range publishers {
+		r.publishers[p.Name()] = p
+	}
+	return r
+}
+
+// Name implements app.Worker.
+func (r *Relay) Name() string {
+	return "outbox-relay"
+}
+
+// Run implements app.Worker: it delivers the messages until ctx is canceled.
+func (r *Relay) Run(ctx context.Context) error {
+	lastCleanup := time.Now()
+	for ctx.Err() == nil {
+		delivered, err := r.Deliver(ctx)
+		if err != nil && ctx.Err() == nil {
+			r.logger.Error("outbox: failed to deliver messages", zap.Error(err))
+		}
+		if r.options.Retention > 0 && time.Since(lastCleanup) > time.Minute {
+			lastCleanup = time.Now()
+			if deleted, err := r.outbox.cleanup(ctx, time.Now().Add(-r.options.Retention)); err != nil {
+				r.logger.Error("outbox: failed to delete the delivered messages", zap.Error(err))
+			} else if deleted > 0 {
+				r.logger.Debug("outbox cleaned", zap.Int64("deleted", deleted))
+			}
+		}
+		if delivered == r.options.BatchSize {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+		case <-time.After(r.options.PollInterval):
+		}
+	}
+	return nil
+}
+
+// Deliver delivers a batch of due messages. The messages are claimed when a delivery slot
+// is free, so a message is only reserved while it is delivered (see RelayOptions.Lease),
+// and the messages of a key are never delivered at the same time.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//
+// Returns:
+//   - int: The number of messages read
+//   - error: An error if the outbox cannot be read
+func (r *Relay) Deliver(ctx context.Context) (int, error) {
+	storeCtx := context.WithoutCancel(ctx)
+	slots := make(chan struct{}, r.options.Concurrency)
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	read := 0
+	for read < r.options.BatchSize && ctx.Err() == nil {
+		// wait for a free slot, then claim a message for each free slot
+		slots <- struct{}{}
+		free := min(cap(slots)-len(slots)+1, r.options.BatchSize-read)
+		<-slots
+		messages, err := r.outbox.claim(ctx, free, r.options.Lease)
+		if err != nil {
+			return read, err
+		}
+		read += len(messages)
+		for _, msg := range messages {
+			slots <- struct{}{}
+			wg.Add(1)
+			go func(msg Message) {
+				defer func() {
+					<-slots
+					wg.Done()
+				}()
+				r.deliver(ctx, storeCtx, msg)
+			}(msg)
+		}
+		if len(messages) < free {
+			break
+		}
+	}
+	return read, nil
+}
+
+func (r *Relay) deliver(ctx, storeCtx context.Context, msg Message) {
+	log := r.logger.With(zap.String("topic", msg.Topic), zap.Int64("message", msg.ID))
+	// the delivery ends before the lease, so no other replica delivers the message meanwhile
+	publishCtx, cancel := context.WithTimeout(ctx, r.options.Lease)
+	err := r.publish(publishCtx, msg)
+	cancel()
+	if err == nil {
+		if err := r.outbox.delivered(storeCtx, msg.ID, r.options.Retention > 0); err != nil {
+			log.Error("outbox: failed to mark the message delivered", zap.Error(err))
+		}
+		log.Debug("outbox message delivered")
+		return
+	}
+
+	msg.Attempts++
+	dead := r.options.MaxAttempts > 0 && msg.Attempts >= r.options.MaxAttempts
+	next := time.Now().Add(r.backoff(msg.Attempts))
+	if err := r.outbox.failed(storeCtx, msg, err, next, dead); err != nil {
+		log.Error("outbox: failed to record the failed delivery", zap.Error(err))
+	}
+	if dead {
+		log.Error("outbox message set aside", zap.Int("attempt", msg.Attempts), zap.Error(err))
+		return
+	}
+	log.Warn("outbox delivery failed, retrying", zap.Int("attempt", msg.Attempts), zap.Time("next", next), zap.Error(err))
+}
+
+func (r *Relay) publish(ctx context.Context, msg Message) (err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = fmt.Errorf("panic: %v", rec)
+		}
+	}()
+	name := r.routes(msg.Topic)
+	publisher, ok := r.publishers[name]
+	if name == "" && len(r.publishers) == 1 {
+		for _, p := range r.publishers {
+			publisher, ok = p, true
+		}
+	}
+	if !ok {
+		return fmt.Errorf("outbox: no publisher for topic %q (outbox.topics.%s.publisher)", msg.Topic, msg.Topic)
+	}
+	return publisher.Publish(ctx, msg)
+}
+
+// backoff returns the delay before the next attempt: it doubles at every attempt,
+// with a random half to spread the retries.
+func (r *Relay) backoff(attempt int) time.Duration {
+	d := r.options.InitialBackoff
+	for i := 1; i < attempt && d < r.options.MaxBackoff; i++ {
+		d *= 2
+	}
+	d = min(d, r.options.MaxBackoff)
+	return d/2 + rand.N(d/2+1)
+}
@@ -0,0 +1,87 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-liquor/liquor-sdk/config"
+)
+
+// WebhookPublisher posts the messages to HTTP endpoints. The body is the payload and the
+// headers describe the message:
+//   - X-Outbox-Topic, X-Outbox-Message-Id and X-Outbox-Key
+//   - X-Outbox-Signature: sha256=<hex HMAC-SHA256 of the body> when a secret is set
+//
+// A response outside of 2xx is a failed delivery.
+type WebhookPublisher struct {
+	client *http.Client
+	config *config.Config
+}
+
+// NewWebhookPublisher creates the "webhook" publisher.
+//
+// Config keys:
+//   - outbox.topics.<topic>.destination: The URL receiving the messages of a topic
+//   - outbox.topics.<topic>.secret: The key signing the messages of a topic (optional)
+//   - outbox.webhook.timeout: The timeout of a request (default 10s)
+//
+// Parameters:
+//   - cfg: Configuration
+//
+// Returns:
+//   - *WebhookPublisher: The publisher
+func NewWebhookPublisher(cfg *config.Config) *WebhookPublisher {
+	timeout := cfg.GetDuration("outbox.webhook.timeout")
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	cfg.Uses("outbox.topics")
+	return &WebhookPublisher{client: &http.Client{Timeout: timeout}, config: cfg}
+}
+
+// Name implements Publisher.
+func (p *WebhookPublisher) Name() string {
+	return "webhook"
+}
+
+// Publish implements Publisher.
+func (p *WebhookPublisher) Publish(ctx context.Context, msg Message) error {
+	url := p.config.GetString("outbox.topics." + msg.Topic + ".destination")
+	if url == "" {
+		return fmt.Errorf("outbox: outbox.topics.%s.destination is not set", msg.Topic)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(msg.Payload))
+	if err != nil {
+		return fmt.Errorf("outbox: invalid webhook of %s: %w", msg.Topic, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Outbox-Topic", msg.Topic)
+	req.Header.Set("X-Outbox-Message-Id", strconv.FormatInt(msg.ID, 10))
+	if msg.Key != "" {
+		req.Header.Set("X-Outbox-Key", msg.Key)
+	}
+	if secret := p.config.GetString("outbox.topics." + msg.Topic + ".secret"); secret != "" {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(msg.Payload)
+		req.Header.Set("X-Outbox-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("outbox: webhook of %s answered %d: %s", msg.Topic, resp.StatusCode, body)
+	}
+	return nil
+}
@@ -0,0 +1,25 @@
+package queue
+
+import "context"
+
+// Backend stores the jobs.
+type Backend interface {
+	// Enqueue stores a job to run at job.RunAt.
+	Enqueue(ctx context.Context, job Job) error
+	// Dequeue reserves a due job of the queue: the job is not given to another worker until
+	// it is acked, retried or dead-lettered, or its reservation expires.
+	// It returns nil when no job is due.
+	Dequeue(ctx context.Context, queue string) (*Job, error)
+	// Ack removes a processed job.
+	Ack(ctx context.Context, job *Job) error
+	// Retry releases a failed job to run again at job.RunAt.
+	Retry(ctx context.Context, job *Job) error
+	// DeadLetter moves a job out of the queue after its last attempt.
+	DeadLetter(ctx context.Context, job *Job) error
+}
+
+// DeadLetterReader is implemented by the backends listing their dead letters.
+type DeadLetterReader interface {
+	// DeadLetters returns the last dead letters of a queue.
+	DeadLetters(ctx context.Context, queue string, limit int) ([]Job, error)
+}
@@ -0,0 +1,115 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// DefaultQueue is the queue of the jobs enqueued without OnQueue.
+const DefaultQueue = "default"
+
+// Job is a unit of work stored in a backend.
+type Job struct {
+	ID          string          `json:"id"`
+	Queue       string          `json:"queue"`
+	Type        string          `json:"type"`
+	Payload     json.RawMessage `json:"payload"`
+	Attempts    int             `json:"attempts"`
+	MaxAttempts int             `json:"maxAttempts"`
+	RunAt       time.Time       `json:"runAt"`
+	EnqueuedAt  time.Time       `json:"enqueuedAt"`
+	LastError   string          `json:"lastError,omitempty"`
+	// Receipt identifies the delivery in the backend (e.g. the SQS receipt handle).
+	Receipt string `json:"-"`
+}
+
+// Handler processes the jobs of a type.
+type Handler struct {
+	Type   string
+	handle func(ctx context.Context, payload json.RawMessage) error
+}
+
+// HandlerFunc creates the handler of a job type with a typed payload.
+//
+// Parameters:
+//   - jobType: The job type
+//   - fn: The function processing the decoded payload
+//
+// Returns:
+//   - Handler: The handler
+//
+// Example:
+//
+//	queue.HandlerFunc("send-email", func(ctx context.Context, email Email) error {
+//	    return mailer.Send(ctx, email)
+//	})
+func HandlerFunc[T any](jobType string, fn func(ctx context.Context, payload T) error) Handler {
+	return Handler{
+		Type: jobType,
+		handle: func(ctx context.Context, raw json.RawMessage) error {
+			var payload T
+			if err := json.Unmarshal(raw, &payload); err != nil {
+				return Permanent(fmt.Errorf("queue: invalid payload for %q: %w", jobType, err))
+			}
+			return fn(ctx, payload)
+		},
+	}
+}
+
+type permanentError struct {
+	err error
+}
+
+func (e permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e permanentError) Unwrap() error {
+	return e.err
+}
+
+// Permanent marks an error that retrying will not fix: the job goes to the dead letters
+// without being retried.
+func Permanent(err error) error {
+	return permanentError{err: err}
+}
+
+// IsPermanent reports whether err was marked with Permanent.
+func IsPermanent(err error) bool {
+	var permanent permanentError
+	return errors.As(err, &permanent)
+}
+
+// EnqueueOption customizes an enqueued job.
+type EnqueueOption func(*Job)
+
+// Delay runs the job after d.
+func Delay(d time.Duration) EnqueueOption {
+	return func(j *Job) {
+		j.RunAt = time.Now().Add(d)
+	}
+}
+
+// At runs the job at t.
+func At(t time.Time) EnqueueOption {
+	return func(j *Job) {
+		j.RunAt = t
+	}
+}
+
+// OnQueue enqueues the job in the given queue instead of DefaultQueue.
+func OnQueue(name string) EnqueueOption {
+	return func(j *Job) {
+		j.Queue = name
+	}
+}
+
+// WithMaxAttempts overrides `queue.maxAttempts` for the job.
+func WithMaxAttempts(n int) EnqueueOption {
+	return func(j *Job) {
+		j.MaxAttempts = n
+	}
+}
@@ -0,0 +1,121 @@
+package queue
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryBackend keeps the jobs in memory. It is meant for tests and development:
+// the jobs are lost when the process stops.
+type MemoryBackend struct {
+	mu         sync.Mutex
+	visibility time.Duration
+	pending    map[string][]Job
+	reserved   map[string]reservation
+	dead       map[string][]Job
+}
+
+type reservation struct {
+	job   Job
+	until time.Time
+}
+
+// NewMemoryBackend creates an in-memory backend.
+//
+// Returns:
+//   - *MemoryBackend: The backend
+func NewMemoryBackend() *MemoryBackend {
+	return &MemoryBackend{
+		visibility: 5 * time.Minute,
+		pending:    map[string][]Job{},
+		reserved:   map[string]reservation{},
+		dead:       map[string][]Job{},
+	}
+}
+
+// Enqueue implements Backend.
+func (b *MemoryBackend) Enqueue(_ context.Context, job Job) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.pending[job.Queue] = append(b.pending[job.Queue], job)
+	return nil
+}
+
+// Dequeue implements Backend.
+func (b *MemoryBackend) Dequeue(_ context.Context, queue string) (*Job, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	for id, r := range b.reserved {
+		if r.job.Queue == queue && now.After(r.until) {
+			delete(b.reserved, id)
+			b.pending[queue] = append(b.pending[queue], r.job)
+		}
+	}
+
+	next := -1
+	for i, job := range b.pending[queue] {
+		if !job.RunAt.After(now) && (next < 0 || job.RunAt.Before(b.pending[queue][next].RunAt)) {
+			next = i
+		}
+	}
+	if next < 0 {
+		return nil, nil
+	}
+	job := b.pending[queue][next]
+	b.pending[queue] = append(b.pending[queue][:next], b.pending[queue][next+1:]...)
+	b.reserved[job.ID] = reservation{job: job, until: now.Add(b.visibility)}
+	return &job, nil
+}
+
+// Ack implements Backend.
+func (b *MemoryBackend) Ack(_ context.Context, job *Job) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.reserved, job.ID)
+	return nil
+}
+
+// Retry implements Backend.
+func (b *MemoryBackend) Retry(_ context.Context, job *Job) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.reserved, job.ID)
+	b.pending[job.Queue] = append(b.pending[job.Queue], *job)
+	return nil
+}
+
+// DeadLetter implements Backend.
+func (b *MemoryBackend) DeadLetter(_ context.Context, job *Job) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.reserved, job.ID)
+	b.dead[job.Queue] = append(b.dead[job.Queue], *job)
+	return nil
+}
+
+// DeadLetters implements DeadLetterReader.
+func (b *MemoryBackend) DeadLetters(_ context.Context, queue string, limit int) ([]Job, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	dead := b.dead[queue]
+	if limit > 0 && len(dead) > limit {
+		dead = dead[len(dead)-limit:]
+	}
+	return append([]Job(nil), dead...), nil
+}
+
+// Pending returns the number of jobs waiting or running in a queue.
+func (b *MemoryBackend) Pending(queue string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	count := len(b.pending[queue])
+	for _, r := range b.reserved {
+		if r.job.Queue == queue {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,39 @@
+package queue
+
+import (
+	"github.com/go-liquor/liquor-sdk/app"
+	"go.uber.org/fx"
+)
+
+// QueueModule provides the *Queue and runs it as the "queue" worker.
+// A Backend must be provided (e.g. MemoryBackendModule or the QueueBackendModule of the
+// database, redis and aws modules).
+var QueueModule = fx.Module("liquor-queue", fx.Provide(
+	NewQueue,
+),
+	app.RegisterWorkers(func(q *Queue) *Queue { return q }),
+)
+
+// MemoryBackendModule provides a MemoryBackend, for tests and development.
+var MemoryBackendModule = fx.Module("liquor-queue-memory", fx.Provide(
+	fx.Annotate(NewMemoryBackend, fx.As(fx.Self()), fx.As(new(Backend))),
+))
+
+// RegisterHandlers register the constructors of your handlers.
+//
+// Example:
+//
+//	app.NewApp(
+//	    queue.QueueModule,
+//	    redis.QueueBackendModule,
+//	    queue.RegisterHandlers(func(mailer *Mailer) queue.Handler {
+//	        return queue.HandlerFunc("send-email", mailer.Send)
+//	    }),
+//	)
+func RegisterHandlers(handlers ...any) fx.Option {
+	annotated := make([]any, len(handlers))
+	for i, h := range handlers {
+		annotated[i] = fx.Annotate(h, fx.ResultTags(`group:"liquor-queue-handlers"`))
+	}
+	return fx.Module("liquor-queue-handlers", fx.Provide(annotated...))
+}
@@ -0,0 +1,295 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/go-liquor/liquor-sdk/config"
+	"github.com/google/uuid"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// Options configures the processing of the jobs.
+type Options struct {
+	// Queues are the queues processed by the worker.
+	Queues []string
+	// Concurrency is the number of jobs processed at the same time per queue.
+	Concurrency int
+	// MaxAttempts is the number of attempts of a job before it is dead-lettered.
+	MaxAttempts int
+	// InitialBackoff is the delay before the first retry; it doubles at every attempt.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the delay between two attempts.
+	MaxBackoff time.Duration
+	// PollInterval is the wait when a queue has no due job.
+	PollInterval time.Duration
+}
+
+// Queue enqueues jobs and processes them with the registered handlers. It is registered
+// as the "queue" worker, so it processes jobs with the application (or `worker queue`).
+type Queue struct {
+	backend  Backend
+	handlers map[string]Handler
+	logger   *zap.Logger
+	options  Options
+}
+
+type queueParams struct {
+	fx.In
+
+	Config   *config.Config
+	Logger   *zap.Logger
+	Backend  Backend
+	Handlers []Handler `group:"liquor-queue-handlers"`
+}
+
+// NewQueue creates the queue of the registered handlers.
+//
+// Config keys:
+//   - queue.queues: The processed queues (default [default])
+//   - queue.concurrency: The jobs processed at the same time per queue (default 10)
+//   - queue.maxAttempts: The attempts before a job is dead-lettered (default 5)
+//   - queue.backoff.initial: The delay before the first retry (default 1s)
+//   - queue.backoff.max: The maximum delay between two attempts (default 1h)
+//   - queue.pollInterval: The wait when a queue is empty (default 1s)
+//
+// Returns:
+//   - *Queue: The queue
+//   - error: An error if two handlers share a job type
+func NewQueue(p queueParams) (*Queue, error) {
+	options := Options{
+		Queues:         p.Config.GetStringSlice("queue.queues"),
+		Concurrency:    p.Config.GetInt("queue.concurrency"),
+		MaxAttempts:    p.Config.GetInt("queue.maxAttempts"),
+		InitialBackoff: p.Config.GetDuration("queue.backoff.initial"),
+		MaxBackoff:     p.Config.GetDuration("queue.backoff.max"),
+		PollInterval:   p.Config.GetDuration("queue.pollInterval"),
+	}
+	q := New(p.Backend, p.Logger, options)
+	for _, h := range p.Handlers {
+		if err := q.Handle(h); err != nil {
+			return nil, err
+		}
+	}
+	return q, nil
+}
+
+// New creates a queue without handlers (see Handle). Zero options take their default.
+//
+// Parameters:
+//   - backend: The backend storing the jobs
+//   - logger: The logger (may be nil)
+//   - options: The processing options
+//
+// Returns:
+//   - *Queue: The queue
+func New(backend Backend, logger *zap.Logger, options Options) *Queue {
+	if len(options.Queues) == 0 {
+		options.Queues = []string{DefaultQueue}
+	}
+	if options.Concurrency <= 0 {
+		options.Concurrency = 10
+	}
+	if options.MaxAttempts <= 0 {
+		options.MaxAttempts = 5
+	}
+	if options.InitialBackoff <= 0 {
+		options.InitialBackoff = time.Second
+	}
+	if options.MaxBackoff <= 0 {
+		options.MaxBackoff = time.Hour
+	}
+	if options.PollInterval <= 0 {
+		options.PollInterval = time.Second
+	}
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &Queue{backend: backend, handlers: map[string]Handler{}, logger: logger, options: options}
+}
+
+// Handle adds a handler.
+//
+// Parameters:
+//   - h: The handler (see HandlerFunc)
+//
+// Returns:
+//   - error: An error if a handler is already registered for the job type
+func (q *Queue) Handle(h Handler) error {
+	if _, ok := q.handlers[h.Type]; ok {
+		return fmt.Errorf("queue: handler %q is registered twice", h.Type)
+	}
+	q.handlers[h.Type] = h
+	return nil
+}
+
+// Backend returns the backend of the queue.
+func (q *Queue) Backend() Backend {
+	return q.backend
+}
+
+// Enqueue stores a job. The payload is encoded in JSON.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - jobType: The job type (see HandlerFunc)
+//   - payload: The job payload
+//   - opts: Options such as Delay or OnQueue
+//
+// Returns:
+//   - string: The job ID
+//   - error: nil if successful, error otherwise
+//
+// Example:
+//
+//	id, err := jobs.Enqueue(ctx, "send-email", Email{To: user.Email}, queue.Delay(time.Minute))
+func (q *Queue) Enqueue(ctx context.Context, jobType string, payload any, opts ...EnqueueOption) (string, error) {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("queue: failed to encode the payload of %q: %w", jobType, err)
+	}
+	now := time.Now()
+	job := Job{
+		ID:          uuid.NewString(),
+		Queue:       DefaultQueue,
+		Type:        jobType,
+		Payload:     raw,
+		MaxAttempts: q.options.MaxAttempts,
+		RunAt:       now,
+		EnqueuedAt:  now,
+	}
+	for _, opt := range opts {
+		opt(&job)
+	}
+	if err := q.backend.Enqueue(ctx, job); err != nil {
+		return "", err
+	}
+	return job.ID, nil
+}
+
+// Name implements app.Worker.
+func (q *Queue) Name() string {
+	return "queue"
+}
+
+// Run implements app.Worker: it processes the jobs until ctx is canceled, then waits
+// for the running jobs to return.
+func (q *Queue) Run(ctx context.Context) error {
+	var wg sync.WaitGroup
+	for _, name := range q.options.Queues {
+		for i := 0; i < q.options.Concurrency; i++ {
+			wg.Add(1)
+			go func(name string) {
+				defer wg.Done()
+				q.poll(ctx, name)
+			}(name)
+		}
+	}
+	wg.Wait()
+	return nil
+}
+
+func (q *Queue) poll(ctx context.Context, name string) {
+	for ctx.Err() == nil {
+		job, err := q.backend.Dequeue(ctx, name)
+		if err != nil && ctx.Err() == nil {
+			q.logger.Error("queue: failed to dequeue", zap.String("queue", name), zap.Error(err))
+		}
+		if job == nil {
+			select {
+			case <-ctx.Done():
+			case <-time.After(q.options.PollInterval):
+			}
+			continue
+		}
+		q.process(ctx, job)
+	}
+}
+
+// process runs a job. The backend calls use a context that is not canceled on stop,
+// so the outcome of a job finishing during the shutdown is kept.
+func (q *Queue) process(ctx context.Context, job *Job) {
+	log := q.logger.With(zap.String("queue", job.Queue), zap.String("type", job.Type), zap.String("job", job.ID))
+	storeCtx := context.WithoutCancel(ctx)
+
+	start := time.Now()
+	job.Attempts++
+	err := q.run(ctx, job)
+	duration := time.Since(start)
+
+	if err == nil {
+		if err := q.backend.Ack(storeCtx, job); err != nil {
+			log.Error("queue: failed to ack job", zap.Error(err))
+		}
+		log.Info("job processed", zap.Int("attempt", job.Attempts), zap.Duration("duration", duration))
+		return
+	}
+
+	job.LastError = err.Error()
+	maxAttempts := job.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = q.options.MaxAttempts
+	}
+	if IsPermanent(err) || job.Attempts >= maxAttempts {
+		if err := q.backend.DeadLetter(storeCtx, job); err != nil {
+			log.Error("queue: failed to dead-letter job", zap.Error(err))
+		}
+		log.Error("job dead-lettered", zap.Int("attempt", job.Attempts), zap.Duration("duration", duration), zap.Error(err))
+		return
+	}
+
+	job.RunAt = time.Now().Add(q.backoff(job.Attempts))
+	if err := q.backend.Retry(storeCtx, job); err != nil {
+		log.Error("queue: failed to retry job", zap.Error(err))
+	}
+	log.Warn("job failed, retrying", zap.Int("attempt", job.Attempts), zap.Time("runAt", job.RunAt), zap.Error(err))
+}
+
+func (q *Queue) run(ctx context.Context, job *Job) (err error) {
+	handler, ok := q.handlers[job.Type]
+	if !ok {
+		return Permanent(fmt.Errorf("queue: no handler for job type %q", job.Type))
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v\n%s", r, debug.Stack())
+		}
+	}()
+	return handler.handle(ctx, job.Payload)
+}
+
+// backoff returns the delay before the next attempt: it doubles at every attempt,
+// with a random half to spread the retries.
+func (q *Queue) backoff(attempt int) time.Duration {
+	d := q.options.InitialBackoff
+	for i := 1; i < attempt && d < q.options.MaxBackoff; i++ {
+		d *= 2
+	}
+	d = min(d, q.options.MaxBackoff)
+	return d/2 + rand.N(d/2+1)
+}
+
+// DeadLetters returns the last dead letters of a queue.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - queue: The queue
+//   - limit: The maximum number of jobs
+//
+// Returns:
+//   - []Job: The dead letters
+//   - error: An error if the backend cannot list its dead letters
+func (q *Queue) DeadLetters(ctx context.Context, queue string, limit int) ([]Job, error) {
+	reader, ok := q.backend.(DeadLetterReader)
+	if !ok {
+		return nil, errors.New("queue: the backend does not list its dead letters")
+	}
+	return reader.DeadLetters(ctx, queue, limit)
+}
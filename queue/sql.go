@@ -0,0 +1,183 @@
+package queue
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// DefaultTable is the table of the SQLBackend.
+const DefaultTable = "liquor_jobs"
+
+// SQLBackend stores the jobs in a table of a bun database (postgres, mysql or sqlite).
+// The table is created on first use.
+type SQLBackend struct {
+	db         *bun.DB
+	table      string
+	visibility time.Duration
+	mu         sync.Mutex
+	ready      bool
+}
+
+type jobRow struct {
+	ID          string         `bun:"id"`
+	Queue       string         `bun:"queue"`
+	Type        string         `bun:"type"`
+	Payload     string         `bun:"payload"`
+	Attempts    int            `bun:"attempts"`
+	MaxAttempts int            `bun:"max_attempts"`
+	RunAt       time.Time      `bun:"run_at"`
+	EnqueuedAt  time.Time      `bun:"enqueued_at"`
+	LastError   sql.NullString `bun:"last_error"`
+}
+
+func (r jobRow) job() Job {
+	return Job{
+		ID:          r.ID,
+		Queue:       r.Queue,
+		Type:        r.Type,
+		Payload:     []byte(r.Payload),
+		Attempts:    r.Attempts,
+		MaxAttempts: r.MaxAttempts,
+		RunAt:       r.RunAt,
+		EnqueuedAt:  r.EnqueuedAt,
+		LastError:   r.LastError.String,
+	}
+}
+
+// NewSQLBackend creates a backend storing the jobs in a table.
+//
+// Parameters:
+//   - db: The database
+//   - table: The table (DefaultTable if empty)
+//   - visibility: How long a dequeued job is reserved before another worker can take it
+//
+// Returns:
+//   - *SQLBackend: The backend
+func NewSQLBackend(db *bun.DB, table string, visibility time.Duration) *SQLBackend {
+	if table == "" {
+		table = DefaultTable
+	}
+	if visibility <= 0 {
+		visibility = 5 * time.Minute
+	}
+	return &SQLBackend{db: db, table: table, visibility: visibility}
+}
+
+func (b *SQLBackend) init(ctx context.Context) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.ready {
+		return nil
+	}
+	_, err := b.db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS ? (
+	id VARCHAR(36) PRIMARY KEY,
+	queue VARCHAR(255) NOT NULL,
+	type VARCHAR(255) NOT NULL,
+	payload TEXT NOT NULL,
+	attempts INTEGER NOT NULL,
+	max_attempts INTEGER NOT NULL,
+	run_at TIMESTAMP NOT NULL,
+	enqueued_at TIMESTAMP NOT NULL,
+	locked_until TIMESTAMP NULL,
+	last_error TEXT NULL,
+	dead BOOLEAN NOT NULL
+)`, bun.Ident(b.table))
+	if err != nil {
+		return fmt.Errorf("queue: failed to create table %s: %w", b.table, err)
+	}
+	b.ready = true
+	return nil
+}
+
+// Enqueue implements Backend.
+func (b *SQLBackend) Enqueue(ctx context.Context, job Job) error {
+	if err := b.init(ctx); err != nil {
+		return err
+	}
+	_, err := b.db.ExecContext(ctx,
+		"INSERT INTO ? (id, queue, type, payload, attempts, max_attempts, run_at, enqueued_at, dead) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		bun.Ident(b.table), job.ID, job.Queue, job.Type, string(job.Payload), job.Attempts, job.MaxAttempts,
+		job.RunAt.UTC(), job.EnqueuedAt.UTC(), false)
+	if err != nil {
+		return fmt.Errorf("queue: failed to enqueue into %s: %w", b.table, err)
+	}
+	return nil
+}
+
+// Dequeue implements Backend. Jobs are reserved with a conditional update,
+// so concurrent workers never take the same job.
+func (b *SQLBackend) Dequeue(ctx context.Context, queue string) (*Job, error) {
+	if err := b.init(ctx); err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	var rows []jobRow
+	err := b.db.NewRaw(`SELECT id, queue, type, payload, attempts, max_attempts, run_at, enqueued_at, last_error FROM ?
+WHERE queue = ? AND dead = ? AND run_at <= ? AND (locked_until IS NULL OR locked_until < ?)
+ORDER BY run_at LIMIT 10`, bun.Ident(b.table), queue, false, now, now).Scan(ctx, &rows)
+	if err != nil {
+		return nil, fmt.Errorf("queue: failed to read %s: %w", b.table, err)
+	}
+
+	for _, row := range rows {
+		res, err := b.db.ExecContext(ctx,
+			"UPDATE ? SET locked_until = ? WHERE id = ? AND (locked_until IS NULL OR locked_until < ?)",
+			bun.Ident(b.table), now.Add(b.visibility), row.ID, now)
+		if err != nil {
+			return nil, fmt.Errorf("queue: failed to reserve job %s: %w", row.ID, err)
+		}
+		if affected, _ := res.RowsAffected(); affected == 1 {
+			job := row.job()
+			return &job, nil
+		}
+	}
+	return nil, nil
+}
+
+// Ack implements Backend.
+func (b *SQLBackend) Ack(ctx context.Context, job *Job) error {
+	_, err := b.db.ExecContext(ctx, "DELETE FROM ? WHERE id = ?", bun.Ident(b.table), job.ID)
+	return err
+}
+
+// Retry implements Backend.
+func (b *SQLBackend) Retry(ctx context.Context, job *Job) error {
+	_, err := b.db.ExecContext(ctx,
+		"UPDATE ? SET attempts = ?, run_at = ?, last_error = ?, locked_until = NULL WHERE id = ?",
+		bun.Ident(b.table), job.Attempts, job.RunAt.UTC(), job.LastError, job.ID)
+	return err
+}
+
+// DeadLetter implements Backend. Dead letters stay in the table with `dead` set.
+func (b *SQLBackend) DeadLetter(ctx context.Context, job *Job) error {
+	_, err := b.db.ExecContext(ctx,
+		"UPDATE ? SET attempts = ?, last_error = ?, locked_until = NULL, dead = ? WHERE id = ?",
+		bun.Ident(b.table), job.Attempts, job.LastError, true, job.ID)
+	return err
+}
+
+// DeadLetters implements DeadLetterReader.
+func (b *SQLBackend) DeadLetters(ctx context.Context, queue string, limit int) ([]Job, error) {
+	if err := b.init(ctx); err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = 100
+	}
+	var rows []jobRow
+	err := b.db.NewRaw(`SELECT id, queue, type, payload, attempts, max_attempts, run_at, enqueued_at, last_error FROM ?
+WHERE queue = ? AND dead = ? ORDER BY run_at DESC LIMIT ?`, bun.Ident(b.table), queue, true, limit).Scan(ctx, &rows)
+	if err != nil {
+		return nil, fmt.Errorf("queue: failed to read %s: %w", b.table, err)
+	}
+	jobs := make([]Job, len(rows))
+	for i, row := range rows {
+		jobs[i] = row.job()
+	}
+	return jobs, nil
+}
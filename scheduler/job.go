@@ -0,0 +1,93 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+)
+
+// Job is a task run on a schedule.
+type Job struct {
+	Name string
+	// Spec is a cron expression (with optional seconds, e.g. "*/5 * * * *" or "0 */10 * * * *"),
+	// a descriptor ("@hourly", "@daily") or an interval ("@every 30s").
+	Spec string
+	// Run is the task. ctx is canceled when the application stops or the timeout is reached.
+	Run func(ctx context.Context) error
+	// Jitter delays every run by a random duration up to this value.
+	Jitter time.Duration
+	// Timeout cancels a run taking longer than this value (no timeout when zero).
+	Timeout time.Duration
+	// Overlap allows a run to start while the previous one is still running.
+	Overlap bool
+	// SingleRunner runs each tick on a single replica, using the Locker provided to the app.
+	SingleRunner bool
+	// LockTTL is how long the lock of a tick is kept (default 10m). It must be longer than
+	// the clock skew between the replicas.
+	LockTTL time.Duration
+}
+
+// Every creates a job run at a fixed interval.
+//
+// Parameters:
+//   - name: The job name
+//   - interval: The interval between two runs
+//   - run: The task
+//
+// Returns:
+//   - Job: The job
+//
+// Example:
+//
+//	scheduler.Every("refresh-cache", 30*time.Second, cache.Refresh)
+func Every(name string, interval time.Duration, run func(ctx context.Context) error) Job {
+	return Job{Name: name, Spec: "@every " + interval.String(), Run: run}
+}
+
+// Cron creates a job run on a cron expression.
+//
+// Parameters:
+//   - name: The job name
+//   - spec: The cron expression (see Job.Spec)
+//   - run: The task
+//
+// Returns:
+//   - Job: The job
+//
+// Example:
+//
+//	scheduler.Cron("cleanup", "0 3 * * *", users.DeleteExpired).WithLock(time.Hour)
+func Cron(name, spec string, run func(ctx context.Context) error) Job {
+	return Job{Name: name, Spec: spec, Run: run}
+}
+
+// WithJitter delays every run by a random duration up to jitter.
+func (j Job) WithJitter(jitter time.Duration) Job {
+	j.Jitter = jitter
+	return j
+}
+
+// WithTimeout cancels a run taking longer than timeout.
+func (j Job) WithTimeout(timeout time.Duration) Job {
+	j.Timeout = timeout
+	return j
+}
+
+// WithOverlap allows a run to start while the previous one is still running.
+func (j Job) WithOverlap() Job {
+	j.Overlap = true
+	return j
+}
+
+// WithLock runs each tick on a single replica. The lock of a tick is kept for ttl
+// (the default when zero).
+func (j Job) WithLock(ttl time.Duration) Job {
+	j.SingleRunner = true
+	j.LockTTL = ttl
+	return j
+}
+
+// Locker takes the distributed locks of the SingleRunner jobs (e.g. redis.NewLocker).
+type Locker interface {
+	// Acquire takes the lock if no one holds it. The lock is released when ttl expires.
+	Acquire(ctx context.Context, key string, ttl time.Duration) (bool, error)
+}
@@ -0,0 +1,53 @@
+package scheduler
+
+import (
+	"reflect"
+
+	"github.com/go-liquor/liquor-sdk/app"
+	"go.uber.org/fx"
+)
+
+// SchedulerModule provides the *Scheduler and runs it as the "scheduler" worker.
+var SchedulerModule = fx.Module("liquor-scheduler", fx.Provide(
+	NewScheduler,
+),
+	app.RegisterWorkers(func(s *Scheduler) *Scheduler { return s }),
+)
+
+var (
+	jobType  = reflect.TypeOf(Job{})
+	jobsType = reflect.TypeOf([]Job{})
+)
+
+// RegisterJobs register your jobs. It accepts Job and []Job values and constructors
+// returning a Job or a []Job, so jobs can use the services of the app.
+//
+// Example:
+//
+//	app.NewApp(
+//	    scheduler.SchedulerModule,
+//	    scheduler.RegisterJobs(
+//	        scheduler.Every("heartbeat", time.Minute, sendHeartbeat),
+//	        func(users *services.UserService) scheduler.Job {
+//	            return scheduler.Cron("cleanup", "0 3 * * *", users.DeleteExpired).WithLock(time.Hour)
+//	        },
+//	    ),
+//	)
+func RegisterJobs(jobs ...any) fx.Option {
+	provides := make([]any, 0, len(jobs))
+	for _, j := range jobs {
+		switch v := j.(type) {
+		case Job:
+			provides = append(provides, fx.Annotate(func() Job { return v }, fx.ResultTags(`group:"liquor-jobs"`)))
+		case []Job:
+			provides = append(provides, fx.Annotate(func() []Job { return v }, fx.ResultTags(`group:"liquor-jobs,flatten"`)))
+		default:
+			tag := `group:"liquor-jobs"`
+			if t := reflect.TypeOf(j); t != nil && t.Kind() == reflect.Func && t.NumOut() > 0 && t.Out(0) == jobsType {
+				tag = `group:"liquor-jobs,flatten"`
+			}
+			provides = append(provides, fx.Annotate(j, fx.ResultTags(tag)))
+		}
+	}
+	return fx.Module("liquor-scheduler-jobs", fx.Provide(provides...))
+}
@@ -0,0 +1,274 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"runtime/debug"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-liquor/liquor-sdk/config"
+	"github.com/robfig/cron/v3"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+const defaultLockTTL = 10 * time.Minute
+
+var parser = cron.NewParser(cron.SecondOptional | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// JobStats are the counters of a job since the application started.
+type JobStats struct {
+	Runs         int64
+	Failures     int64
+	Panics       int64
+	Skipped      int64
+	Running      bool
+	LastStart    time.Time
+	LastDuration time.Duration
+	LastError    string
+	Next         time.Time
+}
+
+type scheduledJob struct {
+	Job
+	schedule cron.Schedule
+	running  atomic.Int32
+	mu       sync.Mutex
+	stats    JobStats
+}
+
+// Scheduler runs the registered jobs. It is registered as the "scheduler" worker, so it
+// starts with the application (or `worker scheduler`) and stops with it.
+type Scheduler struct {
+	jobs   []*scheduledJob
+	locker Locker
+	logger *zap.Logger
+}
+
+type schedulerParams struct {
+	fx.In
+
+	Config *config.Config
+	Logger *zap.Logger
+	Locker Locker `optional:"true"`
+	Jobs   []Job  `group:"liquor-jobs"`
+}
+
+// NewScheduler creates the scheduler of the registered jobs.
+//
+// A job schedule can be replaced with `scheduler.jobs.<name>.spec` and a job disabled
+// with `scheduler.jobs.<name>.disabled`.
+//
+// Returns:
+//   - *Scheduler: The scheduler
+//   - error: An error if a job is invalid
+func NewScheduler(p schedulerParams) (*Scheduler, error) {
+	s := &Scheduler{locker: p.Locker, logger: p.Logger}
+	names := map[string]bool{}
+	for _, job := range p.Jobs {
+		if job.Name == "" || job.Run == nil {
+			return nil, fmt.Errorf("scheduler: job %q needs a name and a run function", job.Name)
+		}
+		if names[job.Name] {
+			return nil, fmt.Errorf("scheduler: job %q is registered twice", job.Name)
+		}
+		names[job.Name] = true
+
+		key := "scheduler.jobs." + job.Name
+		if p.Config.GetBool(key + ".disabled") {
+			p.Logger.Info("scheduler job disabled", zap.String("job", job.Name))
+			continue
+		}
+		if spec := p.Config.GetString(key + ".spec"); spec != "" {
+			job.Spec = spec
+		}
+		schedule, err := parse(job.Spec)
+		if err != nil {
+			return nil, fmt.Errorf("scheduler: invalid spec %q for job %q (%s.spec): %w", job.Spec, job.Name, key, err)
+		}
+		if job.SingleRunner && p.Locker == nil {
+			return nil, fmt.Errorf("scheduler: job %q runs on a single replica and needs a scheduler.Locker (e.g. redis.LockerModule)", job.Name)
+		}
+		if job.LockTTL <= 0 {
+			job.LockTTL = defaultLockTTL
+		}
+		s.jobs = append(s.jobs, &scheduledJob{Job: job, schedule: schedule})
+	}
+	return s, nil
+}
+
+// Name implements app.Worker.
+func (s *Scheduler) Name() string {
+	return "scheduler"
+}
+
+// Run implements app.Worker: it runs the jobs until ctx is canceled, then waits
+// for the running jobs to return.
+func (s *Scheduler) Run(ctx context.Context) error {
+	var wg sync.WaitGroup
+	for _, job := range s.jobs {
+		wg.Add(1)
+		go func(job *scheduledJob) {
+			defer wg.Done()
+			s.loop(ctx, job, &wg)
+		}(job)
+	}
+	wg.Wait()
+	return nil
+}
+
+// Stats returns the counters of every job.
+//
+// Returns:
+//   - map[string]JobStats: The counters by job name
+func (s *Scheduler) Stats() map[string]JobStats {
+	stats := make(map[string]JobStats, len(s.jobs))
+	for _, job := range s.jobs {
+		job.mu.Lock()
+		stats[job.Name] = job.stats
+		job.mu.Unlock()
+	}
+	return stats
+}
+
+// Jobs returns the names of the scheduled jobs.
+func (s *Scheduler) Jobs() []string {
+	names := make([]string, len(s.jobs))
+	for i, job := range s.jobs {
+		names[i] = job.Name
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (s *Scheduler) loop(ctx context.Context, job *scheduledJob, wg *sync.WaitGroup) {
+	for {
+		tick := job.schedule.Next(time.Now())
+		at := tick
+		if job.Jitter > 0 {
+			at = at.Add(rand.N(job.Jitter))
+		}
+		job.update(func(stats *JobStats) { stats.Next = at })
+
+		timer := time.NewTimer(time.Until(at))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		if job.running.Add(1) > 1 && !job.Overlap {
+			job.running.Add(-1)
+			job.update(func(stats *JobStats) { stats.Skipped++ })
+			s.logger.Warn("scheduler job skipped: previous run still running", zap.String("job", job.Name))
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer job.running.Add(-1)
+			s.execute(ctx, job, tick)
+		}()
+	}
+}
+
+func (s *Scheduler) execute(ctx context.Context, job *scheduledJob, tick time.Time) {
+	log := s.logger.With(zap.String("job", job.Name))
+	if job.SingleRunner {
+		// the lock of a tick is not released: replicas with a shifted clock must not run it again
+		key := "scheduler:" + job.Name + ":" + strconv.FormatInt(tick.UnixMilli(), 10)
+		acquired, err := s.locker.Acquire(ctx, key, job.LockTTL)
+		if err != nil {
+			job.update(func(stats *JobStats) { stats.Failures++; stats.LastError = err.Error() })
+			log.Error("scheduler job failed to take its lock", zap.Error(err))
+			return
+		}
+		if !acquired {
+			job.update(func(stats *JobStats) { stats.Skipped++ })
+			log.Debug("scheduler job skipped: running on another replica")
+			return
+		}
+	}
+
+	runCtx := ctx
+	if job.Timeout > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeout(ctx, job.Timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	job.update(func(stats *JobStats) { stats.Running = true; stats.LastStart = start })
+	panicked, err := safeRun(runCtx, job.Run)
+	duration := time.Since(start)
+
+	job.update(func(stats *JobStats) {
+		stats.Running = job.running.Load() > 1
+		stats.Runs++
+		stats.LastDuration = duration
+		stats.LastError = ""
+		if err != nil {
+			stats.Failures++
+			stats.LastError = err.Error()
+		}
+		if panicked {
+			stats.Panics++
+		}
+	})
+
+	switch {
+	case panicked:
+		log.Error("scheduler job panicked", zap.Duration("duration", duration), zap.Error(err))
+	case err != nil && !errors.Is(err, context.Canceled):
+		log.Error("scheduler job failed", zap.Duration("duration", duration), zap.Error(err))
+	default:
+		log.Info("scheduler job ran", zap.Duration("duration", duration))
+	}
+}
+
+// interval runs a job at a fixed interval (cron.Every rounds the intervals to the second).
+// The ticks are aligned on the clock, so the replicas share the lock of a tick.
+type interval time.Duration
+
+func (i interval) Next(t time.Time) time.Time {
+	return t.Truncate(time.Duration(i)).Add(time.Duration(i))
+}
+
+func parse(spec string) (cron.Schedule, error) {
+	if every, ok := strings.CutPrefix(spec, "@every "); ok {
+		d, err := time.ParseDuration(strings.TrimSpace(every))
+		if err != nil {
+			return nil, err
+		}
+		if d <= 0 {
+			return nil, errors.New("the interval must be positive")
+		}
+		return interval(d), nil
+	}
+	return parser.Parse(spec)
+}
+
+func (j *scheduledJob) update(fn func(stats *JobStats)) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	fn(&j.stats)
+}
+
+// safeRun calls run and turns a panic into an error.
+func safeRun(ctx context.Context, run func(context.Context) error) (panicked bool, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v\n%s", r, debug.Stack())
+			panicked = true
+		}
+	}()
+	return false, run(ctx)
+}
@@ -0,0 +1,102 @@
+package seed
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-liquor/liquor-sdk/helpers/lqarray"
+	"go.uber.org/zap"
+)
+
+// Runner runs the seeders of an environment.
+type Runner struct {
+	store   Store
+	seeders []Seeder
+	env     string
+	logger  *zap.Logger
+}
+
+// NewRunner creates a Runner.
+//
+// Parameters:
+//   - store: The database store
+//   - seeders: The seeders, run in the given order
+//   - env: The current environment (seeders restricted to other environments are skipped)
+//   - logger: The logger (may be nil)
+//
+// Returns:
+//   - *Runner: The runner
+func NewRunner(store Store, seeders []Seeder, env string, logger *zap.Logger) *Runner {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &Runner{store: store, seeders: seeders, env: env, logger: logger}
+}
+
+// Seeders returns the seeders of the current environment.
+func (r *Runner) Seeders() []Seeder {
+	var selected []Seeder
+	for _, s := range r.seeders {
+		if len(s.Envs) == 0 || lqarray.Contains(s.Envs, r.env) {
+			selected = append(selected, s)
+		}
+	}
+	return selected
+}
+
+// Run upserts the fixtures and runs the Go code of the seeders. Fixtures are idempotent,
+// so Run can be called on a database already seeded.
+//
+// Parameters:
+//   - ctx: Context for the seeders
+//
+// Returns:
+//   - error: nil if successful, error otherwise
+func (r *Runner) Run(ctx context.Context) error {
+	for _, s := range r.Seeders() {
+		for _, fixture := range s.Fixtures {
+			if err := r.store.Upsert(ctx, fixture.Table, fixture.Key, fixture.Rows); err != nil {
+				return fmt.Errorf("seed: seeder %q failed: %w", s.Name, err)
+			}
+		}
+		if s.Run != nil {
+			if err := s.Run(ctx, r.store.Handle()); err != nil {
+				return fmt.Errorf("seed: seeder %q failed: %w", s.Name, err)
+			}
+		}
+		r.logger.Info("seeder ran", zap.String("seeder", s.Name), zap.String("env", r.env))
+	}
+	return nil
+}
+
+// Reload empties the tables of the seeders (in reverse order, so tables referencing
+// others are emptied first) and runs them again. It is meant for tests.
+//
+// Parameters:
+//   - ctx: Context for the seeders
+//
+// Returns:
+//   - error: nil if successful, error otherwise
+func (r *Runner) Reload(ctx context.Context) error {
+	if err := r.store.Truncate(ctx, r.Tables()...); err != nil {
+		return err
+	}
+	return r.Run(ctx)
+}
+
+// Tables returns the tables written by the seeders of the current environment,
+// in the order Reload empties them.
+func (r *Runner) Tables() []string {
+	var tables []string
+	seeders := r.Seeders()
+	for i := len(seeders) - 1; i >= 0; i-- {
+		s := seeders[i]
+		for j := len(s.Tables) - 1; j >= 0; j-- {
+			tables = append(tables, s.Tables[j])
+		}
+		for j := len(s.Fixtures) - 1; j >= 0; j-- {
+			tables = append(tables, s.Fixtures[j].Table)
+		}
+	}
+	return lqarray.Unique(tables)
+}
@@ -0,0 +1,180 @@
+package seed
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"math"
+	"path"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Func is the body of a Go seeder. db is the handle given by the store:
+// a bun.IDB for the sql stores and a *mongo.Database for MongoDB.
+type Func func(ctx context.Context, db any) error
+
+// Fixture is a set of rows loaded into a table (or collection).
+type Fixture struct {
+	// Table is the table (or collection) receiving the rows.
+	Table string `yaml:"table" json:"table"`
+	// Key lists the columns identifying a row: a row with the same key is updated
+	// instead of inserted, so loading a fixture twice is safe. Defaults to ["id"].
+	Key []string `yaml:"key" json:"key"`
+	// Rows are the rows, as column => value.
+	Rows []map[string]any `yaml:"rows" json:"rows"`
+}
+
+// Seeder populates a database with fixtures or Go code.
+type Seeder struct {
+	Name string
+	// Envs restricts the seeder to these environments (`app.env`); it runs in every
+	// environment when empty.
+	Envs []string
+	// Fixtures are upserted before Run is called.
+	Fixtures []Fixture
+	// Run is the Go code of the seeder (optional).
+	Run Func
+	// Tables are emptied by Runner.Reload, in addition to the tables of the fixtures.
+	Tables []string
+}
+
+// In restricts the seeder to the given environments.
+//
+// Example:
+//
+//	seed.Go("demo_users", createDemoUsers).In("dev", "test")
+func (s Seeder) In(envs ...string) Seeder {
+	s.Envs = append(append([]string(nil), s.Envs...), envs...)
+	return s
+}
+
+// Truncating declares the tables the Go code writes to, so Runner.Reload empties them.
+func (s Seeder) Truncating(tables ...string) Seeder {
+	s.Tables = append(append([]string(nil), s.Tables...), tables...)
+	return s
+}
+
+// Go creates a seeder written in Go.
+//
+// Parameters:
+//   - name: The seeder name
+//   - run: The function populating the database
+//
+// Returns:
+//   - Seeder: The seeder
+//
+// Example:
+//
+//	seed.Go("admin", func(ctx context.Context, db bun.IDB) error {
+//	    return seed.UpsertModels(ctx, db, &[]User{{ID: 1, Name: "admin"}}, "id")
+//	}).Truncating("users")
+func Go[DB any](name string, run func(ctx context.Context, db DB) error) Seeder {
+	return Seeder{
+		Name: name,
+		Run: func(ctx context.Context, db any) error {
+			handle, ok := db.(DB)
+			if !ok {
+				return fmt.Errorf("seed: seeder %q expects a %s handle, the store gives %T",
+					name, reflect.TypeOf((*DB)(nil)).Elem(), db)
+			}
+			return run(ctx, handle)
+		},
+	}
+}
+
+// Fixtures creates a seeder loading fixtures.
+//
+// Parameters:
+//   - name: The seeder name
+//   - fixtures: The fixtures
+//
+// Returns:
+//   - Seeder: The seeder
+func Fixtures(name string, fixtures ...Fixture) Seeder {
+	return Seeder{Name: name, Fixtures: fixtures}
+}
+
+// file is the content of a fixture file.
+type file struct {
+	Envs     []string  `yaml:"envs" json:"envs"`
+	Fixtures []Fixture `yaml:"fixtures" json:"fixtures"`
+}
+
+// FromFS reads the fixture files (.yaml, .yml or .json) of a directory. Each file is a
+// seeder named after the file; the seeders are sorted by file name.
+//
+// A file has the following format:
+//
+//	envs: [dev, test] # optional
+//	fixtures:
+//	  - table: users
+//	    key: [id]
+//	    rows:
+//	      - id: 1
+//	        name: admin
+//
+// Parameters:
+//   - fsys: The file system (e.g. an embed.FS)
+//   - dir: The directory holding the files ("." for the root)
+//
+// Returns:
+//   - []Seeder: The seeders
+//   - error: nil if successful, error otherwise
+func FromFS(fsys fs.FS, dir string) ([]Seeder, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("seed: failed to read fixtures in %q: %w", dir, err)
+	}
+	// fs.ReadDir sorts the entries by file name
+	var seeders []Seeder
+	for _, entry := range entries {
+		ext := path.Ext(entry.Name())
+		if entry.IsDir() || (ext != ".yaml" && ext != ".yml" && ext != ".json") {
+			continue
+		}
+		content, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("seed: failed to read %q: %w", entry.Name(), err)
+		}
+		var f file
+		if ext == ".json" {
+			if err = json.Unmarshal(content, &f); err == nil {
+				normalizeNumbers(f.Fixtures)
+			}
+		} else {
+			err = yaml.Unmarshal(content, &f)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("seed: failed to parse %q: %w", entry.Name(), err)
+		}
+		for i, fixture := range f.Fixtures {
+			if fixture.Table == "" {
+				return nil, fmt.Errorf("seed: fixture %d of %q has no table", i, entry.Name())
+			}
+		}
+		seeders = append(seeders, Seeder{
+			Name:     strings.TrimSuffix(entry.Name(), ext),
+			Envs:     f.Envs,
+			Fixtures: f.Fixtures,
+		})
+	}
+	return seeders, nil
+}
+
+// normalizeNumbers converts the integral JSON numbers (decoded as float64) to int64,
+// as the YAML decoder does.
+func normalizeNumbers(fixtures []Fixture) {
+	for _, fixture := range fixtures {
+		for _, row := range fixture.Rows {
+			for column, value := range row {
+				if f, ok := value.(float64); ok && f == math.Trunc(f) && math.Abs(f) < 1<<53 {
+					row[column] = int64(f)
+				}
+			}
+		}
+	}
+}
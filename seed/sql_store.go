@@ -0,0 +1,160 @@
+package seed
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect"
+)
+
+// SQLStore is the Store of the bun databases (postgres, mysql and sqlite).
+// Go seeders receive the *bun.DB as a bun.IDB.
+type SQLStore struct {
+	db *bun.DB
+}
+
+// NewSQLStore creates a Store for a bun database.
+//
+// Parameters:
+//   - db: The database
+//
+// Returns:
+//   - *SQLStore: The store
+func NewSQLStore(db *bun.DB) *SQLStore {
+	return &SQLStore{db: db}
+}
+
+// Handle implements Store.
+func (s *SQLStore) Handle() any {
+	return bun.IDB(s.db)
+}
+
+// Upsert implements Store.
+func (s *SQLStore) Upsert(ctx context.Context, table string, key []string, rows []map[string]any) error {
+	return s.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		for _, row := range rows {
+			columns := make([]string, 0, len(row))
+			for column := range row {
+				columns = append(columns, column)
+			}
+			sort.Strings(columns)
+
+			values := row
+			q := tx.NewInsert().Model(&values).TableExpr("?", bun.Ident(table))
+			if _, err := onConflict(q, tx.Dialect().Name(), key, columns).Exec(ctx); err != nil {
+				return fmt.Errorf("seed: failed to upsert into %s: %w", table, err)
+			}
+		}
+		return nil
+	})
+}
+
+// Truncate implements Store. On postgres the tables are truncated in one statement without
+// CASCADE, so the foreign keys between them are accepted and a table outside of them
+// referencing one fails the truncate instead of being emptied too.
+func (s *SQLStore) Truncate(ctx context.Context, tables ...string) error {
+	if len(tables) == 0 {
+		return nil
+	}
+	if s.db.Dialect().Name() == dialect.PG {
+		idents := make([]bun.Ident, len(tables))
+		for i, table := range tables {
+			idents[i] = bun.Ident(table)
+		}
+		if _, err := s.db.ExecContext(ctx, "TRUNCATE TABLE ? RESTART IDENTITY", bun.In(idents)); err != nil {
+			return fmt.Errorf("seed: failed to truncate %s: %w", strings.Join(tables, ", "), err)
+		}
+		return nil
+	}
+	for _, table := range tables {
+		if _, err := s.db.ExecContext(ctx, "DELETE FROM ?", bun.Ident(table)); err != nil {
+			return fmt.Errorf("seed: failed to truncate %s: %w", table, err)
+		}
+	}
+	return nil
+}
+
+// UpsertModels inserts bun models, updating the rows with the same key columns.
+//
+// Parameters:
+//   - ctx: Context for the query
+//   - db: The database (or transaction)
+//   - models: A pointer to a model or to a slice of models
+//   - key: The columns identifying a row (defaults to the primary key)
+//
+// Returns:
+//   - error: nil if successful, error otherwise
+//
+// Example:
+//
+//	err := seed.UpsertModels(ctx, db, &[]User{{ID: 1, Name: "admin"}})
+func UpsertModels(ctx context.Context, db bun.IDB, models any, key ...string) error {
+	typ := reflect.TypeOf(models)
+	for typ.Kind() == reflect.Pointer || typ.Kind() == reflect.Slice {
+		typ = typ.Elem()
+	}
+	table := db.Dialect().Tables().Get(typ)
+
+	if len(key) == 0 {
+		for _, field := range table.PKs {
+			key = append(key, field.Name)
+		}
+	}
+	columns := make([]string, 0, len(table.Fields))
+	for _, field := range table.Fields {
+		columns = append(columns, field.Name)
+	}
+
+	q := db.NewInsert().Model(models)
+	if _, err := onConflict(q, db.Dialect().Name(), key, columns).Exec(ctx); err != nil {
+		return fmt.Errorf("seed: failed to upsert into %s: %w", table.Name, err)
+	}
+	return nil
+}
+
+// onConflict turns an insert into an upsert on the key columns.
+func onConflict(q *bun.InsertQuery, name dialect.Name, key, columns []string) *bun.InsertQuery {
+	if len(key) == 0 {
+		key = []string{"id"}
+	}
+	isKey := make(map[string]bool, len(key))
+	for _, k := range key {
+		isKey[k] = true
+	}
+
+	var update []string
+	for _, column := range columns {
+		if !isKey[column] {
+			update = append(update, column)
+		}
+	}
+
+	if name == dialect.MySQL {
+		if len(update) == 0 {
+			// a no-op update keeps the existing row
+			return q.On("DUPLICATE KEY UPDATE ? = ?", bun.Ident(key[0]), bun.Ident(key[0]))
+		}
+		q = q.On("DUPLICATE KEY UPDATE")
+		for _, column := range update {
+			q = q.Set("? = VALUES(?)", bun.Ident(column), bun.Ident(column))
+		}
+		return q
+	}
+
+	target := make([]bun.Ident, len(key))
+	for i, k := range key {
+		target[i] = bun.Ident(k)
+	}
+	if len(update) == 0 {
+		return q.On("CONFLICT (?) DO NOTHING", bun.In(target))
+	}
+	q = q.On("CONFLICT (?) DO UPDATE", bun.In(target))
+	for _, column := range update {
+		q = q.Set("? = EXCLUDED.?", bun.Ident(column), bun.Ident(column))
+	}
+	return q
+}
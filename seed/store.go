@@ -0,0 +1,13 @@
+package seed
+
+import "context"
+
+// Store writes the seeds into a database.
+type Store interface {
+	// Upsert inserts the rows, updating the rows with the same key columns.
+	Upsert(ctx context.Context, table string, key []string, rows []map[string]any) error
+	// Truncate removes every row of the tables, in the given order.
+	Truncate(ctx context.Context, tables ...string) error
+	// Handle returns the handle given to Go seeders.
+	Handle() any
+}
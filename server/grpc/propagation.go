@@ -0,0 +1,50 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/go-liquor/liquor-sdk/helpers/lqcontext"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// PropagationUnaryInterceptor stores the request ID, bearer token and trace context of the
+// incoming metadata in the context of the calls, forwarded by the liquor clients. GrpcModule
+// installs it.
+//
+// Returns:
+//   - grpc.UnaryServerInterceptor: The interceptor
+func PropagationUnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		return handler(propagate(ctx), req)
+	}
+}
+
+// PropagationStreamInterceptor is the PropagationUnaryInterceptor of the streams.
+//
+// Returns:
+//   - grpc.StreamServerInterceptor: The interceptor
+func PropagationStreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &propagatedStream{ServerStream: ss, ctx: propagate(ss.Context())})
+	}
+}
+
+type propagatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *propagatedStream) Context() context.Context {
+	return s.ctx
+}
+
+func propagate(ctx context.Context) context.Context {
+	md, _ := metadata.FromIncomingContext(ctx)
+	return lqcontext.FromHeaders(ctx, func(name string) string {
+		if values := md.Get(name); len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	})
+}
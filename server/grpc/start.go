@@ -11,30 +11,117 @@ import (
 	"google.golang.org/grpc"
 )
 
+// GrpcModule provides the *grpc.Server and serves it on `server.grpc.port`. The request ID,
+// bearer token and trace context of the calls are stored in their context (see lqcontext).
+var GrpcModule = fx.Module("liquor-grpc-server", fx.Provide(newServer), fx.Invoke(registerServices, startServer))
+
+type serverParams struct {
+	fx.In
+
+	Options []grpc.ServerOption `group:"liquor-grpc-server-options"`
+}
+
+func newServer(p serverParams) *grpc.Server {
+	options := append([]grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(PropagationUnaryInterceptor()),
+		grpc.ChainStreamInterceptor(PropagationStreamInterceptor()),
+	}, p.Options...)
+	return grpc.NewServer(options...)
+}
+
+// RegisterServerOptions register options of the server provided by GrpcModule, e.g. the
+// interceptors. It accepts grpc.ServerOption values and constructors returning one. The
+// chained interceptors of several options run in no particular order.
+//
+// Example:
+//
+//	app.NewApp(
+//	    app.WithGRPC(),
+//	    grpc.RegisterServerOptions(
+//	        ggrpc.MaxRecvMsgSize(16<<20),
+//	        func(logger *zap.Logger) ggrpc.ServerOption {
+//	            return ggrpc.ChainUnaryInterceptor(NewLoggingInterceptor(logger))
+//	        },
+//	    ),
+//	)
+func RegisterServerOptions(options ...any) fx.Option {
+	provides := make([]any, len(options))
+	for i, o := range options {
+		if option, ok := o.(grpc.ServerOption); ok {
+			o = func() grpc.ServerOption { return option }
+		}
+		provides[i] = fx.Annotate(o, fx.ResultTags(`group:"liquor-grpc-server-options"`))
+	}
+	return fx.Module("liquor-grpc-server-options", fx.Provide(provides...))
+}
+
+// RegisterGRPCServer registers a service and starts a gRPC server for it.
+// Use RegisterGRPCService with GrpcModule (or app.WithGRPC) to serve several services.
 func RegisterGRPCServer[T any, A any](implementation T, instance A, register func(imp T, registrar *grpc.Server)) fx.Option {
-	return fx.Module("liquor-grpc-server", fx.Provide(instance), fx.Provide(func() *grpc.Server {
-		return grpc.NewServer()
-	}), fx.Invoke(register),
-		fx.Invoke(
-			func(cfg *config.Config, logger *zap.Logger, svc *grpc.Server, lc fx.Lifecycle) {
-				lc.Append(fx.Hook{
-					OnStart: func(ctx context.Context) error {
-						logger.Info("starting grpc server", zap.Int64("port", cfg.GetServerGrpcPort()))
-						lis, err := net.Listen("tcp", fmt.Sprintf(":%d", cfg.GetServerGrpcPort()))
-						if err != nil {
-							logger.Fatal("failed to start tcp grpc", zap.Error(err))
-							return err
-						}
-						go svc.Serve(lis)
-						return nil
-					},
-					OnStop: func(ctx context.Context) error {
-						logger.Info("stopping grpc server")
-						svc.GracefulStop()
-						return nil
-					},
-				})
-
-			},
-		))
+	return fx.Options(GrpcModule, RegisterGRPCService(implementation, instance, register))
+}
+
+// serviceRegistration registers a service of RegisterGRPCService on the server.
+type serviceRegistration func(registrar *grpc.Server)
+
+type servicesParams struct {
+	fx.In
+
+	Server   *grpc.Server
+	Services []serviceRegistration `group:"liquor-grpc-services"`
+}
+
+func registerServices(p servicesParams) {
+	for _, register := range p.Services {
+		register(p.Server)
+	}
+}
+
+// RegisterGRPCService registers a service on the server provided by GrpcModule. The
+// service and its dependencies are only built when the app runs GrpcModule.
+//
+// Parameters:
+//   - implementation: The service implementation type (used for type inference)
+//   - instance: The service implementation constructor
+//   - register: The generated register function (e.g. pb.RegisterUsersServer)
+//
+// Returns:
+//   - fx.Option: Fx option registering the service
+//
+// Example:
+//
+//	app.NewApp(
+//	    app.WithGRPC(),
+//	    grpc.RegisterGRPCService(&UsersServer{}, NewUsersServer, pb.RegisterUsersServer),
+//	)
+func RegisterGRPCService[T any, A any](implementation T, instance A, register func(imp T, registrar *grpc.Server)) fx.Option {
+	return fx.Module("liquor-grpc-service", fx.Provide(instance), fx.Provide(fx.Annotate(
+		func(imp T) serviceRegistration {
+			return func(registrar *grpc.Server) { register(imp, registrar) }
+		},
+		fx.ResultTags(`group:"liquor-grpc-services"`),
+	)))
+}
+
+func startServer(cfg *config.Config, logger *zap.Logger, svc *grpc.Server, lc fx.Lifecycle) {
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			logger.Info("starting grpc server", zap.Int64("port", cfg.GetServerGrpcPort()))
+			lis, err := net.Listen("tcp", fmt.Sprintf(":%d", cfg.GetServerGrpcPort()))
+			if err != nil {
+				return fmt.Errorf("failed to start tcp grpc on port %d: %w", cfg.GetServerGrpcPort(), err)
+			}
+			go func() {
+				if err := svc.Serve(lis); err != nil {
+					logger.Error("grpc server stopped", zap.Error(err))
+				}
+			}()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			logger.Info("stopping grpc server")
+			svc.GracefulStop()
+			return nil
+		},
+	})
 }
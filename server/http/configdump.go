@@ -0,0 +1,58 @@
+package http
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	nethttp "net/http"
+	"strings"
+
+	"github.com/go-liquor/liquor-sdk/config"
+)
+
+// configDump is the body of `/-/config`.
+type configDump struct {
+	Env     string           `json:"env"`
+	Sources []string         `json:"sources"`
+	Keys    []config.KeyInfo `json:"keys"`
+}
+
+// configEndpoint returns the handler of `/-/config`, serving the redacted configuration
+// with the source of each key (see config.Config.Inspect), or nil when it is disabled.
+//
+// Config keys:
+//   - config.endpoint.enabled: Serves `/-/config` (default false)
+//   - config.endpoint.token: The bearer token required by the endpoint (required when enabled)
+func configEndpoint(cfg *config.Config) (nethttp.Handler, error) {
+	if !cfg.GetBool("config.endpoint.enabled") {
+		return nil, nil
+	}
+	if cfg.GetString("config.endpoint.token") == "" {
+		return nil, errors.New("http: config.endpoint.token is required to serve /-/config")
+	}
+	return nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		// the token is read on each request, so it follows the reloads
+		token := []byte(cfg.GetString("config.endpoint.token"))
+		given, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || len(token) == 0 || subtle.ConstantTimeCompare([]byte(given), token) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			nethttp.Error(w, "unauthorized", nethttp.StatusUnauthorized)
+			return
+		}
+		dump := configDump{Env: cfg.GetAppEnv(), Keys: cfg.Inspect()}
+		for _, s := range cfg.Sources() {
+			dump.Sources = append(dump.Sources, s.String())
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(dump)
+	}), nil
+}
+
+// registerOpsConfigEndpoint serves `/-/config` on the ops listener when it is enabled.
+func registerOpsConfigEndpoint(cfg *config.Config, ops *OpsServer) error {
+	handler, err := configEndpoint(cfg)
+	if handler != nil {
+		ops.Handle("/-/config", handler)
+	}
+	return err
+}
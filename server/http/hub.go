@@ -0,0 +1,316 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	nethttp "net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-liquor/liquor-sdk/config"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// ErrTooManyConnections is returned when the hub reached `server.http.realtime.maxConnections`.
+var ErrTooManyConnections = errors.New("too many realtime connections")
+
+// ErrHubClosed is returned when the hub is shutting down.
+var ErrHubClosed = errors.New("realtime hub is closed")
+
+// ErrInvalidEvent is returned for an event name with a line break, which would inject
+// fields in the SSE stream.
+var ErrInvalidEvent = errors.New("realtime event names can't contain line breaks")
+
+// Message is a payload delivered to realtime connections.
+type Message struct {
+	// Event is the event name (SSE `event:` field, WebSocket envelope `event`).
+	Event string `json:"event,omitempty"`
+	// Data is the encoded payload.
+	Data json.RawMessage `json:"data"`
+	// UserID restricts the delivery to the connections of a user. Empty means broadcast.
+	UserID string `json:"userId,omitempty"`
+}
+
+// HubBroker fans hub messages out across application instances (e.g. through Redis pub/sub).
+// Messages are exchanged as JSON-encoded Message payloads.
+type HubBroker interface {
+	// Publish sends a payload to every instance, including the current one.
+	Publish(ctx context.Context, payload []byte) error
+	// Subscribe delivers the published payloads to handler until ctx is canceled.
+	Subscribe(ctx context.Context, handler func(payload []byte)) error
+}
+
+// Connection is a realtime client attached to the hub.
+type Connection struct {
+	ID     uint64
+	UserID string
+
+	send      chan Message
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// Messages returns the channel with the messages to deliver to the client.
+func (c *Connection) Messages() <-chan Message {
+	return c.send
+}
+
+// Done is closed when the connection is removed from the hub.
+func (c *Connection) Done() <-chan struct{} {
+	return c.done
+}
+
+func (c *Connection) close() {
+	c.closeOnce.Do(func() { close(c.done) })
+}
+
+// Hub keeps track of realtime connections and delivers broadcast and per-user messages.
+type Hub struct {
+	logger         *zap.Logger
+	broker         HubBroker
+	bufferSize     int
+	heartbeat      time.Duration
+	maxConnections int
+	allowOrigins   atomic.Pointer[[]string]
+
+	mu     sync.RWMutex
+	conns  map[uint64]*Connection
+	nextID atomic.Uint64
+	closed bool
+	cancel context.CancelFunc
+}
+
+type hubParams struct {
+	fx.In
+
+	Config *config.Config
+	Logger *zap.Logger
+	Lc     fx.Lifecycle
+	Broker HubBroker `optional:"true"`
+}
+
+// NewHub creates the realtime connection hub. When a HubBroker is provided, messages are
+// fanned out through it so every instance delivers them to its own connections.
+//
+// Config keys (under `server.http.realtime`):
+//   - bufferSize: messages buffered per connection before it is dropped as slow (default 64)
+//   - heartbeat: interval of keep-alive pings (default 25s)
+//   - maxConnections: maximum concurrent connections, 0 for unlimited
+//
+// WebSocket upgrades are accepted from the same host or from the `server.http.cors.origin` list
+// (updated on reload).
+func NewHub(p hubParams) *Hub {
+	h := &Hub{
+		logger:         p.Logger,
+		broker:         p.Broker,
+		bufferSize:     p.Config.GetInt("server.http.realtime.bufferSize"),
+		heartbeat:      p.Config.GetDuration("server.http.realtime.heartbeat"),
+		maxConnections: p.Config.GetInt("server.http.realtime.maxConnections"),
+		conns:          map[uint64]*Connection{},
+	}
+	h.setAllowOrigins(p.Config)
+	p.Config.OnChange("server.http.cors", h.setAllowOrigins)
+	if h.bufferSize <= 0 {
+		h.bufferSize = 64
+	}
+	if h.heartbeat <= 0 {
+		h.heartbeat = 25 * time.Second
+	}
+
+	p.Lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			if h.broker == nil {
+				return nil
+			}
+			ctx, cancel := context.WithCancel(context.Background())
+			h.cancel = cancel
+			go func() {
+				if err := h.broker.Subscribe(ctx, h.receive); err != nil && ctx.Err() == nil {
+					h.logger.Error("realtime broker subscription stopped", zap.Error(err))
+				}
+			}()
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			h.logger.Info("closing realtime connections", zap.Int("connections", h.Connections()))
+			h.Close()
+			return nil
+		},
+	})
+	return h
+}
+
+// Attach registers a new connection for a user (empty for anonymous connections).
+//
+// Parameters:
+//   - userID: The user owning the connection
+//
+// Returns:
+//   - *Connection: The connection
+//   - error: ErrTooManyConnections or ErrHubClosed
+func (h *Hub) Attach(userID string) (*Connection, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return nil, ErrHubClosed
+	}
+	if h.maxConnections > 0 && len(h.conns) >= h.maxConnections {
+		return nil, ErrTooManyConnections
+	}
+	conn := &Connection{
+		ID:     h.nextID.Add(1),
+		UserID: userID,
+		send:   make(chan Message, h.bufferSize),
+		done:   make(chan struct{}),
+	}
+	h.conns[conn.ID] = conn
+	return conn, nil
+}
+
+// Detach removes a connection from the hub.
+//
+// Parameters:
+//   - conn: The connection to remove
+func (h *Hub) Detach(conn *Connection) {
+	h.mu.Lock()
+	delete(h.conns, conn.ID)
+	h.mu.Unlock()
+	conn.close()
+}
+
+// Broadcast sends an event to every connection.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - event: The event name
+//   - data: The payload, encoded as JSON
+//
+// Returns:
+//   - error: nil if successful, ErrInvalidEvent or error otherwise
+func (h *Hub) Broadcast(ctx context.Context, event string, data any) error {
+	return h.publish(ctx, "", event, data)
+}
+
+// SendToUser sends an event to every connection of a user.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - userID: The target user
+//   - event: The event name
+//   - data: The payload, encoded as JSON
+//
+// Returns:
+//   - error: nil if successful, ErrInvalidEvent or error otherwise
+func (h *Hub) SendToUser(ctx context.Context, userID, event string, data any) error {
+	return h.publish(ctx, userID, event, data)
+}
+
+// Connections returns the number of attached connections.
+func (h *Hub) Connections() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.conns)
+}
+
+// Heartbeat returns the keep-alive interval used by the handlers.
+func (h *Hub) Heartbeat() time.Duration {
+	return h.heartbeat
+}
+
+// Close detaches every connection and rejects new ones.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	h.closed = true
+	conns := h.conns
+	h.conns = map[uint64]*Connection{}
+	h.mu.Unlock()
+	if h.cancel != nil {
+		h.cancel()
+	}
+	for _, conn := range conns {
+		conn.close()
+	}
+}
+
+func (h *Hub) setAllowOrigins(cfg *config.Config) {
+	origins := []string{"*"}
+	if !cfg.GetServerHttpCorsDefaultAllow() {
+		origins = cfg.GetServerHttpCorsAllowOrigins()
+	}
+	h.allowOrigins.Store(&origins)
+}
+
+func (h *Hub) checkOrigin(r *nethttp.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	for _, allowed := range *h.allowOrigins.Load() {
+		if allowed == "*" || allowed == origin {
+			return true
+		}
+	}
+	u, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(u.Host, r.Host)
+}
+
+func (h *Hub) publish(ctx context.Context, userID, event string, data any) error {
+	if !validEvent(event) {
+		return ErrInvalidEvent
+	}
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	msg := Message{Event: event, Data: payload, UserID: userID}
+	if h.broker != nil {
+		encoded, err := json.Marshal(msg)
+		if err != nil {
+			return err
+		}
+		return h.broker.Publish(ctx, encoded)
+	}
+	h.deliver(msg)
+	return nil
+}
+
+func (h *Hub) receive(payload []byte) {
+	var msg Message
+	if err := json.Unmarshal(payload, &msg); err != nil {
+		h.logger.Warn("invalid realtime broker message", zap.Error(err))
+		return
+	}
+	h.deliver(msg)
+}
+
+// deliver hands the message to the local connections. Connections whose buffer is full
+// are dropped so a slow client cannot hold the publisher back.
+func (h *Hub) deliver(msg Message) {
+	h.mu.RLock()
+	var slow []*Connection
+	for _, conn := range h.conns {
+		if msg.UserID != "" && conn.UserID != msg.UserID {
+			continue
+		}
+		select {
+		case conn.send <- msg:
+		default:
+			slow = append(slow, conn)
+		}
+	}
+	h.mu.RUnlock()
+
+	for _, conn := range slow {
+		h.logger.Warn("dropping slow realtime connection", zap.Uint64("connection", conn.ID), zap.String("userId", conn.UserID))
+		h.Detach(conn)
+	}
+}
+
+func validEvent(event string) bool {
+	return !strings.ContainsAny(event, "\r\n")
+}
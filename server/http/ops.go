@@ -0,0 +1,73 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	nethttp "net/http"
+
+	"github.com/go-liquor/liquor-sdk/config"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// OpsServer is a minimal HTTP listener for probes and metrics, used when the main
+// HTTP server is disabled (e.g. workers and gRPC-only services).
+type OpsServer struct {
+	mux *nethttp.ServeMux
+}
+
+// NewOpsServer creates the ops listener with the `/-/health` probe.
+//
+// Returns:
+//   - *OpsServer: The ops server
+func NewOpsServer() *OpsServer {
+	mux := nethttp.NewServeMux()
+	mux.HandleFunc("/-/health", func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status":"ok"}`))
+	})
+	return &OpsServer{mux: mux}
+}
+
+// Handle registers an extra handler (e.g. metrics) on the ops listener.
+//
+// Parameters:
+//   - pattern: The route pattern
+//   - handler: The handler
+func (o *OpsServer) Handle(pattern string, handler nethttp.Handler) {
+	o.mux.Handle(pattern, handler)
+}
+
+// opsPort returns the port of the ops listener from `server.ops.port` (default 8081).
+func opsPort(cfg *config.Config) int64 {
+	if port := cfg.GetInt64("server.ops.port"); port > 0 {
+		return port
+	}
+	return 8081
+}
+
+func startOpsServer(cfg *config.Config, ops *OpsServer, lg *zap.Logger, lc fx.Lifecycle) {
+	srv := &nethttp.Server{Handler: ops.mux}
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			port := opsPort(cfg)
+			lg.Info("starting ops HTTP server", zap.Int64("port", port))
+			lis, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+			if err != nil {
+				return fmt.Errorf("failed to start ops HTTP server on port %d: %w", port, err)
+			}
+			go func() {
+				if err := srv.Serve(lis); err != nil && !errors.Is(err, nethttp.ErrServerClosed) {
+					lg.Error("ops HTTP server stopped", zap.Error(err))
+				}
+			}()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			lg.Info("stopping ops HTTP server")
+			return srv.Shutdown(ctx)
+		},
+	})
+}
@@ -0,0 +1,184 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	nethttp "net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+)
+
+// UserResolver extracts the user owning a realtime connection from the request.
+// Returning an empty string attaches an anonymous connection that only receives broadcasts.
+type UserResolver func(c *gin.Context) string
+
+// SSEHandler creates a Server-Sent Events endpoint streaming the hub messages.
+//
+// Parameters:
+//   - hub: The realtime hub
+//   - user: Resolves the user of the connection (optional)
+//
+// Returns:
+//   - gin.HandlerFunc: The handler
+//
+// Example:
+//
+//	NewRestModule("live", func(server *gin.Engine, hub *http.Hub) {
+//	    server.GET("/events", http.SSEHandler(hub, func(c *gin.Context) string {
+//	        return c.GetString("userId")
+//	    }))
+//	})
+func SSEHandler(hub *Hub, user UserResolver) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		conn, err := attach(hub, c, user)
+		if err != nil {
+			return
+		}
+		defer hub.Detach(conn)
+
+		c.Header("Content-Type", "text/event-stream")
+		c.Header("Cache-Control", "no-cache")
+		c.Header("Connection", "keep-alive")
+		c.Header("X-Accel-Buffering", "no")
+		c.Status(nethttp.StatusOK)
+		c.Writer.Flush()
+
+		ticker := time.NewTicker(hub.Heartbeat())
+		defer ticker.Stop()
+		for {
+			select {
+			case <-c.Request.Context().Done():
+				return
+			case <-conn.Done():
+				return
+			case <-ticker.C:
+				if _, err := fmt.Fprint(c.Writer, ": ping\n\n"); err != nil {
+					return
+				}
+			case msg := <-conn.Messages():
+				if !validEvent(msg.Event) {
+					// published by an instance without the check
+					continue
+				}
+				if msg.Event != "" {
+					if _, err := fmt.Fprintf(c.Writer, "event: %s\n", msg.Event); err != nil {
+						return
+					}
+				}
+				if _, err := fmt.Fprintf(c.Writer, "data: %s\n\n", msg.Data); err != nil {
+					return
+				}
+			}
+			c.Writer.Flush()
+		}
+	}
+}
+
+// WebSocketMessageHandler handles a message received from a WebSocket client.
+type WebSocketMessageHandler func(ctx context.Context, conn *Connection, data []byte)
+
+// WebSocketHandler creates a WebSocket endpoint. Hub messages are sent as JSON
+// `{"event": "...", "data": ...}` text frames and received frames are passed to onMessage.
+//
+// Parameters:
+//   - hub: The realtime hub
+//   - user: Resolves the user of the connection (optional)
+//   - onMessage: Handles messages sent by the client (optional)
+//
+// Returns:
+//   - gin.HandlerFunc: The handler
+//
+// Example:
+//
+//	server.GET("/ws", http.WebSocketHandler(hub, nil, func(ctx context.Context, conn *http.Connection, data []byte) {
+//	    hub.Broadcast(ctx, "chat", string(data))
+//	}))
+func WebSocketHandler(hub *Hub, user UserResolver, onMessage WebSocketMessageHandler) gin.HandlerFunc {
+	upgrader := websocket.Upgrader{
+		ReadBufferSize:  1024,
+		WriteBufferSize: 1024,
+		CheckOrigin:     hub.checkOrigin,
+	}
+	return func(c *gin.Context) {
+		conn, err := attach(hub, c, user)
+		if err != nil {
+			return
+		}
+		defer hub.Detach(conn)
+
+		ws, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+		if err != nil {
+			hub.logger.Debug("websocket upgrade failed", zap.Error(err))
+			return
+		}
+		defer ws.Close()
+
+		ctx, cancel := context.WithCancel(c.Request.Context())
+		defer cancel()
+		go readWebSocket(ctx, cancel, hub, ws, conn, onMessage)
+
+		ticker := time.NewTicker(hub.Heartbeat())
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-conn.Done():
+				ws.WriteControl(websocket.CloseMessage,
+					websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutting down"),
+					time.Now().Add(time.Second))
+				return
+			case <-ticker.C:
+				if err := ws.WriteControl(websocket.PingMessage, nil, time.Now().Add(10*time.Second)); err != nil {
+					return
+				}
+			case msg := <-conn.Messages():
+				ws.SetWriteDeadline(time.Now().Add(10 * time.Second))
+				if err := ws.WriteJSON(msg); err != nil {
+					return
+				}
+			}
+		}
+	}
+}
+
+func readWebSocket(ctx context.Context, cancel context.CancelFunc, hub *Hub, ws *websocket.Conn, conn *Connection, onMessage WebSocketMessageHandler) {
+	defer cancel()
+	wait := 2 * hub.Heartbeat()
+	ws.SetReadLimit(64 << 10)
+	ws.SetReadDeadline(time.Now().Add(wait))
+	ws.SetPongHandler(func(string) error {
+		return ws.SetReadDeadline(time.Now().Add(wait))
+	})
+	for {
+		_, data, err := ws.ReadMessage()
+		if err != nil {
+			return
+		}
+		ws.SetReadDeadline(time.Now().Add(wait))
+		if onMessage != nil {
+			onMessage(ctx, conn, data)
+		}
+	}
+}
+
+func attach(hub *Hub, c *gin.Context, user UserResolver) (*Connection, error) {
+	userID := ""
+	if user != nil {
+		userID = user(c)
+	}
+	conn, err := hub.Attach(userID)
+	if err != nil {
+		status := nethttp.StatusServiceUnavailable
+		if !errors.Is(err, ErrTooManyConnections) && !errors.Is(err, ErrHubClosed) {
+			status = nethttp.StatusInternalServerError
+		}
+		c.AbortWithStatusJSON(status, gin.H{"error": err.Error()})
+		return nil, err
+	}
+	return conn, nil
+}
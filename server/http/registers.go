@@ -1,8 +1,21 @@
 package http
 
-import "go.uber.org/fx"
+import (
+	"errors"
+	"fmt"
+	"reflect"
 
-// NewRestModule creates a new REST module with handler and route registration.
+	"go.uber.org/fx"
+)
+
+const routesGroup = `group:"liquor-http-routes"`
+
+// registeredRoute is the result of a route registration function of NewRestModule.
+type registeredRoute struct{}
+
+// NewRestModule creates a new REST module with handler and route registration. The
+// route function runs with RoutesModule, so the handlers and their dependencies are
+// only built when the routes are served or listed.
 //
 // Parameters:
 //   - name: Module name identifier
@@ -20,16 +33,86 @@ import "go.uber.org/fx"
 //	    RegisterUserRoutes,
 //	)
 func NewRestModule(name string, route any, providers ...any) fx.Option {
+	registration, err := lazyRoute(route)
+	if err != nil {
+		return fx.Error(fmt.Errorf("http: rest module %q: %w", name, err))
+	}
 	return fx.Module("liquor-app-rest-"+name, fx.Provide(
 		providers...,
 	),
-		fx.Invoke(route))
+		fx.Provide(fx.Annotate(registration, fx.ResultTags(routesGroup))))
+}
+
+// lazyRoute turns a route registration function into a provider of the routes group,
+// called with the dependencies of the function when the group is consumed.
+func lazyRoute(route any) (any, error) {
+	fnType := reflect.TypeOf(route)
+	if fnType == nil || fnType.Kind() != reflect.Func || fnType.IsVariadic() {
+		return nil, fmt.Errorf("the route must be a function, got %T", route)
+	}
+	in := make([]reflect.Type, fnType.NumIn())
+	for i := range in {
+		in[i] = fnType.In(i)
+	}
+	out := []reflect.Type{reflect.TypeOf(registeredRoute{}), reflect.TypeOf((*error)(nil)).Elem()}
+
+	fnValue := reflect.ValueOf(route)
+	wrapped := reflect.MakeFunc(reflect.FuncOf(in, out, false), func(args []reflect.Value) []reflect.Value {
+		var errs []error
+		for _, result := range fnValue.Call(args) {
+			if err, ok := result.Interface().(error); ok && err != nil {
+				errs = append(errs, err)
+			}
+		}
+		err := reflect.Zero(out[1])
+		if joined := errors.Join(errs...); joined != nil {
+			err = reflect.ValueOf(joined)
+		}
+		return []reflect.Value{reflect.ValueOf(registeredRoute{}), err}
+	})
+	return wrapped.Interface(), nil
 }
 
-var HttpModule = fx.Module("liquor-app-http-server", fx.Provide(
+type routesParams struct {
+	fx.In
+
+	Routes []registeredRoute `group:"liquor-http-routes"`
+}
+
+// RoutesModule registers the routes of the NewRestModule modules on the router. The app
+// adds it after the other modules when the routes are served or listed, so the
+// middlewares added to the router by the modules apply to every route.
+var RoutesModule = fx.Module("liquor-app-http-routes", fx.Invoke(
+	func(routesParams) {},
+))
+
+// HttpRouterModule provides the *gin.Engine with the default routes without serving it.
+var HttpRouterModule = fx.Module("liquor-app-http-router", fx.Provide(
 	instanceServer,
 ),
 	fx.Invoke(
-		startServer,
 		initialRoute,
 	))
+
+// HttpModule provides the *gin.Engine and serves it on `server.http.port`.
+var HttpModule = fx.Module("liquor-app-http-server",
+	HttpRouterModule,
+	fx.Invoke(
+		startServer,
+	))
+
+// OpsModule serves the `/-/health` probe (and `/-/config`, see config.endpoint.enabled) on
+// `server.ops.port` without the main HTTP server.
+var OpsModule = fx.Module("liquor-app-ops-server", fx.Provide(
+	NewOpsServer,
+),
+	fx.Invoke(
+		registerOpsConfigEndpoint,
+		startOpsServer,
+	))
+
+// RealtimeModule provides the *Hub used by SSEHandler and WebSocketHandler.
+// Provide a HubBroker (e.g. redis.NewPubSubBroker) to fan messages out across instances.
+var RealtimeModule = fx.Module("liquor-app-http-realtime", fx.Provide(
+	NewHub,
+))
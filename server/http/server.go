@@ -2,16 +2,21 @@ package http
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net"
+	nethttp "net/http"
+	"sync/atomic"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	"github.com/go-liquor/liquor-sdk/config"
+	"github.com/go-liquor/liquor-sdk/helpers/lqcontext"
 	"go.uber.org/fx"
 	"go.uber.org/zap"
 )
 
-func instanceServer(config *config.Config) *gin.Engine {
+func instanceServer(config *config.Config) (*gin.Engine, error) {
 	var svc *gin.Engine
 	if config.IsDebug() {
 		gin.SetMode(gin.DebugMode)
@@ -20,44 +25,104 @@ func instanceServer(config *config.Config) *gin.Engine {
 		gin.SetMode(gin.ReleaseMode)
 		svc = gin.New()
 	}
-	crs := cors.Default()
-	if !config.GetServerHttpCorsDefaultAllow() {
-		corsConfig := cors.Config{
-			AllowMethods:     config.GetServerHttpCorsAllowMethods(),
-			AllowHeaders:     config.GetServerHttpCorsAllowHeaders(),
-			AllowCredentials: config.GetServerHttpCorsAllowCredentials(),
-		}
+	crs, err := newDynamicCORS(config)
+	if err != nil {
+		return nil, err
+	}
+	svc.Use(crs, propagation)
+	return svc, nil
+}
+
+// propagation stores the request ID, bearer token and trace context of the request in its
+// context, forwarded by the liquor clients.
+func propagation(c *gin.Context) {
+	c.Request = c.Request.WithContext(lqcontext.FromHeaders(c.Request.Context(), c.Request.Header.Get))
+	c.Next()
+}
 
-		if len(config.GetServerHttpCorsAllowOrigins()) == 1 && config.GetServerHttpCorsAllowOrigins()[0] == "*" {
-			corsConfig.AllowAllOrigins = true
-		} else {
-			corsConfig.AllowOrigins = config.GetServerHttpCorsAllowOrigins()
+// newDynamicCORS returns the CORS middleware of `server.http.cors`, rebuilt when the
+// section is reloaded. An invalid section rejects the reload.
+func newDynamicCORS(cfg *config.Config) (gin.HandlerFunc, error) {
+	var current atomic.Pointer[gin.HandlerFunc]
+	handler, err := newCORS(cfg)
+	if err != nil {
+		return nil, err
+	}
+	current.Store(&handler)
+
+	cfg.Validate("server.http.cors", func(next *config.Config) error {
+		_, err := newCORS(next)
+		return err
+	})
+	cfg.OnChange("server.http.cors", func(cfg *config.Config) {
+		if handler, err := newCORS(cfg); err == nil {
+			current.Store(&handler)
 		}
+	})
+	return func(c *gin.Context) {
+		(*current.Load())(c)
+	}, nil
+}
 
-		crs = cors.New(corsConfig)
+// newCORS builds the CORS middleware of the configuration.
+func newCORS(config *config.Config) (handler gin.HandlerFunc, err error) {
+	if config.GetServerHttpCorsDefaultAllow() {
+		return cors.Default(), nil
+	}
+	corsConfig := cors.Config{
+		AllowMethods:     config.GetServerHttpCorsAllowMethods(),
+		AllowHeaders:     config.GetServerHttpCorsAllowHeaders(),
+		AllowCredentials: config.GetServerHttpCorsAllowCredentials(),
+	}
+
+	if len(config.GetServerHttpCorsAllowOrigins()) == 1 && config.GetServerHttpCorsAllowOrigins()[0] == "*" {
+		corsConfig.AllowAllOrigins = true
+	} else {
+		corsConfig.AllowOrigins = config.GetServerHttpCorsAllowOrigins()
 	}
-	svc.Use(crs)
-	return svc
+	if err := corsConfig.Validate(); err != nil {
+		return nil, fmt.Errorf("http: invalid server.http.cors: %w", err)
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("http: invalid server.http.cors: %v", r)
+		}
+	}()
+	return cors.New(corsConfig), nil
 }
 
 func startServer(config *config.Config, server *gin.Engine, lg *zap.Logger, lc fx.Lifecycle) {
+	srv := &nethttp.Server{Handler: server}
 	lc.Append(fx.Hook{
 		OnStart: func(context.Context) error {
 			lg.Info("starting HTTP server", zap.Int64("port", config.GetServerHttpPort()))
-			go server.Run(fmt.Sprintf(":%d", config.GetServerHttpPort()))
+			lis, err := net.Listen("tcp", fmt.Sprintf(":%d", config.GetServerHttpPort()))
+			if err != nil {
+				return fmt.Errorf("failed to start HTTP server on port %d: %w", config.GetServerHttpPort(), err)
+			}
+			go func() {
+				if err := srv.Serve(lis); err != nil && !errors.Is(err, nethttp.ErrServerClosed) {
+					lg.Error("HTTP server stopped", zap.Error(err))
+				}
+			}()
 			return nil
 		},
-		OnStop: func(context.Context) error {
+		OnStop: func(ctx context.Context) error {
 			lg.Info("stopping HTTP server")
-			return nil
+			return srv.Shutdown(ctx)
 		},
 	})
 }
 
-func initialRoute(server *gin.Engine) {
+func initialRoute(server *gin.Engine, cfg *config.Config) error {
 	server.GET("/-/health", func(c *gin.Context) {
 		c.JSON(200, gin.H{
 			"status": "ok",
 		})
 	})
+	handler, err := configEndpoint(cfg)
+	if handler != nil {
+		server.GET("/-/config", gin.WrapH(handler))
+	}
+	return err
 }
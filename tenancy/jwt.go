@@ -0,0 +1,276 @@
+package tenancy
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/go-liquor/liquor-sdk/config"
+	"github.com/spf13/cast"
+)
+
+// jwtAlgorithms are the algorithms of the jwt strategy.
+var jwtAlgorithms = []string{"HS256", "HS384", "HS512", "RS256", "RS384", "RS512", "ES256", "ES384", "ES512"}
+
+// jwtStrategy reads the tenant from a claim of a JWT.
+type jwtStrategy struct {
+	header     string
+	claim      []string
+	secret     []byte
+	public     crypto.PublicKey
+	trusted    bool
+	algorithms map[string]bool
+	issuer     string
+	audience   string
+}
+
+// NewJWTStrategy creates the strategy reading the tenant from a claim of the bearer token.
+// The token is verified with `tenancy.jwt.secret` (HS256, HS384, HS512) or
+// `tenancy.jwt.publicKey` (a PEM key, RS* or ES*), or trusted with `tenancy.jwt.trusted`
+// when a gateway verifies it. A token signed with an algorithm that isn't allowed, from
+// another issuer or audience, invalid or expired fails with ErrInvalidCredentials.
+//
+// Config keys:
+//   - tenancy.jwt.claim: The claim of the tenant, a dotted path (default "tenant")
+//   - tenancy.jwt.header: The header of the token (default Authorization)
+//   - tenancy.jwt.secret: The secret of the HMAC tokens
+//   - tenancy.jwt.publicKey: The PEM public key of the RSA and ECDSA tokens
+//   - tenancy.jwt.algorithms: The accepted algorithms (default the algorithms of the keys)
+//   - tenancy.jwt.issuer: The required `iss` claim (optional)
+//   - tenancy.jwt.audience: The audience required in the `aud` claim (optional)
+//   - tenancy.jwt.trusted: Reads the tokens without verifying them (default false)
+//
+// Parameters:
+//   - cfg: The configuration
+//
+// Returns:
+//   - Strategy: The strategy "jwt"
+//   - error: nil if successful, error without key, with an invalid key or an algorithm the
+//     keys don't verify
+func NewJWTStrategy(cfg *config.Config) (Strategy, error) {
+	s := &jwtStrategy{
+		header:     cfg.GetString("tenancy.jwt.header"),
+		claim:      strings.Split(cfg.GetString("tenancy.jwt.claim"), "."),
+		secret:     []byte(cfg.GetString("tenancy.jwt.secret")),
+		trusted:    cfg.GetBool("tenancy.jwt.trusted"),
+		algorithms: map[string]bool{},
+		issuer:     cfg.GetString("tenancy.jwt.issuer"),
+		audience:   cfg.GetString("tenancy.jwt.audience"),
+	}
+	if s.header == "" {
+		s.header = "Authorization"
+	}
+	if cfg.GetString("tenancy.jwt.claim") == "" {
+		s.claim = []string{"tenant"}
+	}
+	if key := cfg.GetString("tenancy.jwt.publicKey"); key != "" {
+		block, _ := pem.Decode([]byte(key))
+		if block == nil {
+			return nil, fmt.Errorf("tenancy: invalid jwt public key")
+		}
+		public, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("tenancy: invalid jwt public key: %w", err)
+		}
+		s.public = public
+	}
+	if len(s.secret) == 0 && s.public == nil && !s.trusted {
+		return nil, fmt.Errorf("tenancy: the jwt strategy requires tenancy.jwt.secret, tenancy.jwt.publicKey or tenancy.jwt.trusted")
+	}
+
+	// the algorithms the keys can verify
+	supported := map[string]bool{}
+	for _, alg := range jwtAlgorithms {
+		switch key := s.public.(type) {
+		case *rsa.PublicKey:
+			supported[alg] = strings.HasPrefix(alg, "RS")
+		case *ecdsa.PublicKey:
+			supported[alg] = (key.Curve.Params().BitSize+7)/8 == ecdsaKeySize[alg]
+		}
+		if (strings.HasPrefix(alg, "HS") && len(s.secret) > 0) || s.trusted {
+			supported[alg] = true
+		}
+	}
+	algorithms := cfg.GetStringSlice("tenancy.jwt.algorithms")
+	if len(algorithms) == 0 {
+		for alg, ok := range supported {
+			if ok {
+				algorithms = append(algorithms, alg)
+			}
+		}
+	}
+	for _, alg := range algorithms {
+		alg = strings.ToUpper(alg)
+		if !supported[alg] {
+			return nil, fmt.Errorf("tenancy: jwt algorithm %q isn't supported by the keys", alg)
+		}
+		s.algorithms[alg] = true
+	}
+	return s, nil
+}
+
+func (s *jwtStrategy) Name() string {
+	return "jwt"
+}
+
+func (s *jwtStrategy) Resolve(req Request) (string, error) {
+	token := strings.TrimSpace(req.Header(s.header))
+	if token == "" {
+		return "", nil
+	}
+	if scheme, rest, ok := strings.Cut(token, " "); ok && strings.EqualFold(scheme, "bearer") {
+		token = strings.TrimSpace(rest)
+	}
+	claims, err := s.parse(token)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidCredentials, err)
+	}
+	var value interface{} = claims
+	for _, name := range s.claim {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return "", nil
+		}
+		value = m[name]
+	}
+	if value == nil {
+		return "", nil
+	}
+	return cast.ToStringE(value)
+}
+
+// parse verifies a token and returns its claims.
+func (s *jwtStrategy) parse(token string) (map[string]interface{}, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("malformed token")
+	}
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, err
+	}
+	if !s.algorithms[header.Alg] {
+		return nil, fmt.Errorf("algorithm %q not allowed", header.Alg)
+	}
+	if !s.trusted {
+		signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+		if err != nil {
+			return nil, fmt.Errorf("malformed signature")
+		}
+		if err := s.verify(header.Alg, parts[0]+"."+parts[1], signature); err != nil {
+			return nil, err
+		}
+	}
+	claims := map[string]interface{}{}
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, err
+	}
+	now := time.Now().Unix()
+	if exp, ok := claims["exp"]; ok && cast.ToInt64(exp) < now {
+		return nil, fmt.Errorf("token expired")
+	}
+	if nbf, ok := claims["nbf"]; ok && cast.ToInt64(nbf) > now {
+		return nil, fmt.Errorf("token not valid yet")
+	}
+	if s.issuer != "" && claims["iss"] != s.issuer {
+		return nil, fmt.Errorf("invalid issuer")
+	}
+	if s.audience != "" && !hasAudience(claims["aud"], s.audience) {
+		return nil, fmt.Errorf("invalid audience")
+	}
+	return claims, nil
+}
+
+// hasAudience reports whether the `aud` claim, a string or an array, contains an audience.
+func hasAudience(aud interface{}, audience string) bool {
+	if aud, ok := aud.(string); ok {
+		return aud == audience
+	}
+	list, _ := aud.([]interface{})
+	for _, a := range list {
+		if a == audience {
+			return true
+		}
+	}
+	return false
+}
+
+// verify verifies the signature of a token.
+func (s *jwtStrategy) verify(alg, signed string, signature []byte) error {
+	var hash crypto.Hash
+	switch alg[min(2, len(alg)):] {
+	case "256":
+		hash = crypto.SHA256
+	case "384":
+		hash = crypto.SHA384
+	case "512":
+		hash = crypto.SHA512
+	default:
+		return fmt.Errorf("unsupported algorithm %q", alg)
+	}
+
+	switch {
+	case strings.HasPrefix(alg, "HS") && len(s.secret) > 0:
+		mac := hmac.New(hash.New, s.secret)
+		mac.Write([]byte(signed))
+		if !hmac.Equal(mac.Sum(nil), signature) {
+			return fmt.Errorf("invalid signature")
+		}
+		return nil
+	case strings.HasPrefix(alg, "RS"):
+		key, ok := s.public.(*rsa.PublicKey)
+		if !ok {
+			break
+		}
+		h := hash.New()
+		h.Write([]byte(signed))
+		if rsa.VerifyPKCS1v15(key, hash, h.Sum(nil), signature) != nil {
+			return fmt.Errorf("invalid signature")
+		}
+		return nil
+	case strings.HasPrefix(alg, "ES"):
+		// ES256, ES384 and ES512 are bound to the curves P-256, P-384 and P-521
+		key, ok := s.public.(*ecdsa.PublicKey)
+		if !ok || (key.Curve.Params().BitSize+7)/8 != ecdsaKeySize[alg] {
+			break
+		}
+		if len(signature) != 2*ecdsaKeySize[alg] {
+			return fmt.Errorf("invalid signature")
+		}
+		h := hash.New()
+		h.Write([]byte(signed))
+		r := new(big.Int).SetBytes(signature[:len(signature)/2])
+		sig := new(big.Int).SetBytes(signature[len(signature)/2:])
+		if !ecdsa.Verify(key, h.Sum(nil), r, sig) {
+			return fmt.Errorf("invalid signature")
+		}
+		return nil
+	}
+	return fmt.Errorf("unsupported algorithm %q", alg)
+}
+
+// ecdsaKeySize is the size in bytes of the keys of the ECDSA algorithms.
+var ecdsaKeySize = map[string]int{"ES256": 32, "ES384": 48, "ES512": 66}
+
+// decodeSegment decodes a base64url JSON segment of a token.
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return fmt.Errorf("malformed token")
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("malformed token")
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package tenancy
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// HTTPMiddleware returns the gin middleware resolving the tenant of the requests and
+// carrying it in the context of the request (see FromContext). TenancyModule installs it
+// on the router; use it on route groups otherwise. A request is rejected with 400 without
+// tenant, 404 with an unknown tenant and 401 with an invalid token.
+//
+// Parameters:
+//   - t: The tenancy
+//   - skip: Path prefixes of the requests without tenant, e.g. "/public"
+//
+// Returns:
+//   - gin.HandlerFunc: The middleware
+func HTTPMiddleware(t *Tenancy, skip ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if hasPrefix(c.Request.URL.Path, skip) {
+			c.Next()
+			return
+		}
+		tenant, err := t.Resolve(Request{
+			Host:   c.Request.Host,
+			Path:   c.Request.URL.Path,
+			Header: c.Request.Header.Get,
+		})
+		if err != nil {
+			c.AbortWithStatusJSON(httpStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+		if tenant != "" {
+			c.Request = c.Request.WithContext(WithTenant(c.Request.Context(), tenant))
+		}
+		c.Next()
+	}
+}
+
+func httpStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrUnknownTenant):
+		return http.StatusNotFound
+	case errors.Is(err, ErrInvalidCredentials):
+		return http.StatusUnauthorized
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+// UnaryServerInterceptor returns the gRPC interceptor resolving the tenant of the unary
+// calls from their metadata and :authority. A call is rejected with InvalidArgument
+// without tenant, NotFound with an unknown tenant and Unauthenticated with an invalid token.
+//
+// Parameters:
+//   - t: The tenancy
+//   - skip: Prefixes of the methods without tenant, e.g. "/grpc.health.v1."
+//
+// Returns:
+//   - grpc.UnaryServerInterceptor: The interceptor
+func UnaryServerInterceptor(t *Tenancy, skip ...string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if hasPrefix(info.FullMethod, skip) {
+			return handler(ctx, req)
+		}
+		ctx, err := resolveCall(ctx, t, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor returns the gRPC interceptor resolving the tenant of the
+// streams (see UnaryServerInterceptor).
+//
+// Parameters:
+//   - t: The tenancy
+//   - skip: Prefixes of the methods without tenant, e.g. "/grpc.health.v1."
+//
+// Returns:
+//   - grpc.StreamServerInterceptor: The interceptor
+func StreamServerInterceptor(t *Tenancy, skip ...string) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if hasPrefix(info.FullMethod, skip) {
+			return handler(srv, ss)
+		}
+		ctx, err := resolveCall(ss.Context(), t, info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &tenantStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// tenantStream is a stream with the tenant in its context.
+type tenantStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *tenantStream) Context() context.Context {
+	return s.ctx
+}
+
+// resolveCall resolves the tenant of a gRPC call.
+func resolveCall(ctx context.Context, t *Tenancy, method string) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	header := func(name string) string {
+		if values := md.Get(name); len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	}
+	host := header(":authority")
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	tenant, err := t.Resolve(Request{Host: host, Path: method, Header: header})
+	if err != nil {
+		return nil, status.Error(grpcCode(err), err.Error())
+	}
+	if tenant != "" {
+		ctx = WithTenant(ctx, tenant)
+	}
+	return ctx, nil
+}
+
+func grpcCode(err error) codes.Code {
+	switch {
+	case errors.Is(err, ErrUnknownTenant):
+		return codes.NotFound
+	case errors.Is(err, ErrInvalidCredentials):
+		return codes.Unauthenticated
+	default:
+		return codes.InvalidArgument
+	}
+}
+
+func hasPrefix(path string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
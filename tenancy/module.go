@@ -0,0 +1,101 @@
+package tenancy
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-liquor/liquor-sdk/config"
+	"github.com/go-liquor/liquor-sdk/flags"
+	grpcserver "github.com/go-liquor/liquor-sdk/server/grpc"
+	"go.uber.org/fx"
+	"google.golang.org/grpc"
+)
+
+// TenancyModule provides the *Tenancy, resolves the tenant of the HTTP requests and gRPC
+// calls, and sets the "tenant" attribute of the flags. The middleware applies to the routes
+// registered after it: the routes of http.NewRestModule are registered after every module,
+// the modules adding routes to the router directly are listed after TenancyModule.
+//
+// Config keys:
+//   - tenancy.http.enabled: Installs the HTTP middleware on the router (default true)
+//   - tenancy.http.skip: Path prefixes of the requests without tenant
+//   - tenancy.grpc.enabled: Installs the gRPC interceptors (default true)
+//   - tenancy.grpc.skip: Prefixes of the methods without tenant (default ["/grpc.health.v1."])
+//
+// Example:
+//
+//	app.NewApp(
+//	    tenancy.TenancyModule,
+//	    app.NewModule("orders", ...),
+//	)
+var TenancyModule = fx.Module("liquor-tenancy",
+	fx.Provide(NewTenancy),
+	fx.Invoke(installHTTPMiddleware),
+	grpcserver.RegisterServerOptions(newUnaryOption, newStreamOption),
+	flags.RegisterAttributes(func(ctx context.Context) flags.Attributes {
+		if tenant, ok := FromContext(ctx); ok {
+			return flags.Attributes{"tenant": tenant}
+		}
+		return nil
+	}),
+)
+
+// RegisterStrategies register custom strategies (or their constructors), enabled by their
+// name in `tenancy.strategies`.
+//
+// Example:
+//
+//	app.NewApp(
+//	    tenancy.TenancyModule,
+//	    tenancy.RegisterStrategies(NewAPIKeyStrategy),
+//	)
+func RegisterStrategies(strategies ...any) fx.Option {
+	provides := make([]any, len(strategies))
+	for i, s := range strategies {
+		if strategy, ok := s.(Strategy); ok {
+			s = func() Strategy { return strategy }
+		}
+		provides[i] = fx.Annotate(s, fx.As(new(Strategy)), fx.ResultTags(`group:"liquor-tenancy-strategies"`))
+	}
+	return fx.Module("liquor-tenancy-strategies", fx.Provide(provides...))
+}
+
+type middlewareParams struct {
+	fx.In
+
+	Tenancy *Tenancy
+	Config  *config.Config
+	Server  *gin.Engine `optional:"true"`
+}
+
+func installHTTPMiddleware(p middlewareParams) {
+	if p.Server == nil || !enabled(p.Config, "tenancy.http.enabled") {
+		return
+	}
+	p.Server.Use(HTTPMiddleware(p.Tenancy, p.Config.GetStringSlice("tenancy.http.skip")...))
+}
+
+func newUnaryOption(t *Tenancy, cfg *config.Config) grpc.ServerOption {
+	if !enabled(cfg, "tenancy.grpc.enabled") {
+		return grpc.EmptyServerOption{}
+	}
+	return grpc.ChainUnaryInterceptor(UnaryServerInterceptor(t, grpcSkip(cfg)...))
+}
+
+func newStreamOption(t *Tenancy, cfg *config.Config) grpc.ServerOption {
+	if !enabled(cfg, "tenancy.grpc.enabled") {
+		return grpc.EmptyServerOption{}
+	}
+	return grpc.ChainStreamInterceptor(StreamServerInterceptor(t, grpcSkip(cfg)...))
+}
+
+func grpcSkip(cfg *config.Config) []string {
+	if cfg.IsSet("tenancy.grpc.skip") {
+		return cfg.GetStringSlice("tenancy.grpc.skip")
+	}
+	return []string{"/grpc.health.v1."}
+}
+
+func enabled(cfg *config.Config, key string) bool {
+	return !cfg.IsSet(key) || cfg.GetBool(key)
+}
@@ -0,0 +1,208 @@
+package tenancy
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-liquor/liquor-sdk/config"
+	"github.com/uptrace/bun"
+	"go.uber.org/zap"
+)
+
+// OpenFunc opens a connection with the configuration of a tenant, e.g. postgres.NewConnection.
+type OpenFunc func(cfg *config.Config) (*bun.DB, error)
+
+// defaultCloseDelay is the default time a replaced connection stays open, so the queries
+// running on it finish.
+const defaultCloseDelay = 30 * time.Second
+
+// tenantDB is the connection of a tenant, with the database section it was opened with.
+type tenantDB struct {
+	db       *bun.DB
+	settings interface{}
+}
+
+// openCall is the opening of the connection of a tenant, shared by its concurrent callers.
+type openCall struct {
+	done chan struct{}
+	db   *bun.DB
+	err  error
+}
+
+// Pool keeps a connection per tenant with a `tenants.<id>.database` section, opened on
+// first use with the configuration of the tenant (see Tenancy.Config). The other tenants
+// share the connection of the app.
+type Pool struct {
+	tenancy    *Tenancy
+	shared     *bun.DB
+	open       OpenFunc
+	closeDelay time.Duration
+
+	mu      sync.Mutex
+	dbs     map[string]*tenantDB
+	opening map[string]*openCall
+	retired map[*bun.DB]*time.Timer
+	closed  bool
+}
+
+// NewPool creates the connections of the tenants. When the database section of a tenant
+// changes on a reload, a new connection replaces its connection, which is closed after
+// `tenancy.pool.closeDelay` (default 30s) so the running queries finish.
+//
+// Parameters:
+//   - t: The tenancy
+//   - shared: The connection of the tenants without database section
+//   - open: Opens a connection with the configuration of a tenant
+//
+// Returns:
+//   - *Pool: The pool
+//
+// Example:
+//
+//	pool := tenancy.NewPool(t, db, func(cfg *config.Config) (*bun.DB, error) {
+//	    return postgres.NewConnection(cfg, logger)
+//	})
+func NewPool(t *Tenancy, shared *bun.DB, open OpenFunc) *Pool {
+	closeDelay := t.config.GetDuration("tenancy.pool.closeDelay")
+	if closeDelay <= 0 {
+		closeDelay = defaultCloseDelay
+	}
+	p := &Pool{
+		tenancy:    t,
+		shared:     shared,
+		open:       open,
+		closeDelay: closeDelay,
+		dbs:        map[string]*tenantDB{},
+		opening:    map[string]*openCall{},
+		retired:    map[*bun.DB]*time.Timer{},
+	}
+	t.config.OnChange("tenants", func(cfg *config.Config) {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		for tenant, tdb := range p.dbs {
+			settings := cfg.Get("tenants." + tenant + ".database")
+			if reflect.DeepEqual(tdb.settings, settings) {
+				continue
+			}
+			delete(p.dbs, tenant)
+			p.retire(tenant, tdb.db)
+			if settings != nil {
+				// the new connection is opened now rather than on the next query
+				go func(tenant string) {
+					if _, err := p.ForTenant(tenant); err != nil {
+						p.tenancy.logger.Warn("failed to open the connection of the tenant", zap.String("tenant", tenant), zap.Error(err))
+					}
+				}(tenant)
+			}
+		}
+	})
+	return p
+}
+
+// DB returns the connection of the tenant of a context, or the shared connection without
+// tenant.
+//
+// Parameters:
+//   - ctx: Context
+//
+// Returns:
+//   - *bun.DB: The connection
+//   - error: nil if successful, error if the connection of the tenant can't be opened
+func (p *Pool) DB(ctx context.Context) (*bun.DB, error) {
+	return p.ForTenant(ID(ctx))
+}
+
+// ForTenant returns the connection of a tenant.
+//
+// Parameters:
+//   - tenant: The tenant ID
+//
+// Returns:
+//   - *bun.DB: The connection
+//   - error: nil if successful, error if the connection of the tenant can't be opened
+func (p *Pool) ForTenant(tenant string) (*bun.DB, error) {
+	tenant = strings.ToLower(tenant)
+	if tenant == "" {
+		return p.shared, nil
+	}
+	settings := p.tenancy.config.Get("tenants." + tenant + ".database")
+	if settings == nil {
+		return p.shared, nil
+	}
+
+	p.mu.Lock()
+	if tdb, ok := p.dbs[tenant]; ok {
+		p.mu.Unlock()
+		return tdb.db, nil
+	}
+	// the connection is opened without the lock, once for the concurrent callers
+	if call, ok := p.opening[tenant]; ok {
+		p.mu.Unlock()
+		<-call.done
+		return call.db, call.err
+	}
+	call := &openCall{done: make(chan struct{})}
+	p.opening[tenant] = call
+	p.mu.Unlock()
+
+	call.db, call.err = p.open(p.tenancy.Config(tenant))
+
+	p.mu.Lock()
+	delete(p.opening, tenant)
+	if call.err == nil && p.closed {
+		call.db, call.err = nil, errors.Join(errors.New("tenancy: the pool is closed"), call.db.Close())
+	}
+	if call.err == nil {
+		if reflect.DeepEqual(settings, p.tenancy.config.Get("tenants."+tenant+".database")) {
+			p.dbs[tenant] = &tenantDB{db: call.db, settings: settings}
+		} else {
+			// the section changed while the connection was opened
+			p.retire(tenant, call.db)
+		}
+	}
+	p.mu.Unlock()
+	close(call.done)
+	return call.db, call.err
+}
+
+// Close closes the connections of the tenants, not the shared connection.
+//
+// Returns:
+//   - error: The errors of the connections
+func (p *Pool) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.closed = true
+	var errs []error
+	for tenant, tdb := range p.dbs {
+		delete(p.dbs, tenant)
+		errs = append(errs, tdb.db.Close())
+	}
+	for db, timer := range p.retired {
+		delete(p.retired, db)
+		if timer.Stop() {
+			errs = append(errs, db.Close())
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// retire closes a replaced connection after the close delay. The lock is held by the caller.
+func (p *Pool) retire(tenant string, db *bun.DB) {
+	p.retired[db] = time.AfterFunc(p.closeDelay, func() {
+		p.mu.Lock()
+		delete(p.retired, db)
+		p.mu.Unlock()
+		p.closeDB(tenant, db)
+	})
+}
+
+func (p *Pool) closeDB(tenant string, db *bun.DB) {
+	if err := db.Close(); err != nil {
+		p.tenancy.logger.Warn("failed to close the connection of the tenant", zap.String("tenant", tenant), zap.Error(err))
+	}
+}
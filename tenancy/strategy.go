@@ -0,0 +1,105 @@
+package tenancy
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+
+	"github.com/go-liquor/liquor-sdk/config"
+	"github.com/spf13/cast"
+)
+
+// headerStrategy reads the tenant from a header.
+type headerStrategy struct {
+	header string
+}
+
+// NewHeaderStrategy creates the strategy reading the tenant from a header (the metadata
+// of the gRPC calls).
+//
+// Parameters:
+//   - header: The header, X-Tenant-ID if empty
+//
+// Returns:
+//   - Strategy: The strategy "header"
+func NewHeaderStrategy(header string) Strategy {
+	if header == "" {
+		header = "X-Tenant-ID"
+	}
+	return &headerStrategy{header: header}
+}
+
+func (s *headerStrategy) Name() string {
+	return "header"
+}
+
+func (s *headerStrategy) Resolve(req Request) (string, error) {
+	return strings.TrimSpace(req.Header(s.header)), nil
+}
+
+// hostStrategy reads the tenant from the host of the request.
+type hostStrategy struct {
+	pattern *regexp.Regexp
+	hosts   map[string]string
+}
+
+// NewHostStrategy creates the strategy reading the tenant from the host of the request:
+// a subdomain matching `tenancy.host.pattern` (e.g. "{tenant}.example.com"), or a custom
+// domain of `tenants.<id>.hosts`.
+//
+// Parameters:
+//   - cfg: The configuration
+//
+// Returns:
+//   - Strategy: The strategy "host"
+//   - error: nil if successful, error if the pattern is invalid or a host has two tenants
+func NewHostStrategy(cfg *config.Config) (Strategy, error) {
+	s := &hostStrategy{hosts: map[string]string{}}
+	if pattern := cfg.GetString("tenancy.host.pattern"); pattern != "" {
+		if strings.Count(pattern, "{tenant}") != 1 {
+			return nil, fmt.Errorf("tenancy: host pattern %q must contain {tenant} once", pattern)
+		}
+		parts := strings.SplitN(strings.ToLower(pattern), "{tenant}", 2)
+		s.pattern = regexp.MustCompile("^" + regexp.QuoteMeta(parts[0]) + "([a-z0-9-]+)" + regexp.QuoteMeta(parts[1]) + "$")
+	}
+	for id := range cfg.GetStringMap("tenants") {
+		value := cfg.Get("tenants." + id + ".hosts")
+		if value == nil {
+			continue
+		}
+		hosts, err := cast.ToStringSliceE(value)
+		if err != nil {
+			return nil, fmt.Errorf("tenancy: invalid hosts of tenant %q: %w", id, err)
+		}
+		id = strings.ToLower(id)
+		for _, host := range hosts {
+			host = strings.ToLower(host)
+			if other, ok := s.hosts[host]; ok && other != id {
+				return nil, fmt.Errorf("tenancy: host %q belongs to tenants %q and %q", host, other, id)
+			}
+			s.hosts[host] = id
+		}
+	}
+	return s, nil
+}
+
+func (s *hostStrategy) Name() string {
+	return "host"
+}
+
+func (s *hostStrategy) Resolve(req Request) (string, error) {
+	host := strings.ToLower(req.Host)
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	if tenant, ok := s.hosts[host]; ok {
+		return tenant, nil
+	}
+	if s.pattern != nil {
+		if m := s.pattern.FindStringSubmatch(host); m != nil {
+			return m[1], nil
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,342 @@
+package tenancy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/go-liquor/liquor-sdk/config"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+var (
+	// ErrTenantRequired is returned when a request has no tenant and `tenancy.required` is set.
+	ErrTenantRequired = errors.New("tenancy: tenant required")
+	// ErrUnknownTenant is returned for a tenant missing from the `tenants` section.
+	ErrUnknownTenant = errors.New("tenancy: unknown tenant")
+	// ErrInvalidCredentials is returned for a token failing the verification of the jwt strategy.
+	ErrInvalidCredentials = errors.New("tenancy: invalid credentials")
+)
+
+type tenantKey struct{}
+
+// WithTenant returns a context carrying a tenant, e.g. in a job processing the data of a
+// tenant.
+//
+// Parameters:
+//   - ctx: The parent context
+//   - tenant: The tenant ID
+//
+// Returns:
+//   - context.Context: The context with the tenant
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, strings.ToLower(tenant))
+}
+
+// FromContext returns the tenant of a context, set by the middlewares or WithTenant.
+//
+// Parameters:
+//   - ctx: Context
+//
+// Returns:
+//   - string: The tenant ID
+//   - bool: true if the context has a tenant
+func FromContext(ctx context.Context) (string, bool) {
+	tenant, ok := ctx.Value(tenantKey{}).(string)
+	return tenant, ok && tenant != ""
+}
+
+// ID returns the tenant of a context, or "" without tenant.
+//
+// Parameters:
+//   - ctx: Context
+//
+// Returns:
+//   - string: The tenant ID
+func ID(ctx context.Context) string {
+	tenant, _ := FromContext(ctx)
+	return tenant
+}
+
+// Request is a request to resolve the tenant of: an HTTP request or a gRPC call.
+type Request struct {
+	// Host is the host of the request (the :authority of the gRPC calls), without port.
+	Host string
+	// Path is the path of the HTTP request or the full method of the gRPC call.
+	Path string
+	// Header returns a header (the metadata of the gRPC calls).
+	Header func(name string) string
+}
+
+// Strategy resolves the tenant of a request (see RegisterStrategies).
+type Strategy interface {
+	// Name is the name of the strategy in `tenancy.strategies`.
+	Name() string
+	// Resolve returns the tenant of the request, or "" when the request doesn't carry one.
+	// An error rejects the request (see ErrInvalidCredentials).
+	Resolve(req Request) (string, error)
+}
+
+// state is the configuration of the tenancy, rebuilt on the reloads.
+type state struct {
+	strategies   []Strategy
+	authority    Strategy // the jwt strategy, if enabled: the tenant of the signed claim wins
+	required     bool
+	allowUnknown bool
+	tenants      map[string]bool
+}
+
+// Tenancy resolves the tenants of the requests and serves the configuration of each tenant.
+type Tenancy struct {
+	config *config.Config
+	logger *zap.Logger
+	custom map[string]Strategy
+
+	state atomic.Pointer[state]
+
+	mu       sync.Mutex
+	overlays map[string]*config.Config
+}
+
+type tenancyParams struct {
+	fx.In
+
+	Config     *config.Config
+	Logger     *zap.Logger
+	Strategies []Strategy `group:"liquor-tenancy-strategies"`
+}
+
+// NewTenancy creates the tenancy of the app.
+//
+// Config keys:
+//   - tenancy.strategies: The strategies resolving the tenant, in order (required); with jwt,
+//     the tenant is the claim of the token, and the tenants of the other strategies must match it
+//   - tenancy.required: Rejects the requests without tenant (default true)
+//   - tenancy.allowUnknown: Accepts the tenants missing from the `tenants` section (default false)
+//   - tenancy.header: The header of the header strategy (default X-Tenant-ID)
+//   - tenancy.host.pattern: The hosts of the host strategy, e.g. "{tenant}.example.com"
+//   - tenancy.jwt.*: The jwt strategy (see NewJWTStrategy)
+//   - tenants.<id>.*: The tenants, with the keys overriding the configuration (see Config)
+//   - tenants.<id>.hosts: The custom domains of a tenant, for the host strategy
+//
+// Parameters:
+//   - p: The configuration, logger and registered strategies
+//
+// Returns:
+//   - *Tenancy: The tenancy
+//   - error: nil if successful, error if the configuration is invalid
+func NewTenancy(p tenancyParams) (*Tenancy, error) {
+	t := &Tenancy{config: p.Config, logger: p.Logger, custom: map[string]Strategy{}, overlays: map[string]*config.Config{}}
+	for _, s := range p.Strategies {
+		t.custom[strings.ToLower(s.Name())] = s
+	}
+	st, err := t.build(p.Config)
+	if err != nil {
+		return nil, err
+	}
+	t.state.Store(st)
+	// the overlays are read on each request
+	p.Config.Uses("tenants")
+
+	validate := func(next *config.Config) error {
+		_, err := t.build(next)
+		return err
+	}
+	p.Config.Validate("tenancy", validate)
+	p.Config.Validate("tenants", validate)
+	p.Config.OnChange("", func(cfg *config.Config) {
+		if st, err := t.build(cfg); err == nil {
+			t.state.Store(st)
+		}
+		t.mu.Lock()
+		t.overlays = map[string]*config.Config{}
+		t.mu.Unlock()
+	})
+	return t, nil
+}
+
+// build reads the tenancy of a configuration.
+func (t *Tenancy) build(cfg *config.Config) (*state, error) {
+	st := &state{required: true, tenants: map[string]bool{}}
+	if cfg.IsSet("tenancy.required") {
+		st.required = cfg.GetBool("tenancy.required")
+	}
+	st.allowUnknown = cfg.GetBool("tenancy.allowUnknown")
+	for id := range cfg.GetStringMap("tenants") {
+		st.tenants[strings.ToLower(id)] = true
+	}
+
+	// no default: the header and host strategies let the callers choose their tenant
+	names := cfg.GetStringSlice("tenancy.strategies")
+	if len(names) == 0 {
+		return nil, errors.New("tenancy: tenancy.strategies is required, e.g. [jwt] or [host]")
+	}
+	for _, name := range names {
+		var s Strategy
+		var err error
+		switch name = strings.ToLower(name); name {
+		case "header":
+			s = NewHeaderStrategy(cfg.GetString("tenancy.header"))
+		case "host":
+			s, err = NewHostStrategy(cfg)
+		case "jwt":
+			s, err = NewJWTStrategy(cfg)
+			st.authority = s
+		default:
+			custom, ok := t.custom[name]
+			if !ok {
+				err = fmt.Errorf("tenancy: unknown strategy %q", name)
+			}
+			s = custom
+		}
+		if err != nil {
+			return nil, err
+		}
+		st.strategies = append(st.strategies, s)
+	}
+	return st, nil
+}
+
+// Resolve returns the tenant of a request with the strategies, in order. With the jwt
+// strategy, the tenant is the claim of the token, and a request whose other strategies
+// resolve another tenant is rejected.
+//
+// Parameters:
+//   - req: The request
+//
+// Returns:
+//   - string: The tenant ID, "" without tenant when `tenancy.required` is false
+//   - error: ErrTenantRequired, ErrUnknownTenant, ErrInvalidCredentials or the error of a strategy
+func (t *Tenancy) Resolve(req Request) (string, error) {
+	st := t.state.Load()
+	var tenant string
+	if st.authority != nil {
+		claim, err := st.authority.Resolve(req)
+		if err != nil {
+			return "", err
+		}
+		tenant = strings.ToLower(claim)
+	}
+	for _, s := range st.strategies {
+		if s == st.authority {
+			continue
+		}
+		resolved, err := s.Resolve(req)
+		if err != nil {
+			return "", err
+		}
+		if resolved == "" {
+			continue
+		}
+		resolved = strings.ToLower(resolved)
+		if st.authority == nil {
+			tenant = resolved
+			break
+		}
+		if resolved != tenant {
+			return "", fmt.Errorf("%w: the %s tenant %q doesn't match the token", ErrInvalidCredentials, s.Name(), resolved)
+		}
+	}
+	if tenant == "" {
+		if st.required {
+			return "", ErrTenantRequired
+		}
+		return "", nil
+	}
+	if !st.allowUnknown && !st.tenants[tenant] {
+		return "", fmt.Errorf("%w %q", ErrUnknownTenant, tenant)
+	}
+	return tenant, nil
+}
+
+// Tenants returns the tenants of the `tenants` section.
+//
+// Returns:
+//   - []string: The tenant IDs, in order
+func (t *Tenancy) Tenants() []string {
+	st := t.state.Load()
+	tenants := make([]string, 0, len(st.tenants))
+	for id := range st.tenants {
+		tenants = append(tenants, id)
+	}
+	sort.Strings(tenants)
+	return tenants
+}
+
+// Exists reports whether a tenant is in the `tenants` section.
+//
+// Parameters:
+//   - tenant: The tenant ID
+//
+// Returns:
+//   - bool: true if the tenant exists
+func (t *Tenancy) Exists(tenant string) bool {
+	return t.state.Load().tenants[strings.ToLower(tenant)]
+}
+
+// Config returns the configuration of a tenant: the configuration of the app with the
+// keys of `tenants.<id>` merged over it, e.g. `tenants.acme.limits.rps` overrides
+// `limits.rps` for acme. Without tenant, or for a tenant missing from the `tenants`
+// section (see `tenancy.allowUnknown`), the configuration of the app is returned. The
+// configuration is a snapshot, rebuilt after the reloads.
+//
+// Parameters:
+//   - tenant: The tenant ID
+//
+// Returns:
+//   - *config.Config: The configuration of the tenant
+func (t *Tenancy) Config(tenant string) *config.Config {
+	tenant = strings.ToLower(tenant)
+	// only the configured tenants are cached: the unknown ones come from the requests
+	if tenant == "" || !t.Exists(tenant) {
+		return t.config
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if cfg, ok := t.overlays[tenant]; ok {
+		return cfg
+	}
+	settings := t.config.AllSettings()
+	for k, v := range t.config.GetStringMap("tenants." + tenant) {
+		if k == "hosts" {
+			continue
+		}
+		merge(settings, map[string]interface{}{k: v})
+	}
+	cfg := config.NewFromMap(settings)
+	t.overlays[tenant] = cfg
+	return cfg
+}
+
+// ConfigFrom returns the configuration of the tenant of a context (see Config).
+//
+// Parameters:
+//   - ctx: Context
+//
+// Returns:
+//   - *config.Config: The configuration of the tenant
+func (t *Tenancy) ConfigFrom(ctx context.Context) *config.Config {
+	return t.Config(ID(ctx))
+}
+
+// merge merges a copy of src over dst, recursively.
+func merge(dst, src map[string]interface{}) {
+	for k, v := range src {
+		nested, isMap := v.(map[string]interface{})
+		if !isMap {
+			dst[k] = v
+			continue
+		}
+		current, wasMap := dst[k].(map[string]interface{})
+		if !wasMap {
+			current = map[string]interface{}{}
+			dst[k] = current
+		}
+		merge(current, nested)
+	}
+}